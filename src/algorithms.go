@@ -0,0 +1,330 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"src/data"
+	"src/models"
+	ann "src/models/ann"
+	recommendation "src/models/colaborative_filter"
+	decisiontree "src/models/decision_tree"
+	dnn "src/models/dnn"
+	underFactors "src/models/factores_latentes"
+	randomforest "src/models/random_forest"
+	svmachine "src/models/svm"
+	"strconv"
+	"strings"
+)
+
+// Algoritmo disponible en la línea de comandos con sus dos variantes
+type algorithm struct {
+	sequential func() models.Regressor
+	concurrent func() models.Regressor
+}
+
+var algorithms = map[string]algorithm{
+	"cf": {
+		sequential: func() models.Regressor { return recommendation.NewCollaborativeFilter() },
+		concurrent: func() models.Regressor { return recommendation.NewCollaborativeFilterConcurrent() },
+	},
+	"svm": {
+		sequential: func() models.Regressor { return svmachine.NewSVM() },
+		concurrent: func() models.Regressor { return svmachine.NewSVMConcurrent() },
+	},
+	"ksvm": {
+		sequential: func() models.Regressor { return svmachine.NewKernelSVM() },
+		concurrent: func() models.Regressor { return svmachine.NewKernelSVMConcurrent() },
+	},
+	"svr": {
+		sequential: func() models.Regressor { return svmachine.NewSVR() },
+		concurrent: func() models.Regressor { return svmachine.NewSVRConcurrent() },
+	},
+	"ksvr": {
+		sequential: func() models.Regressor { return svmachine.NewKernelSVR() },
+		concurrent: func() models.Regressor { return svmachine.NewKernelSVRConcurrent() },
+	},
+	"tree": {
+		sequential: func() models.Regressor { return decisiontree.NewDecisionTree() },
+		concurrent: func() models.Regressor { return decisiontree.NewDecisionTreeConcurrent() },
+	},
+	"ann": {
+		sequential: func() models.Regressor { return ann.NewANN() },
+		concurrent: func() models.Regressor { return ann.NewANNConcurrent() },
+	},
+	"forest": {
+		sequential: func() models.Regressor { return randomforest.NewRandomForest() },
+		concurrent: func() models.Regressor { return randomforest.NewRandomForestConcurrent() },
+	},
+	"dnn": {
+		sequential: func() models.Regressor { return dnn.NewDNN() },
+		concurrent: func() models.Regressor { return dnn.NewDNNConcurrent() },
+	},
+	"factors": {
+		sequential: func() models.Regressor { return underFactors.NewRecommender() },
+		concurrent: func() models.Regressor { return underFactors.NewRecommenderConcurrent() },
+	},
+}
+
+// Orden en que benchmark ejecuta todos los algoritmos
+var algorithmOrder = []string{"cf", "svm", "ksvm", "svr", "ksvr", "tree", "ann", "forest", "dnn", "factors"}
+
+// Devuelve el algoritmo con el nombre dado
+func lookupAlgorithm(name string) (algorithm, error) {
+	algo, ok := algorithms[name]
+	if !ok {
+		names := make([]string, 0, len(algorithms))
+		for n := range algorithms {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return algo, fmt.Errorf("algoritmo desconocido %q (disponibles: %s)", name, strings.Join(names, ", "))
+	}
+	return algo, nil
+}
+
+// Crea el modelo de la variante pedida: "seq" o "con"
+func (a algorithm) build(mode string) (models.Regressor, error) {
+	switch mode {
+	case "seq":
+		return a.sequential(), nil
+	case "con":
+		return a.concurrent(), nil
+	}
+	return nil, fmt.Errorf("modo desconocido %q (use seq o con)", mode)
+}
+
+// Indica si el modelo se entrena con una matriz de calificaciones usuario × ítem
+func usesRatings(model models.Regressor) bool {
+	switch model.(type) {
+	case *recommendation.CollaborativeFilter, *recommendation.CollaborativeFilterC,
+		*underFactors.Recommender, *underFactors.RecommenderC:
+		return true
+	}
+	return false
+}
+
+// Hiperparámetros configurables desde la línea de comandos; el valor cero
+// conserva el valor por defecto del modelo
+type hyperparams struct {
+	epochs       int
+	learningRate float64
+	lambda       float64
+	hidden       string
+	depth        int
+	trees        int
+	similarity   string
+	kernel       string
+	c            float64
+	gamma        float64
+	degree       int
+	coef0        float64
+	cacheMB      float64
+	epsilon      float64
+	multiclass   string
+	aggregate    string
+	calibrate    string
+	calibFolds   int
+	seed         int64
+}
+
+// Registra los flags de hiperparámetros en fs
+func (h *hyperparams) register(fs *flag.FlagSet) {
+	fs.IntVar(&h.epochs, "epochs", 0, "épocas de entrenamiento (svm, svr, ann, dnn)")
+	fs.Float64Var(&h.learningRate, "lr", 0, "tasa de aprendizaje (ann, dnn)")
+	fs.Float64Var(&h.lambda, "lambda", 0, "regularización λ; en svm y svr fija también el paso 1/(λt)")
+	fs.StringVar(&h.hidden, "hidden", "", "neuronas ocultas separadas por comas, p. ej. 5,5 (ann usa la primera)")
+	fs.IntVar(&h.depth, "depth", 0, "profundidad máxima (tree)")
+	fs.IntVar(&h.trees, "trees", 0, "número de árboles (forest)")
+	fs.StringVar(&h.similarity, "similarity", "", "similitud pearson o cosine (factors)")
+	fs.StringVar(&h.kernel, "kernel", "", "kernel linear, rbf, poly o sigmoid (ksvm, ksvr)")
+	fs.Float64Var(&h.c, "C", 0, "penalización de las violaciones del margen o del tubo (ksvm, ksvr)")
+	fs.Float64Var(&h.gamma, "gamma", 0, "γ de los kernels rbf, poly y sigmoid; 0 = 1/características (ksvm, ksvr)")
+	fs.IntVar(&h.degree, "degree", 0, "grado del kernel poly (ksvm, ksvr)")
+	fs.Float64Var(&h.coef0, "coef0", 0, "término independiente de los kernels poly y sigmoid (ksvm, ksvr)")
+	fs.Float64Var(&h.cacheMB, "cache-mb", 0, "memoria de la caché de filas de kernel en MB; 0 = 100 (ksvm, ksvr)")
+	fs.Float64Var(&h.epsilon, "epsilon", 0, "ancho del tubo sin pérdida de la regresión; 0 = 0.1 (svr, ksvr)")
+	fs.StringVar(&h.multiclass, "multiclass", "", "entrena un svm binario por clase (ovr) o por par de clases (ovo) (svm, ksvm)")
+	fs.StringVar(&h.aggregate, "aggregate", "auto", "combina los svm de -multiclass por decision o vote; auto = decision en ovr y vote en ovo")
+	fs.StringVar(&h.calibrate, "calibrate", "", "calibra las probabilidades con platt o isotonic sobre una validación cruzada interna (svm)")
+	fs.IntVar(&h.calibFolds, "calibration-folds", 0, "folds de la validación cruzada de -calibrate; 0 = 5 (svm)")
+	fs.Int64Var(&h.seed, "seed", data.DefaultSeed, "semilla de la división de los datos y de la inicialización de los modelos")
+}
+
+// Aplica los hiperparámetros distintos de cero al modelo
+func (h hyperparams) apply(model models.Regressor) error {
+	hidden, err := parseInts(h.hidden)
+	if err != nil {
+		return fmt.Errorf("-hidden: %w", err)
+	}
+
+	switch m := model.(type) {
+	case *svmachine.SVM:
+		return h.applySVM(m)
+	case *svmachine.SVMC:
+		return h.applySVM(&m.SVM)
+	case *svmachine.KernelSVM:
+		return h.applyKernelSVM(m)
+	case *svmachine.KernelSVMC:
+		return h.applyKernelSVM(&m.KernelSVM)
+	case *svmachine.SVR:
+		h.applySVR(m)
+	case *svmachine.SVRC:
+		h.applySVR(&m.SVR)
+	case *svmachine.KernelSVR:
+		return h.applyKernelSVR(m)
+	case *svmachine.KernelSVRC:
+		return h.applyKernelSVR(&m.KernelSVR)
+	case *ann.ANN:
+		h.applyANN(m, hidden)
+	case *ann.ANNC:
+		h.applyANN(&m.ANN, hidden)
+	case *dnn.DNN:
+		h.applyDNN(m, hidden)
+	case *dnn.DNNC:
+		h.applyDNN(&m.DNN, hidden)
+	case *decisiontree.DecisionTree:
+		setInt(&m.MaxDepth, h.depth)
+	case *decisiontree.DecisionTreeC:
+		setInt(&m.MaxDepth, h.depth)
+	case *randomforest.RandomForest:
+		setInt(&m.NumTrees, h.trees)
+		m.Seed = h.seed
+	case *randomforest.RandomForestConc:
+		setInt(&m.NumTrees, h.trees)
+		m.Seed = h.seed
+	case *underFactors.Recommender:
+		setString(&m.Similarity, h.similarity)
+	case *underFactors.RecommenderC:
+		setString(&m.Similarity, h.similarity)
+	}
+	return nil
+}
+
+// Envuelve los SVM binarios en un SVM multiclase si se pidió -multiclass; la
+// variante concurrente entrena cada problema binario en su propia goroutine
+func (h hyperparams) wrap(model models.Regressor) (models.Regressor, error) {
+	base, ok := model.(svmachine.Model)
+	if h.multiclass == "" || !ok {
+		return model, nil
+	}
+	strategy, err := svmachine.ParseStrategy(h.multiclass)
+	if err != nil {
+		return nil, err
+	}
+	aggregation, err := svmachine.ParseAggregation(h.aggregate)
+	if err != nil {
+		return nil, err
+	}
+	switch model.(type) {
+	case *svmachine.SVMC, *svmachine.KernelSVMC:
+		m := svmachine.NewMulticlassConcurrent(base, strategy)
+		m.Aggregation = aggregation
+		return m, nil
+	}
+	m := svmachine.NewMulticlass(base, strategy)
+	m.Aggregation = aggregation
+	return m, nil
+}
+
+func (h hyperparams) applySVM(m *svmachine.SVM) error {
+	if h.calibrate != "" {
+		calibration, err := svmachine.ParseCalibration(h.calibrate)
+		if err != nil {
+			return err
+		}
+		m.Calibration = calibration
+	}
+	setInt(&m.CalibrationFolds, h.calibFolds)
+	setInt(&m.Epochs, h.epochs)
+	setFloat(&m.Lambda, h.lambda)
+	m.Seed = h.seed
+	return nil
+}
+
+func (h hyperparams) applyKernelSVM(m *svmachine.KernelSVM) error {
+	setFloat(&m.C, h.c)
+	setFloat(&m.CacheMB, h.cacheMB)
+	return h.applyKernel(&m.Kernel)
+}
+
+func (h hyperparams) applySVR(m *svmachine.SVR) {
+	setInt(&m.Epochs, h.epochs)
+	setFloat(&m.Lambda, h.lambda)
+	setFloat(&m.Epsilon, h.epsilon)
+	m.Seed = h.seed
+}
+
+func (h hyperparams) applyKernelSVR(m *svmachine.KernelSVR) error {
+	setFloat(&m.C, h.c)
+	setFloat(&m.Epsilon, h.epsilon)
+	setFloat(&m.CacheMB, h.cacheMB)
+	return h.applyKernel(&m.Kernel)
+}
+
+func (h hyperparams) applyKernel(k *svmachine.Kernel) error {
+	if h.kernel != "" {
+		kernel, err := svmachine.ParseKernel(h.kernel)
+		if err != nil {
+			return err
+		}
+		k.Type = kernel
+	}
+	setFloat(&k.Gamma, h.gamma)
+	setInt(&k.Degree, h.degree)
+	setFloat(&k.Coef0, h.coef0)
+	return nil
+}
+
+func (h hyperparams) applyANN(m *ann.ANN, hidden []int) {
+	setInt(&m.Epochs, h.epochs)
+	setFloat(&m.LearningRate, h.learningRate)
+	m.Seed = h.seed
+	if len(hidden) > 0 {
+		m.HiddenSize = hidden[0]
+	}
+}
+
+func (h hyperparams) applyDNN(m *dnn.DNN, hidden []int) {
+	setInt(&m.Epochs, h.epochs)
+	setFloat(&m.LearningRate, h.learningRate)
+	m.Seed = h.seed
+	if len(hidden) > 0 {
+		m.HiddenLayers = hidden
+	}
+}
+
+func setInt(dst *int, v int) {
+	if v != 0 {
+		*dst = v
+	}
+}
+
+func setFloat(dst *float64, v float64) {
+	if v != 0 {
+		*dst = v
+	}
+}
+
+func setString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+// Convierte una lista separada por comas en enteros positivos
+func parseInts(list string) ([]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	parts := strings.Split(list, ",")
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("valor inválido %q", part)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
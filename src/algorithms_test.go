@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"slices"
+	"sort"
+	"src/models"
+	svmachine "src/models/svm"
+	"testing"
+)
+
+func TestAlgorithmOrderCoversEveryAlgorithm(t *testing.T) {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	order := slices.Clone(algorithmOrder)
+	sort.Strings(order)
+	if !slices.Equal(names, order) {
+		t.Errorf("algorithmOrder = %v, los algoritmos son %v", algorithmOrder, names)
+	}
+
+	for _, name := range algorithmOrder {
+		for _, mode := range []string{"seq", "con"} {
+			model, err := algorithms[name].build(mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := model.(models.Persistent); !ok {
+				t.Errorf("%s -mode %s: %T no se puede guardar", name, mode, model)
+			}
+		}
+	}
+	if _, err := algorithms["svm"].build("gpu"); err == nil {
+		t.Error("un modo desconocido debe dar error")
+	}
+	if _, err := lookupAlgorithm("knn"); err == nil {
+		t.Error("un algoritmo desconocido debe dar error")
+	}
+}
+
+// Hiperparámetros leídos de args como en la línea de comandos
+func parseHyperparams(t *testing.T, args ...string) hyperparams {
+	t.Helper()
+	var h hyperparams
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	h.register(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestApplyReachesEmbeddedModel(t *testing.T) {
+	h := parseHyperparams(t, "-kernel", "poly", "-C", "4", "-degree", "2", "-cache-mb", "16")
+	m := svmachine.NewKernelSVMConcurrent()
+	if err := h.apply(m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Kernel.Type != svmachine.Polynomial || m.C != 4 || m.Kernel.Degree != 2 || m.CacheMB != 16 {
+		t.Errorf("kernel %v, C %v, grado %d, caché %v", m.Kernel.Type, m.C, m.Kernel.Degree, m.CacheMB)
+	}
+	// Los flags sin dar conservan los valores por defecto
+	if m.Tol != svmachine.NewKernelSVM().Tol {
+		t.Errorf("Tol = %v, se esperaba el valor por defecto", m.Tol)
+	}
+
+	if err := parseHyperparams(t, "-kernel", "cubic").apply(svmachine.NewKernelSVM()); err == nil {
+		t.Error("un kernel desconocido debe dar error")
+	}
+	if err := parseHyperparams(t, "-hidden", "5,x").apply(svmachine.NewSVM()); err == nil {
+		t.Error("-hidden inválido debe dar error")
+	}
+}
+
+func TestWrapMulticlass(t *testing.T) {
+	h := parseHyperparams(t, "-multiclass", "ovo")
+	wrapped, err := h.wrap(svmachine.NewSVMConcurrent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := wrapped.(*svmachine.MulticlassC); !ok || m.Strategy != svmachine.OneVsOne {
+		t.Errorf("wrap = %T, se esperaba un MulticlassC ovo", wrapped)
+	}
+	// Los modelos que no son SVM binarios no se envuelven
+	tree := algorithms["tree"].sequential()
+	if wrapped, err := h.wrap(tree); err != nil || wrapped != tree {
+		t.Errorf("wrap(tree) = %T, %v", wrapped, err)
+	}
+}
+
+func TestParseInts(t *testing.T) {
+	got, err := parseInts("5, 3,2")
+	if err != nil || !slices.Equal(got, []int{5, 3, 2}) {
+		t.Errorf("parseInts = %v, %v", got, err)
+	}
+	for _, list := range []string{"0", "5,", "-1"} {
+		if _, err := parseInts(list); err == nil {
+			t.Errorf("parseInts(%q) debe dar error", list)
+		}
+	}
+}
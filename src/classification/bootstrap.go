@@ -0,0 +1,263 @@
+package classification
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"src/data"
+	"src/models"
+)
+
+// IntervalMethod es la forma de obtener el intervalo a partir de los remuestreos
+type IntervalMethod int
+
+const (
+	PercentileInterval IntervalMethod = iota // Cuantiles de los remuestreos
+	BCaInterval                              // Percentil corregido por sesgo y aceleración
+)
+
+func (m IntervalMethod) String() string {
+	switch m {
+	case PercentileInterval:
+		return "percentile"
+	case BCaInterval:
+		return "bca"
+	}
+	return fmt.Sprintf("IntervalMethod(%d)", int(m))
+}
+
+// ParseIntervalMethod convierte "percentile" o "bca" en su IntervalMethod
+func ParseIntervalMethod(name string) (IntervalMethod, error) {
+	switch name {
+	case "percentile":
+		return PercentileInterval, nil
+	case "bca":
+		return BCaInterval, nil
+	}
+	return 0, fmt.Errorf("método de intervalo desconocido %q (use percentile o bca)", name)
+}
+
+// PairMetric calcula una métrica sobre pares (predicción, etiqueta)
+type PairMetric func(predictions, actuals []float64) float64
+
+// MetricSet calcula varias métricas por nombre sobre pares (predicción, etiqueta)
+type MetricSet func(predictions, actuals []float64) map[string]float64
+
+// Interval es el intervalo de confianza bootstrap de una métrica
+type Interval struct {
+	Estimate float64 `json:"estimate"` // Valor sobre todas las filas
+	Lower    float64 `json:"lower"`
+	Upper    float64 `json:"upper"`
+	StdErr   float64 `json:"std_err"` // Desviación estándar de los remuestreos
+}
+
+// Bootstrap estima intervalos de confianza remuestreando las filas evaluadas
+// con reemplazo. Cada remuestreo usa su propia semilla derivada de Seed, así
+// que el resultado no depende de Workers
+type Bootstrap struct {
+	Resamples  int            // Número de remuestreos; 0 usa 1000
+	Confidence float64        // Nivel de confianza; 0 usa 0.95
+	Method     IntervalMethod // Percentil (por defecto) o BCa
+	Workers    int            // Goroutines que calculan remuestreos; 0 usa GOMAXPROCS
+	Seed       int64          // Semilla de los remuestreos
+	Task       Task           // Tarea con que RunDataset evalúa el modelo; AutoTask la deduce
+}
+
+// Con más filas la aceleración de BCa se estima quitando grupos de filas en
+// lugar de una fila cada vez
+const jackknifeMax = 1000
+
+// Estadístico calculado sobre un subconjunto de filas
+type statistic func(indices []int) (map[string]float64, error)
+
+// Interval devuelve el intervalo de confianza de una métrica
+func (b *Bootstrap) Interval(metric PairMetric, predictions, actuals []float64) (Interval, error) {
+	intervals, err := b.Run(func(p, a []float64) map[string]float64 {
+		return map[string]float64{"metric": metric(p, a)}
+	}, predictions, actuals)
+	if err != nil {
+		return Interval{}, err
+	}
+	return intervals["metric"], nil
+}
+
+// Run devuelve el intervalo de confianza de cada métrica del conjunto
+func (b *Bootstrap) Run(metrics MetricSet, predictions, actuals []float64) (map[string]Interval, error) {
+	if len(predictions) != len(actuals) {
+		return nil, errors.New("la longitud de las predicciones y las etiquetas no coinciden")
+	}
+	return b.run(len(actuals), func(indices []int) (map[string]float64, error) {
+		return metrics(pick(predictions, indices), pick(actuals, indices)), nil
+	})
+}
+
+// RunDataset predice las filas del dataset con un modelo entrenado y devuelve
+// el intervalo de confianza de cada métrica del reporte de EvaluateDatasetAs
+// con b.Task
+func (b *Bootstrap) RunDataset(model models.Regressor, ds *data.Dataset) (map[string]Interval, error) {
+	out, err := datasetOutputs(model, ds, b.Task)
+	if err != nil {
+		return nil, err
+	}
+	return b.run(len(out.actuals), func(indices []int) (map[string]float64, error) {
+		report, err := out.report(indices)
+		return report.Metrics(), err
+	})
+}
+
+// Calcula el estadístico sobre todas las filas y sobre cada remuestreo
+func (b *Bootstrap) run(n int, stat statistic) (map[string]Interval, error) {
+	resamples, confidence := b.Resamples, b.Confidence
+	if resamples <= 0 {
+		resamples = 1000
+	}
+	if confidence == 0 {
+		confidence = 0.95
+	}
+	if n == 0 {
+		return nil, errors.New("no hay filas que remuestrear")
+	}
+	if !(confidence > 0 && confidence < 1) {
+		return nil, errors.New("el nivel de confianza debe estar entre 0 y 1")
+	}
+
+	all := make([]int, n)
+	for i := range all {
+		all[i] = i
+	}
+	estimates, err := stat(all)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := data.DeriveSeeds(data.NewRand(b.Seed), resamples)
+	samples, err := evalAll(resamples, b.Workers, func(r int) (map[string]float64, error) {
+		rng := data.NewRand(seeds[r])
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = rng.Intn(n)
+		}
+		return stat(indices)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var jackknife []map[string]float64
+	if b.Method == BCaInterval {
+		if jackknife, err = b.jackknife(n, stat); err != nil {
+			return nil, err
+		}
+	}
+
+	alpha := (1 - confidence) / 2
+	intervals := make(map[string]Interval, len(estimates))
+	for name, estimate := range estimates {
+		values := collect(samples, name)
+		if math.IsNaN(estimate) || len(values) == 0 {
+			continue
+		}
+		slices.Sort(values)
+		_, std := meanStd(values)
+		lo, hi := alpha, 1-alpha
+		if b.Method == BCaInterval {
+			lo, hi = bcaLevels(values, collect(jackknife, name), estimate, alpha)
+		}
+		intervals[name] = Interval{
+			Estimate: estimate,
+			Lower:    quantile(values, lo),
+			Upper:    quantile(values, hi),
+			StdErr:   std,
+		}
+	}
+	return intervals, nil
+}
+
+// Estadísticos jackknife: sin cada fila o, con más de jackknifeMax filas, sin
+// cada uno de jackknifeMax grupos de filas tomadas de forma intercalada
+func (b *Bootstrap) jackknife(n int, stat statistic) ([]map[string]float64, error) {
+	groups := min(n, jackknifeMax)
+	if groups < 2 {
+		return nil, errors.New("BCa necesita al menos dos filas")
+	}
+	return evalAll(groups, b.Workers, func(g int) (map[string]float64, error) {
+		indices := make([]int, 0, n)
+		for i := range n {
+			if i%groups != g {
+				indices = append(indices, i)
+			}
+		}
+		return stat(indices)
+	})
+}
+
+// Evalúa f(0..count-1) en paralelo repartiendo los índices entre workers goroutines
+func evalAll(count, workers int, f func(i int) (map[string]float64, error)) ([]map[string]float64, error) {
+	results := make([]map[string]float64, count)
+	errs := make([]error, count)
+	MapReduce(count, 1, workers, func(lo, hi int) struct{} {
+		for i := lo; i < hi; i++ {
+			results[i], errs[i] = f(i)
+		}
+		return struct{}{}
+	}, func(a, _ struct{}) struct{} { return a })
+	return results, errors.Join(errs...)
+}
+
+// Valores definidos de una métrica; los remuestreos en que falta (p. ej.
+// ROC-AUC con una sola clase) se omiten
+func collect(results []map[string]float64, name string) []float64 {
+	values := make([]float64, 0, len(results))
+	for _, r := range results {
+		if v, ok := r[name]; ok && !math.IsNaN(v) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Niveles de los cuantiles de BCa: corrección de sesgo z0 a partir de la
+// fracción de remuestreos por debajo de la estimación y aceleración a partir
+// de la asimetría de los valores jackknife
+func bcaLevels(sorted, jackknife []float64, estimate, alpha float64) (float64, float64) {
+	below := 0.0
+	for _, v := range sorted {
+		if v < estimate {
+			below++
+		} else if v == estimate {
+			below += 0.5
+		}
+	}
+	// Se recorta para que z0 sea finito cuando todos quedan de un lado
+	half := 0.5 / float64(len(sorted))
+	z0 := normalQuantile(math.Min(math.Max(below/float64(len(sorted)), half), 1-half))
+
+	mean, _ := meanStd(jackknife)
+	num, den := 0.0, 0.0
+	for _, v := range jackknife {
+		d := mean - v
+		num += d * d * d
+		den += d * d
+	}
+	a := 0.0
+	if den > 0 {
+		a = num / (6 * math.Pow(den, 1.5))
+	}
+
+	level := func(q float64) float64 {
+		z := z0 + normalQuantile(q)
+		return normalCDF(z0 + z/(1-a*z))
+	}
+	return level(alpha), level(1 - alpha)
+}
+
+// Función de distribución de la normal estándar
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// Inversa de la función de distribución de la normal estándar
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
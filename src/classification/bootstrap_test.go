@@ -0,0 +1,65 @@
+package classification
+
+import "testing"
+
+// Media de las predicciones, para que el intervalo se pueda razonar a mano
+func meanPrediction(predictions, _ []float64) float64 {
+	mean, _ := meanStd(predictions)
+	return mean
+}
+
+func sample() ([]float64, []float64) {
+	predictions := make([]float64, 200)
+	for i := range predictions {
+		predictions[i] = float64(i%10) / 10
+	}
+	return predictions, make([]float64, len(predictions))
+}
+
+func TestBootstrapIntervalContainsEstimate(t *testing.T) {
+	predictions, actuals := sample()
+	for _, method := range []IntervalMethod{PercentileInterval, BCaInterval} {
+		b := Bootstrap{Resamples: 500, Method: method, Seed: 3}
+		interval, err := b.Interval(meanPrediction, predictions, actuals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if interval.Estimate != 0.45 {
+			t.Errorf("%v: estimación = %v, se esperaba 0.45", method, interval.Estimate)
+		}
+		if !(interval.Lower < 0.45 && 0.45 < interval.Upper) || interval.StdErr <= 0 {
+			t.Errorf("%v: intervalo = %+v", method, interval)
+		}
+		// Error estándar de la media: σ/√n = 0.287/√200 ≈ 0.02
+		if interval.StdErr < 0.015 || interval.StdErr > 0.026 {
+			t.Errorf("%v: error estándar = %v, se esperaba ≈ 0.02", method, interval.StdErr)
+		}
+	}
+}
+
+func TestBootstrapDoesNotDependOnWorkers(t *testing.T) {
+	predictions, actuals := sample()
+	one := Bootstrap{Resamples: 200, Workers: 1, Seed: 9}
+	many := Bootstrap{Resamples: 200, Workers: 8, Seed: 9}
+	a, err := one.Interval(meanPrediction, predictions, actuals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := many.Interval(meanPrediction, predictions, actuals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Errorf("con 1 y 8 workers: %+v y %+v", a, b)
+	}
+}
+
+func TestBootstrapErrors(t *testing.T) {
+	b := Bootstrap{Confidence: 1.5}
+	if _, err := b.Interval(meanPrediction, []float64{1}, []float64{1}); err == nil {
+		t.Error("confianza fuera de (0, 1): se esperaba un error")
+	}
+	if _, err := (&Bootstrap{}).Interval(meanPrediction, nil, nil); err == nil {
+		t.Error("sin filas: se esperaba un error")
+	}
+}
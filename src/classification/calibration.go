@@ -0,0 +1,122 @@
+package classification
+
+import (
+	"errors"
+	"math"
+)
+
+// Las probabilidades se recortan a [eps, 1-eps] para que el log loss sea finito
+const probabilityEps = 1e-15
+
+// ReliabilityBin es un intervalo del diagrama de fiabilidad: compara la
+// probabilidad media predicha con la fracción real de positivos
+type ReliabilityBin struct {
+	Lower            float64 // Límite inferior (incluido) de las probabilidades del intervalo
+	Upper            float64 // Límite superior (excluido salvo en el último intervalo)
+	Count            int
+	MeanPredicted    float64 // Probabilidad media predicha; 0 si el intervalo está vacío
+	FractionPositive float64 // Fracción de etiquetas 1; 0 si el intervalo está vacío
+}
+
+// Valida probabilidades y etiquetas
+func checkProbas(probas, labels []float64) error {
+	if len(probas) != len(labels) {
+		return ErrLengthMismatch
+	}
+	if len(probas) == 0 {
+		return errors.New("no hay probabilidades que evaluar")
+	}
+	for _, p := range probas {
+		if !(p >= 0 && p <= 1) {
+			return errors.New("las probabilidades deben estar entre 0 y 1")
+		}
+	}
+	return nil
+}
+
+// LogLoss calcula la entropía cruzada binaria media de las probabilidades de la clase 1
+func LogLoss(probas, labels []float64) (float64, error) {
+	if err := checkProbas(probas, labels); err != nil {
+		return 0, err
+	}
+	loss := sumOver(len(probas), func(i int) (float64, bool) {
+		p := math.Min(math.Max(probas[i], probabilityEps), 1-probabilityEps)
+		if labels[i] == 1 {
+			return -math.Log(p), true
+		}
+		return -math.Log(1 - p), true
+	})
+	return loss.sum / float64(len(probas)), nil
+}
+
+// BrierScore calcula el error cuadrático medio entre la probabilidad de la clase 1 y la etiqueta
+func BrierScore(probas, labels []float64) (float64, error) {
+	if err := checkProbas(probas, labels); err != nil {
+		return 0, err
+	}
+	loss := sumOver(len(probas), func(i int) (float64, bool) {
+		target := 0.0
+		if labels[i] == 1 {
+			target = 1
+		}
+		return (probas[i] - target) * (probas[i] - target), true
+	})
+	return loss.sum / float64(len(probas)), nil
+}
+
+// ReliabilityCurve reparte las probabilidades en bins intervalos de igual
+// ancho sobre [0, 1]
+func ReliabilityCurve(probas, labels []float64, bins int) ([]ReliabilityBin, error) {
+	if bins < 1 {
+		return nil, errors.New("el número de intervalos debe ser positivo")
+	}
+	if err := checkProbas(probas, labels); err != nil {
+		return nil, err
+	}
+
+	// Cada bloque acumula conteos y sumas por intervalo; se normalizan al final
+	curve := MapReduce(len(probas), metricChunk, metricWorkers(len(probas)), func(lo, hi int) []ReliabilityBin {
+		curve := make([]ReliabilityBin, bins)
+		for i := lo; i < hi; i++ {
+			b := min(int(probas[i]*float64(bins)), bins-1)
+			curve[b].Count++
+			curve[b].MeanPredicted += probas[i]
+			if labels[i] == 1 {
+				curve[b].FractionPositive++
+			}
+		}
+		return curve
+	}, func(a, b []ReliabilityBin) []ReliabilityBin {
+		for i := range a {
+			a[i].Count += b[i].Count
+			a[i].MeanPredicted += b[i].MeanPredicted
+			a[i].FractionPositive += b[i].FractionPositive
+		}
+		return a
+	})
+	for b := range curve {
+		curve[b].Lower = float64(b) / float64(bins)
+		curve[b].Upper = float64(b+1) / float64(bins)
+	}
+	for b := range curve {
+		if n := float64(curve[b].Count); n > 0 {
+			curve[b].MeanPredicted /= n
+			curve[b].FractionPositive /= n
+		}
+	}
+	return curve, nil
+}
+
+// ExpectedCalibrationError es la diferencia media entre confianza y acierto de
+// los intervalos de ReliabilityCurve, ponderada por el número de filas de cada uno
+func ExpectedCalibrationError(probas, labels []float64, bins int) (float64, error) {
+	curve, err := ReliabilityCurve(probas, labels, bins)
+	if err != nil {
+		return 0, err
+	}
+	ece := 0.0
+	for _, bin := range curve {
+		ece += float64(bin.Count) * math.Abs(bin.MeanPredicted-bin.FractionPositive)
+	}
+	return ece / float64(len(probas)), nil
+}
@@ -0,0 +1,74 @@
+package classification
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLogLossAndBrier(t *testing.T) {
+	probas := []float64{0.9, 0.2, 0.6, 0.5}
+	labels := []float64{1, 0, 0, 1}
+	logLoss, err := LogLoss(probas, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := -(math.Log(0.9) + math.Log(0.8) + math.Log(0.4) + math.Log(0.5)) / 4; math.Abs(logLoss-want) > 1e-12 {
+		t.Errorf("LogLoss = %v, se esperaba %v", logLoss, want)
+	}
+	brier, err := BrierScore(probas, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (0.01 + 0.04 + 0.36 + 0.25) / 4; math.Abs(brier-want) > 1e-12 {
+		t.Errorf("BrierScore = %v, se esperaba %v", brier, want)
+	}
+}
+
+func TestLogLossIsFiniteForCertainErrors(t *testing.T) {
+	loss, err := LogLoss([]float64{0, 1}, []float64{1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.IsInf(loss, 0) || math.IsNaN(loss) {
+		t.Errorf("LogLoss = %v, se esperaba un valor finito", loss)
+	}
+}
+
+func TestProbabilityMetricsRejectInvalidInput(t *testing.T) {
+	for name, probas := range map[string][]float64{
+		"mayor que 1": {0.5, 1.2},
+		"negativa":    {-0.1, 0.5},
+		"NaN":         {math.NaN(), 0.5},
+	} {
+		if _, err := LogLoss(probas, []float64{0, 1}); err == nil {
+			t.Errorf("%s: se esperaba un error", name)
+		}
+	}
+	if _, err := BrierScore([]float64{0.5}, []float64{0, 1}); err != ErrLengthMismatch {
+		t.Errorf("error = %v, se esperaba ErrLengthMismatch", err)
+	}
+}
+
+func TestReliabilityCurveAndECE(t *testing.T) {
+	probas := []float64{0.1, 0.1, 0.3, 0.8, 0.9, 1}
+	labels := []float64{0, 0, 1, 1, 1, 0}
+	curve, err := ReliabilityCurve(probas, labels, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// [0, 0.5): 3 filas, media 0.5/3, 1 positivo; [0.5, 1]: 3 filas, media 0.9, 2 positivos
+	low, high := curve[0], curve[1]
+	if low.Count != 3 || math.Abs(low.MeanPredicted-0.5/3) > 1e-12 || math.Abs(low.FractionPositive-1.0/3) > 1e-12 {
+		t.Errorf("primer intervalo = %+v", low)
+	}
+	if high.Count != 3 || math.Abs(high.MeanPredicted-0.9) > 1e-12 || math.Abs(high.FractionPositive-2.0/3) > 1e-12 {
+		t.Errorf("segundo intervalo = %+v", high)
+	}
+	ece, err := ExpectedCalibrationError(probas, labels, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (math.Abs(0.5/3-1.0/3) + math.Abs(0.9-2.0/3)) / 2; math.Abs(ece-want) > 1e-12 {
+		t.Errorf("ECE = %v, se esperaba %v", ece, want)
+	}
+}
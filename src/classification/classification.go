@@ -1,11 +1,17 @@
 package classification
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"slices"
+	"src/data"
+	"src/models"
+	"strings"
 )
 
-// Función para calcular la accuracy
-func accuracy(tp, tn, fp, fn int) float64 {
+// Accuracy calcula la fracción de predicciones correctas
+func Accuracy(tp, tn, fp, fn int) float64 {
 	total := tp + tn + fp + fn
 	if total == 0 {
 		return 0.0
@@ -14,24 +20,24 @@ func accuracy(tp, tn, fp, fn int) float64 {
 	return float64(tp+tn) / float64(total)
 }
 
-// Function to calculate precision
-func precision(tp, fp int) float64 {
+// Precision calcula la fracción de positivos predichos que son positivos reales
+func Precision(tp, fp int) float64 {
 	if tp+fp == 0 {
 		return 0
 	}
 	return float64(tp) / float64(tp+fp)
 }
 
-// Function to calculate recall
-func recall(tp, fn int) float64 {
+// Recall calcula la fracción de positivos reales que el modelo detecta
+func Recall(tp, fn int) float64 {
 	if tp+fn == 0 {
 		return 0
 	}
 	return float64(tp) / float64(tp+fn)
 }
 
-// Function to calculate F1-score
-func f1Score(precision, recall float64) float64 {
+// F1Score calcula la media armónica de precision y recall
+func F1Score(precision, recall float64) float64 {
 	if precision+recall == 0 {
 		return 0
 	}
@@ -39,16 +45,381 @@ func f1Score(precision, recall float64) float64 {
 }
 
 // MSE calcula el error cuadrático medio
-func mse(predictions []float64, actuals []float64) float64 {
-	sum := 0.0
-	for i := range predictions {
-		diff := predictions[i] - actuals[i]
-		sum += diff * diff
+func MSE(predictions []float64, actuals []float64) float64 {
+	if len(actuals) == 0 {
+		return 0
 	}
-	return sum / float64(len(actuals))
+	p := sumOver(len(actuals), func(i int) (float64, bool) {
+		diff := predictions[i] - actuals[i]
+		return diff * diff, true
+	})
+	return p.sum / float64(len(actuals))
 }
 
 // RMSE calcula la raíz del error cuadrático medio
-func rmse(predictions []float64, actuals []float64) float64 {
-	return math.Sqrt(mse(predictions, actuals))
+func RMSE(predictions []float64, actuals []float64) float64 {
+	return math.Sqrt(MSE(predictions, actuals))
+}
+
+// Report reúne las métricas de un modelo sobre un conjunto de datos. Los
+// clasificadores rellenan la matriz de confusión y sus métricas; los modelos
+// evaluados sobre un objetivo continuo, los errores de regresión
+type Report struct {
+	Samples        int
+	Classification bool
+
+	Matrix         *Confusion[int] // Matriz N×N de todas las clases
+	TP, TN, FP, FN int             // Conteos de la clase positiva 1
+	Accuracy       float64
+	Precision      float64 // De la clase 1 si las etiquetas son 0/1; si no, promedio macro
+	Recall         float64
+	F1             float64
+
+	Scored           bool    // Hay probabilidades (PredictProba) de la clase 1
+	LogLoss          float64 // Entropía cruzada de las probabilidades
+	Brier            float64 // Error cuadrático medio de las probabilidades
+	ECE              float64 // Error de calibración esperado con ReportBins intervalos
+	Ranked           bool    // Las etiquetas tienen ambas clases y hay métricas de ranking
+	ROCAUC           float64 // Área bajo la curva ROC
+	AveragePrecision float64 // Resumen de la curva precision-recall
+
+	MSE               float64
+	RMSE              float64
+	MAE               float64
+	MAPE              float64 // NaN si todos los valores reales son 0
+	MedianAE          float64
+	R2                float64
+	AdjustedR2        float64 // NaN si no se conoce el número de características
+	ExplainedVariance float64
+	Huber             float64 // Pérdida de Huber con HuberDelta
+	Residuals         ResidualSummary
+}
+
+// ClassificationReport evalúa predicciones de etiquetas de clase. Con etiquetas
+// 0/1 precision, recall y F1 son los de la clase 1; con más clases, su promedio macro
+func ClassificationReport(predictions, actuals []float64) Report {
+	matrix, _ := NewConfusion(toInts(predictions), toInts(actuals))
+	tp, tn, fp, fn := ConfusionMatrix(toInts(predictions), toInts(actuals))
+	scores := Scores{Precision: Precision(tp, fp), Recall: Recall(tp, fn)}
+	scores.F1 = F1Score(scores.Precision, scores.Recall)
+	if !isBinary(matrix.Labels) {
+		scores = matrix.Average(Macro)
+	}
+	return Report{
+		Samples:        len(actuals),
+		Classification: true,
+		Matrix:         matrix,
+		TP:             tp,
+		TN:             tn,
+		FP:             fp,
+		FN:             fn,
+		Accuracy:       matrix.Accuracy(),
+		Precision:      scores.Precision,
+		Recall:         scores.Recall,
+		F1:             scores.F1,
+	}
+}
+
+// Indica si todas las clases son 0 o 1
+func isBinary(labels []int) bool {
+	for _, label := range labels {
+		if label != 0 && label != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// RegressionReport evalúa predicciones de un objetivo continuo. AdjustedR2
+// queda en NaN; SetFeatures lo calcula
+func RegressionReport(predictions, actuals []float64) Report {
+	return Report{
+		Samples:           len(actuals),
+		MSE:               MSE(predictions, actuals),
+		RMSE:              RMSE(predictions, actuals),
+		MAE:               MAE(predictions, actuals),
+		MAPE:              MAPE(predictions, actuals),
+		MedianAE:          MedianAE(predictions, actuals),
+		R2:                R2(predictions, actuals),
+		AdjustedR2:        math.NaN(),
+		ExplainedVariance: ExplainedVariance(predictions, actuals),
+		Huber:             HuberLoss(predictions, actuals, HuberDelta),
+		Residuals:         Residuals(predictions, actuals),
+	}
+}
+
+// SetFeatures calcula el R² ajustado de un reporte de regresión con p características
+func (r *Report) SetFeatures(p int) {
+	if !r.Classification {
+		r.AdjustedR2 = AdjustedR2(r.R2, r.Samples, p)
+	}
+}
+
+// Task es el tipo de problema con el que se evalúa un modelo
+type Task int
+
+const (
+	AutoTask           Task = iota // Clasificación si el modelo es un clasificador y las etiquetas son enteras
+	ClassificationTask             // Matriz de confusión y métricas de clasificación
+	RegressionTask                 // Errores de regresión sobre la salida sin umbral
+)
+
+func (t Task) String() string {
+	switch t {
+	case AutoTask:
+		return "auto"
+	case ClassificationTask:
+		return "classification"
+	case RegressionTask:
+		return "regression"
+	}
+	return fmt.Sprintf("Task(%d)", int(t))
+}
+
+// ParseTask convierte "auto", "classification" o "regression" en su Task
+func ParseTask(name string) (Task, error) {
+	switch name {
+	case "auto":
+		return AutoTask, nil
+	case "classification":
+		return ClassificationTask, nil
+	case "regression":
+		return RegressionTask, nil
+	}
+	return 0, fmt.Errorf("tarea desconocida %q (use auto, classification o regression)", name)
+}
+
+// Evaluate devuelve el reporte de clasificación o el de regresión según task.
+// AutoTask elige clasificación si el modelo es un clasificador (binario o
+// multiclase) y las etiquetas son clases enteras, y regresión en otro caso
+func Evaluate(model models.Regressor, predictions, actuals []float64, task Task) Report {
+	if task.classification(model, actuals) {
+		return ClassificationReport(predictions, actuals)
+	}
+	return RegressionReport(predictions, actuals)
+}
+
+// Indica si el modelo se evalúa como clasificador. Con AutoTask un objetivo
+// con valores no enteros es continuo aunque el modelo sea un clasificador
+func (t Task) classification(model models.Regressor, actuals []float64) bool {
+	switch t {
+	case ClassificationTask:
+		return true
+	case RegressionTask:
+		return false
+	}
+	_, binary := model.(models.Classifier)
+	_, multi := model.(models.MultiClassifier)
+	if !binary && !multi {
+		return false
+	}
+	for _, v := range actuals {
+		if v != math.Trunc(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Intervalos de probabilidad con los que Report calcula el ECE
+const ReportBins = 10
+
+// AddScores completa el reporte con las métricas de las probabilidades de la
+// clase 1. Las de ranking (ROC-AUC y average precision) se omiten si las
+// etiquetas tienen una sola clase o no son binarias
+func (r *Report) AddScores(probas, actuals []float64) error {
+	var err error
+	if r.LogLoss, err = LogLoss(probas, actuals); err != nil {
+		return err
+	}
+	if r.Brier, err = BrierScore(probas, actuals); err != nil {
+		return err
+	}
+	if r.ECE, err = ExpectedCalibrationError(probas, actuals, ReportBins); err != nil {
+		return err
+	}
+	r.Scored = true
+
+	auc, err := ROCAUC(probas, actuals)
+	if errors.Is(err, ErrSingleClass) || errors.Is(err, ErrNotBinary) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	ap, err := AveragePrecision(probas, actuals)
+	if err != nil {
+		return err
+	}
+	r.Ranked, r.ROCAUC, r.AveragePrecision = true, auc, ap
+	return nil
+}
+
+// EvaluateDataset predice las filas del dataset con un modelo entrenado y lo
+// evalúa eligiendo la tarea con AutoTask. Los clasificadores binarios añaden
+// las métricas de sus probabilidades; si el objetivo es continuo se evalúa su
+// salida sin umbral (PredictProba), que en el árbol de decisión es la media
+// de la hoja
+func EvaluateDataset(model models.Regressor, ds *data.Dataset) (Report, error) {
+	return EvaluateDatasetAs(model, ds, AutoTask)
+}
+
+// EvaluateDatasetAs es EvaluateDataset con la tarea indicada
+func EvaluateDatasetAs(model models.Regressor, ds *data.Dataset, task Task) (Report, error) {
+	out, err := datasetOutputs(model, ds, task)
+	if err != nil {
+		return Report{}, err
+	}
+	return out.report(nil)
+}
+
+// Salidas de un modelo sobre un dataset, listas para evaluarse sobre
+// cualquier subconjunto de filas
+type outputs struct {
+	classification bool
+	predictions    []float64 // Etiquetas o valores predichos
+	probas         []float64 // Probabilidades de la clase 1; nil si no hay
+	actuals        []float64
+	features       int
+}
+
+// Predice las filas del dataset como lo evalúa EvaluateDataset
+func datasetOutputs(model models.Regressor, ds *data.Dataset, task Task) (outputs, error) {
+	X, err := ds.Matrix()
+	if err != nil {
+		return outputs{}, err
+	}
+	return modelOutputs(model, X, ds.Labels(), task), nil
+}
+
+// Predice las filas de X: los clasificadores evaluados como tales dan sus
+// etiquetas y probabilidades, y los evaluados como regresión su salida sin umbral
+func modelOutputs(model models.Regressor, X [][]float64, actuals []float64, task Task) outputs {
+	out := outputs{classification: task.classification(model, actuals), actuals: actuals}
+	if len(X) > 0 {
+		out.features = len(X[0])
+	}
+	classifier, isClassifier := model.(models.Classifier)
+	switch {
+	case out.classification:
+		out.predictions = model.Predict(X)
+		if isClassifier {
+			out.probas = classifier.PredictProba(X)
+		}
+	case isClassifier:
+		out.predictions = classifier.PredictProba(X)
+	default:
+		out.predictions = model.Predict(X)
+	}
+	return out
+}
+
+// Reporte de las filas indicadas; nil evalúa todas
+func (o outputs) report(indices []int) (Report, error) {
+	predictions, probas, actuals := o.predictions, o.probas, o.actuals
+	if indices != nil {
+		predictions, actuals = pick(predictions, indices), pick(actuals, indices)
+		if probas != nil {
+			probas = pick(probas, indices)
+		}
+	}
+
+	if !o.classification {
+		report := RegressionReport(predictions, actuals)
+		if o.features > 0 {
+			report.SetFeatures(o.features)
+		}
+		return report, nil
+	}
+	report := ClassificationReport(predictions, actuals)
+	// Las probabilidades son de la clase 1: solo tienen sentido con clases 0 y 1
+	if probas != nil && slices.Equal(report.Matrix.Labels, []int{0, 1}) {
+		if err := report.AddScores(probas, actuals); err != nil {
+			return Report{}, err
+		}
+	}
+	return report, nil
+}
+
+// Valores en las posiciones indicadas
+func pick(values []float64, indices []int) []float64 {
+	out := make([]float64, len(indices))
+	for i, idx := range indices {
+		out[i] = values[idx]
+	}
+	return out
+}
+
+// Metrics devuelve las métricas del reporte por nombre
+func (r Report) Metrics() map[string]float64 {
+	if !r.Classification {
+		metrics := map[string]float64{
+			"mse":                r.MSE,
+			"rmse":               r.RMSE,
+			"mae":                r.MAE,
+			"median_ae":          r.MedianAE,
+			"r2":                 r.R2,
+			"explained_variance": r.ExplainedVariance,
+			"huber":              r.Huber,
+		}
+		// Las métricas indefinidas se omiten (JSON no admite NaN)
+		if !math.IsNaN(r.MAPE) {
+			metrics["mape"] = r.MAPE
+		}
+		if !math.IsNaN(r.AdjustedR2) {
+			metrics["adjusted_r2"] = r.AdjustedR2
+		}
+		return metrics
+	}
+	metrics := map[string]float64{
+		"accuracy":  r.Accuracy,
+		"precision": r.Precision,
+		"recall":    r.Recall,
+		"f1":        r.F1,
+	}
+	if r.Scored {
+		metrics["log_loss"] = r.LogLoss
+		metrics["brier"] = r.Brier
+		metrics["ece"] = r.ECE
+	}
+	if r.Ranked {
+		metrics["roc_auc"] = r.ROCAUC
+		metrics["average_precision"] = r.AveragePrecision
+	}
+	return metrics
+}
+
+// String muestra el reporte en varias líneas
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Muestras: %d\n", r.Samples)
+	if !r.Classification {
+		fmt.Fprintf(&b, "MSE: %.4f\nRMSE: %.4f\nMAE: %.4f\nMAPE: %.4f\nMedian AE: %.4f\n", r.MSE, r.RMSE, r.MAE, r.MAPE, r.MedianAE)
+		fmt.Fprintf(&b, "R²: %.4f\nR² ajustado: %.4f\nVarianza explicada: %.4f\nHuber: %.4f\n", r.R2, r.AdjustedR2, r.ExplainedVariance, r.Huber)
+		res := r.Residuals
+		fmt.Fprintf(&b, "Residuos: media %.4f, desviación %.4f, mín %.4f, Q1 %.4f, mediana %.4f, Q3 %.4f, máx %.4f\n",
+			res.Mean, res.Std, res.Min, res.Q1, res.Median, res.Q3, res.Max)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "TP: %d, TN: %d, FP: %d, FN: %d\n", r.TP, r.TN, r.FP, r.FN)
+	fmt.Fprintf(&b, "Accuracy: %.4f\nPrecision: %.4f\nRecall: %.4f\nF1: %.4f\n", r.Accuracy, r.Precision, r.Recall, r.F1)
+	if r.Scored {
+		fmt.Fprintf(&b, "Log loss: %.4f\nBrier: %.4f\nECE: %.4f\n", r.LogLoss, r.Brier, r.ECE)
+	}
+	if r.Ranked {
+		fmt.Fprintf(&b, "ROC-AUC: %.4f\nAverage precision: %.4f\n", r.ROCAUC, r.AveragePrecision)
+	}
+	if r.Matrix != nil {
+		b.WriteString("\n")
+		r.Matrix.WriteTable(&b)
+	}
+	return b.String()
+}
+
+// Convierte etiquetas reales en enteros
+func toInts(values []float64) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
 }
@@ -0,0 +1,134 @@
+package classification_test
+
+import (
+	"fmt"
+	"src/classification"
+	"src/data"
+	decisiontree "src/models/decision_tree"
+	"strings"
+	"testing"
+)
+
+// Dataset con una característica y la clase (row % classes) separada en
+// intervalos, que el árbol aprende sin errores
+func separable(t *testing.T, classes int) *data.Dataset {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("x,label\n")
+	for i := range 60 {
+		class := i % classes
+		fmt.Fprintf(&b, "%d.%d,%d\n", class*10, i, class)
+	}
+	ds, err := data.ReadCSV(strings.NewReader(b.String()), data.CSVOptions{Target: "label"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+func TestEvaluateDataset(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes int
+		scored  bool // Métricas de probabilidad (log loss, Brier, ECE)
+		ranked  bool // ROC-AUC y average precision
+	}{
+		{"binario", 2, true, true},
+		{"tres clases", 3, false, false},
+		{"cinco clases", 5, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := separable(t, tt.classes)
+			tree := decisiontree.NewDecisionTree()
+			tree.MaxDepth = 5
+			X, err := ds.Matrix()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tree.Fit(X, ds.Labels()); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := classification.EvaluateDataset(tree, ds)
+			if err != nil {
+				t.Fatalf("EvaluateDataset: %v", err)
+			}
+			if !report.Classification {
+				t.Fatal("el objetivo entero debe evaluarse como clasificación")
+			}
+			if report.Accuracy != 1 {
+				t.Errorf("accuracy = %v, se esperaba 1", report.Accuracy)
+			}
+			if len(report.Matrix.Labels) != tt.classes {
+				t.Errorf("clases de la matriz = %v, se esperaban %d", report.Matrix.Labels, tt.classes)
+			}
+			if report.Scored != tt.scored || report.Ranked != tt.ranked {
+				t.Errorf("Scored, Ranked = %v, %v; se esperaba %v, %v", report.Scored, report.Ranked, tt.scored, tt.ranked)
+			}
+		})
+	}
+}
+
+func TestEvaluateDatasetAs(t *testing.T) {
+	ds := separable(t, 2)
+	tree := decisiontree.NewDecisionTree()
+	X, err := ds.Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Fit(X, ds.Labels()); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name           string
+		classification bool
+	}{
+		{"auto", true},
+		{"classification", true},
+		{"regression", false},
+	} {
+		task, err := classification.ParseTask(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.String() != tt.name {
+			t.Errorf("ParseTask(%q).String() = %q", tt.name, task)
+		}
+		report, err := classification.EvaluateDatasetAs(tree, ds, task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Classification != tt.classification {
+			t.Errorf("%s: Classification = %v, se esperaba %v", tt.name, report.Classification, tt.classification)
+		}
+	}
+	if _, err := classification.ParseTask("ranking"); err == nil {
+		t.Error("ParseTask aceptó una tarea desconocida")
+	}
+
+	// Forzar la clasificación evalúa un objetivo no entero por sus clases truncadas
+	report := classification.Evaluate(tree, []float64{0, 1}, []float64{0.5, 1.5}, classification.ClassificationTask)
+	if !report.Classification || report.Accuracy != 1 {
+		t.Errorf("Classification, Accuracy = %v, %v; se esperaba true, 1", report.Classification, report.Accuracy)
+	}
+	if report := classification.Evaluate(tree, []float64{0, 1}, []float64{0.5, 1.5}, classification.AutoTask); report.Classification {
+		t.Error("AutoTask debe evaluar un objetivo no entero como regresión")
+	}
+}
+
+func TestClassificationReportMacroAverage(t *testing.T) {
+	// Clase 0: P = 1, R = 1/2; clase 1: P = 1/2, R = 1; clase 2: P = R = 1
+	predictions := []float64{0, 1, 1, 2}
+	actuals := []float64{0, 0, 1, 2}
+	report := classification.ClassificationReport(predictions, actuals)
+	if report.Accuracy != 0.75 {
+		t.Errorf("accuracy = %v, se esperaba 0.75", report.Accuracy)
+	}
+	if want := (1 + 0.5 + 1) / 3.0; report.Precision != want {
+		t.Errorf("precision macro = %v, se esperaba %v", report.Precision, want)
+	}
+	if want := (0.5 + 1 + 1) / 3.0; report.Recall != want {
+		t.Errorf("recall macro = %v, se esperaba %v", report.Recall, want)
+	}
+}
@@ -1,61 +1,30 @@
 package classification
 
-import "sync"
-
-// ConfusionMatrix genera una matriz de confusión
+// ConfusionMatrix genera la matriz de confusión binaria de las etiquetas 0 y 1;
+// las demás etiquetas se ignoran. Para varias clases use NewConfusion
 func ConfusionMatrix(predictions []int, actuals []int) (tp, tn, fp, fn int) {
-	for i := range predictions {
-		if predictions[i] == 1 && actuals[i] == 1 {
-			tp++
-		} else if predictions[i] == 0 && actuals[i] == 0 {
-			tn++
-		} else if predictions[i] == 1 && actuals[i] == 0 {
-			fp++
-		} else if predictions[i] == 0 && actuals[i] == 1 {
-			fn++
-		}
-	}
-	return tp, tn, fp, fn
+	return ConfusionMatrixConcurrent(predictions, actuals, metricWorkers(len(actuals)))
 }
 
-func confusionMatrixConcurrent(actual []int, predicted []int) (tp, fp, tn, fn int) {
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	numGoroutines := 4 // Número de goroutines (ajuste según CPU)
-	chunkSize := len(actual) / numGoroutines
-
-	for i := 0; i < numGoroutines; i++ {
-		startIdx := i * chunkSize
-		endIdx := startIdx + chunkSize
-		if i == numGoroutines-1 {
-			endIdx = len(actual)
-		}
-
-		wg.Add(1)
-		go func(start, end int) {
-			defer wg.Done()
-			localTP, localFP, localTN, localFN := 0, 0, 0, 0
-			for j := start; j < end; j++ {
-				if actual[j] == 1 && predicted[j] == 1 {
-					localTP++
-				} else if actual[j] == 0 && predicted[j] == 1 {
-					localFP++
-				} else if actual[j] == 0 && predicted[j] == 0 {
-					localTN++
-				} else if actual[j] == 1 && predicted[j] == 0 {
-					localFN++
-				}
+// ConfusionMatrixConcurrent cuenta la matriz de ConfusionMatrix repartiendo
+// las filas en bloques entre workers goroutines (<= 0 usa GOMAXPROCS)
+func ConfusionMatrixConcurrent(predictions []int, actuals []int, workers int) (tp, tn, fp, fn int) {
+	counts := MapReduce(len(actuals), metricChunk, workers, func(lo, hi int) [4]int {
+		var c [4]int
+		for i := lo; i < hi; i++ {
+			if predictions[i] == 1 && actuals[i] == 1 {
+				c[0]++
+			} else if predictions[i] == 0 && actuals[i] == 0 {
+				c[1]++
+			} else if predictions[i] == 1 && actuals[i] == 0 {
+				c[2]++
+			} else if predictions[i] == 0 && actuals[i] == 1 {
+				c[3]++
 			}
-			mu.Lock()
-			tp += localTP
-			fp += localFP
-			tn += localTN
-			fn += localFN
-			mu.Unlock()
-		}(startIdx, endIdx)
-	}
-
-	wg.Wait()
-	return
+		}
+		return c
+	}, func(a, b [4]int) [4]int {
+		return [4]int{a[0] + b[0], a[1] + b[1], a[2] + b[2], a[3] + b[3]}
+	})
+	return counts[0], counts[1], counts[2], counts[3]
 }
@@ -0,0 +1,229 @@
+package classification
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"src/data"
+	"src/models"
+	"sync"
+)
+
+// Scorer calcula las métricas de un fold a partir de las predicciones del
+// modelo entrenado y de las etiquetas reales
+type Scorer func(model models.Regressor, predictions, actuals []float64) map[string]float64
+
+// CrossValidator evalúa un modelo con validación cruzada k-fold. Cada fold
+// entrena una copia independiente del modelo y los folds se entrenan en
+// paralelo con como máximo Workers goroutines
+type CrossValidator struct {
+	K          int    // Número de folds; 0 usa 5
+	Stratified bool   // Conserva en cada fold la proporción de cada clase
+	Repeats    int    // Repeticiones con barajados distintos; 0 usa 1
+	Workers    int    // Folds entrenados a la vez; 0 usa GOMAXPROCS
+	Seed       int64  // Semilla del barajado de las filas
+	Task       Task   // Tarea con que se evalúa cada fold; AutoTask la deduce
+	Score      Scorer // Métricas de cada fold; nil usa las del reporte de EvaluateDatasetAs
+}
+
+// MetricSummary resume una métrica sobre todos los folds
+type MetricSummary struct {
+	Mean   float64   `json:"mean"`
+	Std    float64   `json:"std"`    // Desviación estándar poblacional entre folds
+	Values []float64 `json:"values"` // Valor en cada fold, en orden de repetición y fold
+}
+
+// CVResult es el resultado de una validación cruzada
+type CVResult struct {
+	K       int                      `json:"k"`     // Folds de cada repetición
+	Folds   int                      `json:"folds"` // Folds evaluados (K × Repeats)
+	Metrics map[string]MetricSummary `json:"metrics"`
+}
+
+// Errores devueltos por la validación cruzada
+var (
+	ErrEmptyFold = errors.New("un fold quedó sin filas de entrenamiento o de prueba")
+)
+
+// CrossValidate evalúa el modelo con k-fold simple, la semilla por defecto y
+// las métricas del reporte de EvaluateDataset
+func CrossValidate(model models.Regressor, X [][]float64, y []float64, k int) (*CVResult, error) {
+	cv := CrossValidator{K: k, Seed: data.DefaultSeed}
+	return cv.Run(model, X, y)
+}
+
+// Run evalúa el modelo sobre X e y. El modelo recibido no se modifica
+func (cv *CrossValidator) Run(model models.Regressor, X [][]float64, y []float64) (*CVResult, error) {
+	if err := models.CheckFit(X, y); err != nil {
+		return nil, err
+	}
+	return cv.run(model, y, func(m models.Regressor, train, test []int) (outputs, error) {
+		trainX, trainY := rows(X, y, train)
+		testX, testY := rows(X, y, test)
+		if err := m.Fit(trainX, trainY); err != nil {
+			return outputs{}, err
+		}
+		return modelOutputs(m, testX, testY, cv.Task), nil
+	})
+}
+
+// RunDataset evalúa el modelo sobre un dataset. Si pipeline no es nil, en cada
+// fold se ajusta una copia del pipeline solo con las filas de entrenamiento
+func (cv *CrossValidator) RunDataset(model models.Regressor, ds *data.Dataset, pipeline *data.Pipeline) (*CVResult, error) {
+	if pipeline == nil {
+		pipeline = &data.Pipeline{}
+	}
+	return cv.run(model, ds.Labels(), func(m models.Regressor, train, test []int) (outputs, error) {
+		p, err := pipeline.Clone()
+		if err != nil {
+			return outputs{}, err
+		}
+		trainSet, err := p.FitTransform(ds.Subset(train))
+		if err != nil {
+			return outputs{}, err
+		}
+		testSet, err := p.Transform(ds.Subset(test))
+		if err != nil {
+			return outputs{}, err
+		}
+		if err := models.FitDataset(m, trainSet); err != nil {
+			return outputs{}, err
+		}
+		return datasetOutputs(m, testSet, cv.Task)
+	})
+}
+
+// Función que entrena un modelo con las filas train y predice las filas test
+// como lo hace EvaluateDatasetAs
+type foldFunc func(m models.Regressor, train, test []int) (outputs, error)
+
+// Reparte los folds de todas las repeticiones entre los workers y resume las métricas
+func (cv *CrossValidator) run(model models.Regressor, labels []float64, fit foldFunc) (*CVResult, error) {
+	k, repeats, workers := cv.K, cv.Repeats, cv.Workers
+	if k == 0 {
+		k = 5
+	}
+	if repeats <= 0 {
+		repeats = 1
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	// Sin Scorer cada fold se resume con el reporte de EvaluateDatasetAs, que
+	// incluye las métricas de las probabilidades
+	score := func(m models.Regressor, out outputs) (map[string]float64, error) {
+		if cv.Score != nil {
+			return cv.Score(m, out.predictions, out.actuals), nil
+		}
+		report, err := out.report(nil)
+		return report.Metrics(), err
+	}
+
+	// Folds de todas las repeticiones; cada repetición baraja con su propia semilla
+	type job struct{ train, test []int }
+	var jobs []job
+	for _, seed := range data.DeriveSeeds(data.NewRand(cv.Seed), repeats) {
+		rng := data.NewRand(seed)
+		var folds [][]int
+		var err error
+		if cv.Stratified {
+			folds, err = data.StratifiedKFold(labels, k, rng)
+		} else {
+			folds, err = data.KFold(len(labels), k, rng)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, test := range folds {
+			var train []int
+			for j, fold := range folds {
+				if j != i {
+					train = append(train, fold...)
+				}
+			}
+			if len(train) == 0 || len(test) == 0 {
+				return nil, ErrEmptyFold
+			}
+			jobs = append(jobs, job{train, test})
+		}
+	}
+
+	// Cada resultado se guarda en la posición de su fold, así el resumen no
+	// depende del orden en que terminen las goroutines
+	results := make([]map[string]float64, len(jobs))
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			m, err := models.Clone(model)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			out, err := fit(m, j.train, j.test)
+			if err == nil {
+				results[i], err = score(m, out)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("fold %d: %w", i+1, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	res := summarize(results)
+	res.K = k
+	return res, nil
+}
+
+// Calcula la media y la desviación estándar de cada métrica
+func summarize(results []map[string]float64) *CVResult {
+	res := &CVResult{Folds: len(results), Metrics: make(map[string]MetricSummary)}
+	names := make([]string, 0, len(results[0]))
+	for name := range results[0] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		values := make([]float64, len(results))
+		mean := 0.0
+		for i, r := range results {
+			values[i] = r[name]
+			mean += values[i]
+		}
+		mean /= float64(len(values))
+
+		variance := 0.0
+		for _, v := range values {
+			variance += (v - mean) * (v - mean)
+		}
+		res.Metrics[name] = MetricSummary{Mean: mean, Std: math.Sqrt(variance / float64(len(values))), Values: values}
+	}
+	return res
+}
+
+// Filas indicadas de X e y
+func rows(X [][]float64, y []float64, indices []int) ([][]float64, []float64) {
+	subX := make([][]float64, len(indices))
+	subY := make([]float64, len(indices))
+	for i, idx := range indices {
+		subX[i], subY[i] = X[idx], y[idx]
+	}
+	return subX, subY
+}
+
+// ScoreModel devuelve las métricas del reporte de Evaluate con AutoTask
+func ScoreModel(model models.Regressor, predictions, actuals []float64) map[string]float64 {
+	return Evaluate(model, predictions, actuals, AutoTask).Metrics()
+}
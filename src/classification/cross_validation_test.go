@@ -0,0 +1,123 @@
+package classification_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"src/classification"
+	"src/data"
+	decisiontree "src/models/decision_tree"
+	"strings"
+	"testing"
+)
+
+func TestCrossValidatorDoesNotDependOnWorkers(t *testing.T) {
+	ds := separable(t, 3)
+	X, err := ds.Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(workers int) *classification.CVResult {
+		cv := classification.CrossValidator{K: 4, Stratified: true, Repeats: 2, Workers: workers, Seed: 7}
+		result, err := cv.Run(decisiontree.NewDecisionTree(), X, ds.Labels())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	one, many := run(1), run(4)
+	if one.Folds != 8 {
+		t.Errorf("Folds = %d, se esperaban 8", one.Folds)
+	}
+	if !reflect.DeepEqual(one, many) {
+		t.Errorf("con 1 y 4 workers los resultados difieren:\n%+v\n%+v", one, many)
+	}
+	if accuracy := one.Metrics["accuracy"]; len(accuracy.Values) != 8 || accuracy.Mean < 0.9 {
+		t.Errorf("accuracy = %+v, se esperaban 8 folds con media >= 0.9", accuracy)
+	}
+}
+
+func TestCrossValidatorRejectsNaNLabelsWhenStratified(t *testing.T) {
+	ds := separable(t, 2)
+	X, err := ds.Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := ds.Labels()
+	y[3] = math.NaN()
+	cv := classification.CrossValidator{K: 3, Stratified: true}
+	if _, err := cv.Run(decisiontree.NewDecisionTree(), X, y); !errors.Is(err, data.ErrNaNLabel) {
+		t.Errorf("error = %v, se esperaba data.ErrNaNLabel", err)
+	}
+}
+
+// Dataset con un objetivo continuo de cuatro niveles no enteros en
+// intervalos de la característica
+func continuous(t *testing.T) *data.Dataset {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("x,target\n")
+	for i := range 60 {
+		fmt.Fprintf(&b, "%d,%v\n", i, 0.1+0.25*float64(i/15))
+	}
+	ds, err := data.ReadCSV(strings.NewReader(b.String()), data.CSVOptions{Target: "target"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+func TestCrossValidatorScoresContinuousTargetsLikeEvaluateDataset(t *testing.T) {
+	// Con un objetivo continuo el árbol se evalúa con la media de la hoja
+	// (PredictProba), no con la etiqueta redondeada de Predict
+	ds := continuous(t)
+	X, err := ds.Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv := classification.CrossValidator{K: 3, Seed: 7}
+	fromX, err := cv.Run(decisiontree.NewDecisionTree(), X, ds.Labels())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromDataset, err := cv.RunDataset(decisiontree.NewDecisionTree(), ds, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromX, fromDataset) {
+		t.Errorf("Run y RunDataset difieren:\n%+v\n%+v", fromX, fromDataset)
+	}
+	if _, ok := fromX.Metrics["accuracy"]; ok {
+		t.Error("un objetivo continuo no debe evaluarse como clasificación")
+	}
+	// Redondeando a 0 o 1 los niveles darían un mse de 0.079
+	if mse := fromX.Metrics["mse"].Mean; !(mse < 0.02) {
+		t.Errorf("mse = %v, se esperaba < 0.02 con la media de la hoja", mse)
+	}
+}
+
+func TestCrossValidatorTask(t *testing.T) {
+	ds := separable(t, 2)
+	X, err := ds.Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		task   classification.Task
+		metric string
+	}{
+		{classification.AutoTask, "roc_auc"},
+		{classification.ClassificationTask, "roc_auc"},
+		{classification.RegressionTask, "mse"},
+	} {
+		cv := classification.CrossValidator{K: 3, Seed: 7, Task: tt.task}
+		result, err := cv.Run(decisiontree.NewDecisionTree(), X, ds.Labels())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := result.Metrics[tt.metric]; !ok {
+			t.Errorf("%v: faltan %q entre las métricas %v", tt.task, tt.metric, result.Metrics)
+		}
+	}
+}
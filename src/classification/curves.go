@@ -0,0 +1,253 @@
+package classification
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+)
+
+// Errores de las curvas calculadas a partir de puntuaciones
+var (
+	ErrLengthMismatch = errors.New("la longitud de las puntuaciones y las etiquetas no coinciden")
+	ErrSingleClass    = errors.New("las etiquetas deben incluir la clase positiva (1) y la negativa")
+	ErrNotBinary      = errors.New("las etiquetas deben ser 0 o 1")
+)
+
+// ROCPoint es un punto de la curva ROC: las filas con puntuación >= Threshold
+// se predicen como positivas
+type ROCPoint struct {
+	Threshold float64
+	FPR       float64 // Tasa de falsos positivos
+	TPR       float64 // Tasa de verdaderos positivos (recall)
+}
+
+// PRPoint es un punto de la curva precision-recall
+type PRPoint struct {
+	Threshold float64
+	Precision float64
+	Recall    float64
+}
+
+// Criterion es el criterio con el que OptimalThreshold elige el umbral
+type Criterion int
+
+const (
+	YoudenJ Criterion = iota // Maximiza TPR - FPR
+	BestF1                   // Maximiza F1
+)
+
+// Conteos acumulados al bajar el umbral por cada puntuación distinta
+type ranking struct {
+	thresholds []float64 // Puntuaciones distintas de mayor a menor
+	tps, fps   []int     // Positivos y negativos con puntuación >= thresholds[i]
+	pos, neg   int       // Totales de cada clase
+}
+
+// Ordena las puntuaciones de mayor a menor y acumula los conteos. La clase
+// positiva es la etiqueta 1 y la negativa la 0; con otras etiquetas el
+// problema no es binario y no hay ranking
+func rank(scores, labels []float64) (*ranking, error) {
+	if len(scores) != len(labels) {
+		return nil, ErrLengthMismatch
+	}
+	for _, label := range labels {
+		if label != 0 && label != 1 {
+			return nil, ErrNotBinary
+		}
+	}
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sortIndices(order, func(a, b int) int {
+		if c := cmp.Compare(scores[b], scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	r := &ranking{}
+	tp, fp := 0, 0
+	for k, i := range order {
+		if labels[i] == 1 {
+			tp++
+		} else {
+			fp++
+		}
+		// Solo se cierra un punto cuando cambia la puntuación
+		if k == len(order)-1 || scores[order[k+1]] != scores[i] {
+			r.thresholds = append(r.thresholds, scores[i])
+			r.tps = append(r.tps, tp)
+			r.fps = append(r.fps, fp)
+		}
+	}
+	r.pos, r.neg = tp, fp
+	if r.pos == 0 || r.neg == 0 {
+		return nil, ErrSingleClass
+	}
+	return r, nil
+}
+
+// Ordena los índices con compare. Con ParallelMin elementos o más cada
+// goroutine ordena un bloque y los bloques se mezclan por pares, también en
+// paralelo. compare debe ser un orden total para que el resultado no dependa
+// de los bloques
+func sortIndices(indices []int, compare func(a, b int) int) {
+	workers := metricWorkers(len(indices))
+	if workers < 2 {
+		slices.SortFunc(indices, compare)
+		return
+	}
+
+	size := (len(indices) + workers - 1) / workers
+	var blocks [][]int
+	for start := 0; start < len(indices); start += size {
+		blocks = append(blocks, indices[start:min(start+size, len(indices))])
+	}
+	var wg sync.WaitGroup
+	for _, block := range blocks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slices.SortFunc(block, compare)
+		}()
+	}
+	wg.Wait()
+
+	buf := make([]int, len(indices))
+	for len(blocks) > 1 {
+		merged := make([][]int, (len(blocks)+1)/2)
+		offset := 0
+		for i := 0; i < len(blocks); i += 2 {
+			if i+1 == len(blocks) {
+				merged[i/2] = blocks[i]
+				continue
+			}
+			a, b := blocks[i], blocks[i+1]
+			dst := buf[offset : offset+len(a)+len(b)]
+			offset += len(dst)
+			merged[i/2] = dst
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				mergeInto(dst, a, b, compare)
+			}()
+		}
+		wg.Wait()
+
+		// Los bloques mezclados vuelven a indices para la siguiente ronda
+		offset = 0
+		for i, block := range merged {
+			copy(indices[offset:], block)
+			merged[i] = indices[offset : offset+len(block)]
+			offset += len(block)
+		}
+		blocks = merged
+	}
+}
+
+// Mezcla dos bloques ordenados en dst
+func mergeInto(dst, a, b []int, compare func(a, b int) int) {
+	i, j := 0, 0
+	for k := range dst {
+		if j == len(b) || (i < len(a) && compare(a[i], b[j]) <= 0) {
+			dst[k] = a[i]
+			i++
+		} else {
+			dst[k] = b[j]
+			j++
+		}
+	}
+}
+
+// ROC devuelve la curva ROC de las puntuaciones, desde (0, 0) con umbral +Inf
+// hasta (1, 1)
+func ROC(scores, labels []float64) ([]ROCPoint, error) {
+	r, err := rank(scores, labels)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ROCPoint, 0, len(r.thresholds)+1)
+	points = append(points, ROCPoint{Threshold: math.Inf(1)})
+	for i, t := range r.thresholds {
+		points = append(points, ROCPoint{
+			Threshold: t,
+			FPR:       float64(r.fps[i]) / float64(r.neg),
+			TPR:       float64(r.tps[i]) / float64(r.pos),
+		})
+	}
+	return points, nil
+}
+
+// ROCAUC devuelve el área bajo la curva ROC (regla del trapecio)
+func ROCAUC(scores, labels []float64) (float64, error) {
+	points, err := ROC(scores, labels)
+	if err != nil {
+		return 0, err
+	}
+	area := 0.0
+	for i := 1; i < len(points); i++ {
+		area += (points[i].FPR - points[i-1].FPR) * (points[i].TPR + points[i-1].TPR) / 2
+	}
+	return area, nil
+}
+
+// PrecisionRecallCurve devuelve la curva precision-recall de las puntuaciones,
+// empezando en recall 0 con precision 1
+func PrecisionRecallCurve(scores, labels []float64) ([]PRPoint, error) {
+	r, err := rank(scores, labels)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]PRPoint, 0, len(r.thresholds)+1)
+	points = append(points, PRPoint{Threshold: math.Inf(1), Precision: 1})
+	for i, t := range r.thresholds {
+		points = append(points, PRPoint{
+			Threshold: t,
+			Precision: Precision(r.tps[i], r.fps[i]),
+			Recall:    float64(r.tps[i]) / float64(r.pos),
+		})
+	}
+	return points, nil
+}
+
+// AveragePrecision resume la curva precision-recall como la suma de la
+// precision en cada umbral ponderada por el aumento de recall
+func AveragePrecision(scores, labels []float64) (float64, error) {
+	points, err := PrecisionRecallCurve(scores, labels)
+	if err != nil {
+		return 0, err
+	}
+	ap := 0.0
+	for i := 1; i < len(points); i++ {
+		ap += (points[i].Recall - points[i-1].Recall) * points[i].Precision
+	}
+	return ap, nil
+}
+
+// OptimalThreshold devuelve el umbral que maximiza el criterio y el valor
+// alcanzado. Las filas con puntuación >= umbral se predicen como positivas
+func OptimalThreshold(scores, labels []float64, criterion Criterion) (float64, float64, error) {
+	r, err := rank(scores, labels)
+	if err != nil {
+		return 0, 0, err
+	}
+	best, bestValue := 0.0, math.Inf(-1)
+	for i, t := range r.thresholds {
+		var value float64
+		switch criterion {
+		case YoudenJ:
+			value = float64(r.tps[i])/float64(r.pos) - float64(r.fps[i])/float64(r.neg)
+		case BestF1:
+			value = F1Score(Precision(r.tps[i], r.fps[i]), Recall(r.tps[i], r.pos-r.tps[i]))
+		default:
+			return 0, 0, errors.New("criterio de umbral desconocido")
+		}
+		if value > bestValue {
+			best, bestValue = t, value
+		}
+	}
+	return best, bestValue, nil
+}
@@ -0,0 +1,60 @@
+package classification
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestROCAUC(t *testing.T) {
+	tests := []struct {
+		name   string
+		scores []float64
+		labels []float64
+		want   float64
+		err    error
+	}{
+		{"perfecto", []float64{0.9, 0.8, 0.2, 0.1}, []float64{1, 1, 0, 0}, 1, nil},
+		{"invertido", []float64{0.1, 0.2, 0.8, 0.9}, []float64{1, 1, 0, 0}, 0, nil},
+		{"empates", []float64{0.5, 0.5, 0.5, 0.5}, []float64{1, 0, 1, 0}, 0.5, nil},
+		{"un error", []float64{0.9, 0.7, 0.8, 0.1}, []float64{1, 1, 0, 0}, 0.75, nil},
+		{"una clase", []float64{0.9, 0.1}, []float64{1, 1}, 0, ErrSingleClass},
+		{"multiclase", []float64{0.9, 0.5, 0.1}, []float64{0, 1, 2}, 0, ErrNotBinary},
+		{"longitudes", []float64{0.9}, []float64{0, 1}, 0, ErrLengthMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ROCAUC(tt.scores, tt.labels)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, se esperaba %v", err, tt.err)
+			}
+			if err == nil && math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("ROCAUC = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAveragePrecision(t *testing.T) {
+	// Positivos en las posiciones 1 y 3 del ranking: AP = (1/1 + 2/3) / 2
+	got, err := AveragePrecision([]float64{0.9, 0.8, 0.7, 0.1}, []float64{1, 0, 1, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (1 + 2.0/3) / 2; math.Abs(got-want) > 1e-12 {
+		t.Errorf("AveragePrecision = %v, se esperaba %v", got, want)
+	}
+	if _, err := AveragePrecision([]float64{0.9, 0.5, 0.1}, []float64{0, 1, 2}); !errors.Is(err, ErrNotBinary) {
+		t.Errorf("error = %v, se esperaba ErrNotBinary", err)
+	}
+}
+
+func TestAddScoresSkipsRankingForMulticlass(t *testing.T) {
+	var r Report
+	if err := r.AddScores([]float64{0.2, 0.5, 0.9}, []float64{0, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if r.Ranked {
+		t.Error("las métricas de ranking no deben calcularse con etiquetas multiclase")
+	}
+}
@@ -0,0 +1,257 @@
+package classification
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Average indica cómo combinar las métricas de varias clases
+type Average int
+
+const (
+	Macro    Average = iota // Media simple de las métricas de cada clase
+	Micro                   // Métricas de los conteos totales de TP, FP y FN
+	Weighted                // Media de las métricas de cada clase ponderada por su soporte
+)
+
+func (a Average) String() string {
+	switch a {
+	case Macro:
+		return "macro"
+	case Micro:
+		return "micro"
+	case Weighted:
+		return "weighted"
+	}
+	return fmt.Sprintf("Average(%d)", int(a))
+}
+
+// Scores son precision, recall y F1 de una clase o de un promedio
+type Scores struct {
+	Precision float64
+	Recall    float64
+	F1        float64
+}
+
+// ClassScores son las métricas de una clase frente al resto
+type ClassScores[L cmp.Ordered] struct {
+	Label   L
+	Support int // Filas cuya clase real es Label
+	Scores
+}
+
+// Confusion es una matriz de confusión N×N sobre etiquetas arbitrarias
+type Confusion[L cmp.Ordered] struct {
+	Labels []L     // Clases ordenadas de menor a mayor
+	Counts [][]int // Counts[i][j]: filas de clase real Labels[i] predichas como Labels[j]
+}
+
+// NewConfusion cuenta cada par (real, predicha). Las clases son la unión de las
+// que aparecen en predictions y en actuals
+func NewConfusion[L cmp.Ordered](predictions, actuals []L) (*Confusion[L], error) {
+	return NewConfusionConcurrent(predictions, actuals, metricWorkers(len(actuals)))
+}
+
+// NewConfusionConcurrent calcula la matriz de NewConfusion repartiendo las filas
+// en bloques entre workers goroutines (<= 0 usa GOMAXPROCS)
+func NewConfusionConcurrent[L cmp.Ordered](predictions, actuals []L, workers int) (*Confusion[L], error) {
+	if len(predictions) != len(actuals) {
+		return nil, errors.New("la longitud de las predicciones y las etiquetas no coinciden")
+	}
+	n := len(actuals)
+
+	// Clases de cada bloque, ordenadas y sin repetir, unidas por pares
+	labels := MapReduce(n, metricChunk, workers, func(lo, hi int) []L {
+		block := slices.Concat(predictions[lo:hi], actuals[lo:hi])
+		slices.Sort(block)
+		return slices.Compact(block)
+	}, func(a, b []L) []L {
+		merged := slices.Concat(a, b)
+		slices.Sort(merged)
+		return slices.Compact(merged)
+	})
+
+	c := &Confusion[L]{Labels: labels}
+	c.Counts = MapReduce(n, metricChunk, workers, func(lo, hi int) [][]int {
+		counts := newCounts(len(labels))
+		for i := lo; i < hi; i++ {
+			counts[c.Index(actuals[i])][c.Index(predictions[i])]++
+		}
+		return counts
+	}, func(a, b [][]int) [][]int {
+		for i := range a {
+			for j := range a[i] {
+				a[i][j] += b[i][j]
+			}
+		}
+		return a
+	})
+	return c, nil
+}
+
+// Matriz cuadrada de conteos a cero
+func newCounts(k int) [][]int {
+	counts := make([][]int, k)
+	for i := range counts {
+		counts[i] = make([]int, k)
+	}
+	return counts
+}
+
+// Index devuelve la posición de la clase en Labels, o -1 si no aparece
+func (c *Confusion[L]) Index(label L) int {
+	i, ok := slices.BinarySearch(c.Labels, label)
+	if !ok {
+		return -1
+	}
+	return i
+}
+
+// Total devuelve el número de filas contadas
+func (c *Confusion[L]) Total() int {
+	total := 0
+	for _, row := range c.Counts {
+		for _, n := range row {
+			total += n
+		}
+	}
+	return total
+}
+
+// Accuracy devuelve la fracción de filas en la diagonal
+func (c *Confusion[L]) Accuracy() float64 {
+	total := c.Total()
+	if total == 0 {
+		return 0
+	}
+	correct := 0
+	for i := range c.Counts {
+		correct += c.Counts[i][i]
+	}
+	return float64(correct) / float64(total)
+}
+
+// Conteos uno contra el resto de la clase i
+func (c *Confusion[L]) oneVsRest(i int) (tp, fp, fn int) {
+	tp = c.Counts[i][i]
+	for j := range c.Counts {
+		if j != i {
+			fp += c.Counts[j][i]
+			fn += c.Counts[i][j]
+		}
+	}
+	return tp, fp, fn
+}
+
+// PerClass devuelve precision, recall, F1 y soporte de cada clase, en el orden de Labels
+func (c *Confusion[L]) PerClass() []ClassScores[L] {
+	classes := make([]ClassScores[L], len(c.Labels))
+	for i, label := range c.Labels {
+		tp, fp, fn := c.oneVsRest(i)
+		p, r := Precision(tp, fp), Recall(tp, fn)
+		classes[i] = ClassScores[L]{Label: label, Support: tp + fn, Scores: Scores{p, r, F1Score(p, r)}}
+	}
+	return classes
+}
+
+// Average combina las métricas de todas las clases
+func (c *Confusion[L]) Average(avg Average) Scores {
+	if avg == Micro {
+		var tp, fp, fn int
+		for i := range c.Labels {
+			t, p, n := c.oneVsRest(i)
+			tp, fp, fn = tp+t, fp+p, fn+n
+		}
+		p, r := Precision(tp, fp), Recall(tp, fn)
+		return Scores{p, r, F1Score(p, r)}
+	}
+
+	var sum Scores
+	weight := 0.0
+	for _, class := range c.PerClass() {
+		w := 1.0
+		if avg == Weighted {
+			w = float64(class.Support)
+		}
+		sum.Precision += w * class.Precision
+		sum.Recall += w * class.Recall
+		sum.F1 += w * class.F1
+		weight += w
+	}
+	if weight == 0 {
+		return Scores{}
+	}
+	return Scores{sum.Precision / weight, sum.Recall / weight, sum.F1 / weight}
+}
+
+// WriteTable escribe la matriz (filas: clase real, columnas: clase predicha)
+// seguida de las métricas por clase y sus promedios
+func (c *Confusion[L]) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprint(tw, "real \\ predicha\t")
+	for _, label := range c.Labels {
+		fmt.Fprintf(tw, "%v\t", label)
+	}
+	fmt.Fprintln(tw)
+	for i, label := range c.Labels {
+		fmt.Fprintf(tw, "%v\t", label)
+		for _, n := range c.Counts[i] {
+			fmt.Fprintf(tw, "%d\t", n)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "clase\tprecision\trecall\tf1\tsoporte\t")
+	for _, class := range c.PerClass() {
+		fmt.Fprintf(tw, "%v\t%.4f\t%.4f\t%.4f\t%d\t\n", class.Label, class.Precision, class.Recall, class.F1, class.Support)
+	}
+	fmt.Fprintln(tw)
+	for _, avg := range []Average{Macro, Micro, Weighted} {
+		s := c.Average(avg)
+		fmt.Fprintf(tw, "%s\t%.4f\t%.4f\t%.4f\t%d\t\n", avg, s.Precision, s.Recall, s.F1, c.Total())
+	}
+	fmt.Fprintf(tw, "accuracy\t\t\t%.4f\t%d\t\n", c.Accuracy(), c.Total())
+	return tw.Flush()
+}
+
+// String devuelve la tabla de WriteTable
+func (c *Confusion[L]) String() string {
+	var b strings.Builder
+	c.WriteTable(&b)
+	return b.String()
+}
+
+// Relabel traduce una matriz de clases enteras a sus nombres, p. ej. con
+// Dataset.Classes. Las clases sin nombre conservan su número
+func Relabel(c *Confusion[int], names []string) *Confusion[string] {
+	name := func(label int) string {
+		if label >= 0 && label < len(names) {
+			return names[label]
+		}
+		return fmt.Sprint(label)
+	}
+
+	// El orden de los nombres puede no coincidir con el de los números
+	order := make([]int, len(c.Labels))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return cmp.Compare(name(c.Labels[a]), name(c.Labels[b])) })
+
+	out := &Confusion[string]{Labels: make([]string, len(order)), Counts: make([][]int, len(order))}
+	for i, a := range order {
+		out.Labels[i] = name(c.Labels[a])
+		out.Counts[i] = make([]int, len(order))
+		for j, b := range order {
+			out.Counts[i][j] = c.Counts[a][b]
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package classification
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestConfusionCountsAndAverages(t *testing.T) {
+	// Reales: 0 0 0 1 1 2; la clase 2 solo aparece en las reales
+	actuals := []int{0, 0, 0, 1, 1, 2}
+	predictions := []int{0, 0, 1, 1, 0, 1}
+	c, err := NewConfusion(predictions, actuals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(c.Labels, []int{0, 1, 2}) {
+		t.Fatalf("clases = %v", c.Labels)
+	}
+	want := [][]int{{2, 1, 0}, {1, 1, 0}, {0, 1, 0}}
+	for i := range want {
+		if !slices.Equal(c.Counts[i], want[i]) {
+			t.Errorf("fila %d = %v, se esperaba %v", i, c.Counts[i], want[i])
+		}
+	}
+	if c.Total() != 6 || c.Accuracy() != 0.5 {
+		t.Errorf("Total, Accuracy = %d, %v; se esperaba 6, 0.5", c.Total(), c.Accuracy())
+	}
+
+	// Con una etiqueta por fila, el promedio micro coincide con la accuracy
+	if micro := c.Average(Micro); micro.Precision != 0.5 || micro.Recall != 0.5 {
+		t.Errorf("micro = %+v, se esperaba 0.5", micro)
+	}
+	// Recall por clase: 2/3, 1/2 y 0, con soporte 3, 2 y 1
+	if got, want := c.Average(Macro).Recall, (2.0/3+0.5+0)/3; math.Abs(got-want) > 1e-12 {
+		t.Errorf("recall macro = %v, se esperaba %v", got, want)
+	}
+	if got, want := c.Average(Weighted).Recall, (3*2.0/3+2*0.5)/6; math.Abs(got-want) > 1e-12 {
+		t.Errorf("recall ponderado = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestConfusionConcurrentMatchesSequential(t *testing.T) {
+	predictions := make([]string, 1000)
+	actuals := make([]string, 1000)
+	names := []string{"no", "quizá", "sí"}
+	for i := range actuals {
+		actuals[i] = names[i%3]
+		predictions[i] = names[(i*7)%3]
+	}
+	sequential, err := NewConfusionConcurrent(predictions, actuals, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	concurrent, err := NewConfusionConcurrent(predictions, actuals, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(sequential.Labels, concurrent.Labels) {
+		t.Fatalf("clases %v y %v", sequential.Labels, concurrent.Labels)
+	}
+	for i := range sequential.Counts {
+		if !slices.Equal(sequential.Counts[i], concurrent.Counts[i]) {
+			t.Errorf("fila %d: %v y %v", i, sequential.Counts[i], concurrent.Counts[i])
+		}
+	}
+}
+
+func TestConfusionLengthMismatch(t *testing.T) {
+	if _, err := NewConfusion([]int{0, 1}, []int{0}); err == nil {
+		t.Error("se esperaba un error con longitudes distintas")
+	}
+}
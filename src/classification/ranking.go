@@ -0,0 +1,200 @@
+package classification
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// RankingEvaluator mide la calidad de listas de recomendaciones frente a las
+// interacciones reservadas de cada usuario. Los usuarios se reparten entre
+// Workers goroutines
+type RankingEvaluator struct {
+	K          int         // Posiciones evaluadas de cada lista; 0 usa 10
+	Workers    int         // Goroutines; 0 usa GOMAXPROCS
+	Catalog    int         // Ítems del catálogo para la cobertura; 0 la omite
+	Popularity map[int]int // Usuarios de entrenamiento que interactuaron con cada ítem; nil omite la novedad
+	Users      int         // Usuarios de entrenamiento, para normalizar Popularity
+}
+
+// RankingReport reúne las métricas de ranking promediadas sobre los usuarios
+type RankingReport struct {
+	K         int
+	Users     int     // Usuarios evaluados: los que tienen algún ítem relevante
+	Precision float64 // precision@K
+	Recall    float64 // recall@K
+	MAP       float64 // MAP@K
+	NDCG      float64 // NDCG@K con relevancia binaria
+	MRR       float64 // Rango recíproco medio del primer acierto de la lista completa
+	HitRate   float64 // Fracción de usuarios con algún acierto entre los K primeros
+	Coverage  float64 // Fracción del catálogo recomendada a algún usuario; NaN si no hay Catalog
+	Novelty   float64 // Autoinformación media (bits) de los ítems recomendados; NaN sin Popularity
+}
+
+// Métricas de un usuario
+type userRanking struct {
+	precision, recall, ap, ndcg, rr, hit float64
+}
+
+// Evaluate calcula las métricas. recommended tiene la lista de cada usuario
+// ordenada de más a menos recomendada y relevant los ítems reservados con los
+// que interactuó
+func (e RankingEvaluator) Evaluate(recommended, relevant map[int][]int) (RankingReport, error) {
+	k, workers := e.K, e.Workers
+	if k == 0 {
+		k = 10
+	}
+	if k < 0 {
+		return RankingReport{}, errors.New("k debe ser positivo")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var users []int
+	for user, items := range relevant {
+		if len(items) > 0 {
+			users = append(users, user)
+		}
+	}
+	if len(users) == 0 {
+		return RankingReport{}, errors.New("ningún usuario tiene ítems relevantes")
+	}
+	slices.Sort(users)
+
+	// Cada usuario se calcula en su posición; la suma final sigue el orden de users
+	results := make([]userRanking, len(users))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = rankUser(recommended[users[i]], relevant[users[i]], k)
+			}
+		}()
+	}
+	for i := range users {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := RankingReport{K: k, Users: len(users), Coverage: math.NaN(), Novelty: math.NaN()}
+	for _, r := range results {
+		report.Precision += r.precision
+		report.Recall += r.recall
+		report.MAP += r.ap
+		report.NDCG += r.ndcg
+		report.MRR += r.rr
+		report.HitRate += r.hit
+	}
+	n := float64(len(users))
+	report.Precision /= n
+	report.Recall /= n
+	report.MAP /= n
+	report.NDCG /= n
+	report.MRR /= n
+	report.HitRate /= n
+
+	if e.Catalog > 0 {
+		report.Coverage = Coverage(recommended, k, e.Catalog)
+	}
+	if e.Popularity != nil && e.Users > 0 {
+		report.Novelty = Novelty(recommended, k, e.Popularity, e.Users)
+	}
+	return report, nil
+}
+
+// Métricas de la lista de un usuario con al menos un ítem relevante
+func rankUser(list, relevant []int, k int) userRanking {
+	isRelevant := make(map[int]bool, len(relevant))
+	for _, item := range relevant {
+		isRelevant[item] = true
+	}
+
+	var r userRanking
+	hits := 0
+	dcg, idcg := 0.0, 0.0
+	for i, item := range list {
+		if !isRelevant[item] {
+			continue
+		}
+		if r.rr == 0 {
+			r.rr = 1 / float64(i+1)
+		}
+		if i < k {
+			hits++
+			r.ap += float64(hits) / float64(i+1)
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+	for i := 0; i < min(k, len(isRelevant)); i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+
+	r.precision = float64(hits) / float64(k)
+	r.recall = float64(hits) / float64(len(isRelevant))
+	r.ap /= float64(min(k, len(isRelevant)))
+	r.ndcg = dcg / idcg
+	if hits > 0 {
+		r.hit = 1
+	}
+	return r
+}
+
+// Coverage devuelve la fracción de los catalog ítems que aparece entre los k
+// primeros de alguna lista
+func Coverage(recommended map[int][]int, k, catalog int) float64 {
+	seen := make(map[int]bool)
+	for _, list := range recommended {
+		for _, item := range list[:min(k, len(list))] {
+			seen[item] = true
+		}
+	}
+	return float64(len(seen)) / float64(catalog)
+}
+
+// Novelty devuelve la autoinformación media -log2(popularidad) de los k
+// primeros ítems de cada lista. popularity cuenta los usuarios de
+// entrenamiento (de users) que interactuaron con cada ítem; un ítem sin
+// interacciones cuenta como visto por uno. Las listas se suman en el orden de
+// los usuarios para que el resultado sea reproducible
+func Novelty(recommended map[int][]int, k int, popularity map[int]int, users int) float64 {
+	sum, n := 0.0, 0
+	for _, user := range slices.Sorted(maps.Keys(recommended)) {
+		list := recommended[user]
+		for _, item := range list[:min(k, len(list))] {
+			sum -= math.Log2(float64(max(popularity[item], 1)) / float64(users))
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// TopK devuelve los k ítems de mayor puntuación, omitiendo los de exclude
+// (p. ej. los ya vistos en el entrenamiento). Los empates se resuelven por el
+// ítem menor
+func TopK(scores map[int]float64, k int, exclude map[int]bool) []int {
+	items := make([]int, 0, len(scores))
+	for item := range scores {
+		if !exclude[item] {
+			items = append(items, item)
+		}
+	}
+	slices.SortFunc(items, func(a, b int) int {
+		if c := cmp.Compare(scores[b], scores[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return items[:min(k, len(items))]
+}
@@ -0,0 +1,108 @@
+package classification
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestRankingEvaluator(t *testing.T) {
+	recommended := map[int][]int{
+		1: {1, 2, 3, 4}, // Acierta en la posición 2; el 4 queda fuera de K
+		2: {5, 6},       // Acierta en la posición 1
+		3: {1},          // Sin ítems relevantes: no se evalúa
+		4: {7, 8},       // Sin aciertos
+	}
+	relevant := map[int][]int{1: {2, 4}, 2: {5}, 3: {}, 4: {9}}
+
+	got, err := RankingEvaluator{K: 2, Workers: 1, Catalog: 10}.Evaluate(recommended, relevant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dcg := 1 / math.Log2(3)
+	want := RankingReport{
+		K:         2,
+		Users:     3,
+		Precision: (0.5 + 0.5 + 0) / 3,
+		Recall:    (0.5 + 1 + 0) / 3,
+		MAP:       (0.25 + 1 + 0) / 3,
+		NDCG:      (dcg/(1+dcg) + 1 + 0) / 3,
+		MRR:       (0.5 + 1 + 0) / 3,
+		HitRate:   2.0 / 3,
+		Coverage:  6.0 / 10,
+	}
+	metrics := []struct {
+		name      string
+		got, want float64
+	}{
+		{"precision", got.Precision, want.Precision},
+		{"recall", got.Recall, want.Recall},
+		{"MAP", got.MAP, want.MAP},
+		{"NDCG", got.NDCG, want.NDCG},
+		{"MRR", got.MRR, want.MRR},
+		{"hit rate", got.HitRate, want.HitRate},
+		{"coverage", got.Coverage, want.Coverage},
+	}
+	if got.K != want.K || got.Users != want.Users {
+		t.Errorf("K, Users = %d, %d; se esperaba %d, %d", got.K, got.Users, want.K, want.Users)
+	}
+	for _, m := range metrics {
+		if math.Abs(m.got-m.want) > 1e-12 {
+			t.Errorf("%s = %v, se esperaba %v", m.name, m.got, m.want)
+		}
+	}
+	if !math.IsNaN(got.Novelty) {
+		t.Errorf("novelty = %v, se esperaba NaN sin Popularity", got.Novelty)
+	}
+
+}
+
+func TestRankingEvaluatorWorkers(t *testing.T) {
+	recommended := make(map[int][]int)
+	relevant := make(map[int][]int)
+	for user := range 50 {
+		recommended[user] = []int{user % 7, user % 11, user % 13}
+		relevant[user] = []int{user % 5, user % 11}
+	}
+	evaluate := func(workers int) RankingReport {
+		e := RankingEvaluator{K: 3, Workers: workers, Catalog: 13, Popularity: map[int]int{0: 5, 1: 2}, Users: 50}
+		report, err := e.Evaluate(recommended, relevant)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+	// Las métricas se suman en el orden de los usuarios: el resultado no
+	// depende del número de goroutines
+	if one, many := evaluate(1), evaluate(8); one != many {
+		t.Errorf("con 8 workers %+v, con 1 %+v", many, one)
+	}
+}
+
+func TestRankingEvaluatorErrors(t *testing.T) {
+	if _, err := (RankingEvaluator{K: -1}).Evaluate(nil, map[int][]int{1: {1}}); err == nil {
+		t.Error("K negativo debe dar error")
+	}
+	if _, err := (RankingEvaluator{}).Evaluate(nil, map[int][]int{1: {}}); err == nil {
+		t.Error("sin usuarios con ítems relevantes debe dar error")
+	}
+}
+
+func TestNovelty(t *testing.T) {
+	// Ítem 1 visto por la mitad de 8 usuarios (1 bit) e ítem 2 por ninguno (cuenta como 1: 3 bits)
+	got := Novelty(map[int][]int{1: {1, 2, 3}}, 2, map[int]int{1: 4}, 8)
+	if want := (1 + 3) / 2.0; math.Abs(got-want) > 1e-12 {
+		t.Errorf("Novelty = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	scores := map[int]float64{1: 0.5, 2: 0.9, 3: 0.5, 4: 0.7, 5: 1}
+	got := TopK(scores, 3, map[int]bool{5: true})
+	if want := []int{2, 4, 1}; !slices.Equal(got, want) {
+		t.Errorf("TopK = %v, se esperaba %v", got, want)
+	}
+	if got := TopK(scores, 10, nil); len(got) != len(scores) {
+		t.Errorf("TopK con k mayor que los ítems devolvió %d ítems", len(got))
+	}
+}
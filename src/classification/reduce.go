@@ -0,0 +1,101 @@
+package classification
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers es el número de goroutines con que las métricas recorren vectores de
+// al menos ParallelMin elementos; 0 usa GOMAXPROCS
+var Workers = 0
+
+// ParallelMin es el tamaño a partir del cual las métricas se calculan en
+// paralelo. Por debajo el costo de lanzar goroutines supera la ganancia
+var ParallelMin = 1 << 16
+
+// Elementos por bloque con que las métricas recorren sus vectores
+const metricChunk = 1 << 14
+
+// MapReduce divide [0, n) en bloques contiguos de chunk elementos (el último
+// puede ser menor), reparte los bloques entre workers goroutines y combina
+// los resultados con reduce en el orden de los bloques. Los bloques dependen
+// solo de n y chunk, no de workers, así que el resultado es el mismo con
+// cualquier número de goroutines. workers <= 0 usa GOMAXPROCS
+func MapReduce[T any](n, chunk, workers int, mapper func(lo, hi int) T, reduce func(a, b T) T) T {
+	chunk = max(chunk, 1)
+	partial := make([]T, max((n+chunk-1)/chunk, 1))
+	run := func(c int) {
+		lo := c * chunk
+		partial[c] = mapper(lo, min(lo+chunk, n))
+	}
+
+	workers = min(resolveWorkers(workers), len(partial))
+	if workers == 1 {
+		for c := range partial {
+			run(c)
+		}
+	} else {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range jobs {
+					run(c)
+				}
+			}()
+		}
+		for c := range partial {
+			jobs <- c
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	result := partial[0]
+	for _, p := range partial[1:] {
+		result = reduce(result, p)
+	}
+	return result
+}
+
+// Un número de workers <= 0 significa GOMAXPROCS
+func resolveWorkers(workers int) int {
+	if workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// Workers efectivos de una métrica sobre n elementos: 1 por debajo de ParallelMin
+func metricWorkers(n int) int {
+	if n < ParallelMin {
+		return 1
+	}
+	return resolveWorkers(Workers)
+}
+
+// Suma y cuenta parciales de una métrica elemento a elemento
+type partialSum struct {
+	sum float64
+	n   int
+}
+
+func addSums(a, b partialSum) partialSum {
+	return partialSum{a.sum + b.sum, a.n + b.n}
+}
+
+// Suma term(i) para i en [0, n); term devuelve false para omitir el elemento
+func sumOver(n int, term func(i int) (float64, bool)) partialSum {
+	return MapReduce(n, metricChunk, metricWorkers(n), func(lo, hi int) partialSum {
+		var p partialSum
+		for i := lo; i < hi; i++ {
+			if v, ok := term(i); ok {
+				p.sum += v
+				p.n++
+			}
+		}
+		return p
+	}, addSums)
+}
@@ -0,0 +1,87 @@
+package classification
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestMapReduceCombinesBlocksInOrder(t *testing.T) {
+	// La concatenación no es conmutativa: cualquier desorden cambia el resultado
+	for _, workers := range []int{1, 3, 7, 100} {
+		got := MapReduce(10, 2, workers, func(lo, hi int) []int {
+			block := make([]int, 0, hi-lo)
+			for i := lo; i < hi; i++ {
+				block = append(block, i)
+			}
+			return block
+		}, func(a, b []int) []int { return append(a, b...) })
+		if want := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}; !slices.Equal(got, want) {
+			t.Errorf("workers = %d: %v, se esperaba %v", workers, got, want)
+		}
+	}
+}
+
+func TestMapReduceBlocksDoNotDependOnWorkers(t *testing.T) {
+	for _, workers := range []int{1, 2, 4, 16} {
+		blocks := MapReduce(10, 3, workers, func(lo, hi int) []string {
+			return []string{fmt.Sprintf("[%d,%d)", lo, hi)}
+		}, func(a, b []string) []string { return append(a, b...) })
+		if want := []string{"[0,3)", "[3,6)", "[6,9)", "[9,10)"}; !slices.Equal(blocks, want) {
+			t.Errorf("workers = %d: bloques = %v, se esperaba %v", workers, blocks, want)
+		}
+	}
+	if got := MapReduce(0, 3, 4, func(lo, hi int) int { return hi - lo + 1 }, func(a, b int) int { return a + b }); got != 1 {
+		t.Errorf("sin elementos mapper debe llamarse una vez con [0, 0): %d", got)
+	}
+}
+
+func TestMapReduceSumIsExactAcrossWorkers(t *testing.T) {
+	// Sumas en coma flotante que cambian con el orden de los sumandos
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = 1 / float64(i+1)
+	}
+	sum := func(workers int) float64 {
+		return MapReduce(len(values), 7, workers, func(lo, hi int) float64 {
+			s := 0.0
+			for _, v := range values[lo:hi] {
+				s += v
+			}
+			return s
+		}, func(a, b float64) float64 { return a + b })
+	}
+	for _, workers := range []int{2, 3, 8} {
+		if one, many := sum(1), sum(workers); one != many {
+			t.Errorf("workers = %d: %v, con 1 worker %v", workers, many, one)
+		}
+	}
+}
+
+func TestMetricsDoNotDependOnParallelism(t *testing.T) {
+	defer func(min, workers int) { ParallelMin, Workers = min, workers }(ParallelMin, Workers)
+
+	predictions := make([]float64, 5000)
+	actuals := make([]float64, 5000)
+	for i := range actuals {
+		actuals[i] = float64(i%17) / 3
+		predictions[i] = actuals[i] + float64(i%5)/10 - 0.2
+	}
+	ParallelMin = 1 << 30
+	sequential := RegressionReport(predictions, actuals)
+	ParallelMin, Workers = 1, 8
+	parallel := RegressionReport(predictions, actuals)
+	parallelAgain := RegressionReport(predictions, actuals)
+	if parallel.MSE != parallelAgain.MSE || parallel.MAE != parallelAgain.MAE || parallel.R2 != parallelAgain.R2 {
+		t.Errorf("dos cálculos con 8 workers difieren:\n%v\n%v", parallel, parallelAgain)
+	}
+	for name, pair := range map[string][2]float64{
+		"MSE": {sequential.MSE, parallel.MSE},
+		"MAE": {sequential.MAE, parallel.MAE},
+		"R2":  {sequential.R2, parallel.R2},
+	} {
+		if diff := pair[0] - pair[1]; diff > 1e-12 || diff < -1e-12 {
+			t.Errorf("%s: secuencial %v, paralelo %v", name, pair[0], pair[1])
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package classification
+
+import (
+	"math"
+	"slices"
+)
+
+// Delta con el que Report calcula la pérdida de Huber
+const HuberDelta = 1.0
+
+// ResidualSummary describe la distribución de los residuos (real - predicho)
+type ResidualSummary struct {
+	Mean   float64
+	Std    float64
+	Min    float64
+	Q1     float64
+	Median float64
+	Q3     float64
+	Max    float64
+}
+
+// Residuos real - predicho
+func residuals(predictions, actuals []float64) []float64 {
+	res := make([]float64, len(actuals))
+	for i := range actuals {
+		res[i] = actuals[i] - predictions[i]
+	}
+	return res
+}
+
+// Media y desviación estándar poblacional
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	n := float64(len(values))
+	mean := sumOver(len(values), func(i int) (float64, bool) { return values[i], true }).sum / n
+	variance := sumOver(len(values), func(i int) (float64, bool) {
+		return (values[i] - mean) * (values[i] - mean), true
+	}).sum / n
+	return mean, math.Sqrt(variance)
+}
+
+// Cuantil q de valores ordenados, interpolando linealmente entre posiciones
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// MAE calcula el error absoluto medio
+func MAE(predictions, actuals []float64) float64 {
+	if len(actuals) == 0 {
+		return 0
+	}
+	p := sumOver(len(actuals), func(i int) (float64, bool) {
+		return math.Abs(actuals[i] - predictions[i]), true
+	})
+	return p.sum / float64(len(actuals))
+}
+
+// MAPE calcula el error porcentual absoluto medio (en tanto por uno). Las filas
+// con valor real 0 se omiten; si no queda ninguna devuelve NaN
+func MAPE(predictions, actuals []float64) float64 {
+	p := sumOver(len(actuals), func(i int) (float64, bool) {
+		return math.Abs((actuals[i] - predictions[i]) / actuals[i]), actuals[i] != 0
+	})
+	if p.n == 0 {
+		return math.NaN()
+	}
+	return p.sum / float64(p.n)
+}
+
+// MedianAE calcula la mediana del error absoluto, poco sensible a valores atípicos
+func MedianAE(predictions, actuals []float64) float64 {
+	if len(actuals) == 0 {
+		return 0
+	}
+	errs := make([]float64, len(actuals))
+	for i := range actuals {
+		errs[i] = math.Abs(actuals[i] - predictions[i])
+	}
+	slices.Sort(errs)
+	return quantile(errs, 0.5)
+}
+
+// R2 calcula el coeficiente de determinación: 1 - SSres / SStot. Con un
+// objetivo constante devuelve 1 si las predicciones son exactas y 0 si no
+func R2(predictions, actuals []float64) float64 {
+	mean, _ := meanStd(actuals)
+	ssRes := sumOver(len(actuals), func(i int) (float64, bool) {
+		return (actuals[i] - predictions[i]) * (actuals[i] - predictions[i]), true
+	}).sum
+	ssTot := sumOver(len(actuals), func(i int) (float64, bool) {
+		return (actuals[i] - mean) * (actuals[i] - mean), true
+	}).sum
+	if ssTot == 0 {
+		if ssRes == 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1 - ssRes/ssTot
+}
+
+// AdjustedR2 corrige r2 por el número de características p usadas con n
+// filas. Devuelve NaN si n <= p + 1
+func AdjustedR2(r2 float64, n, p int) float64 {
+	if n <= p+1 {
+		return math.NaN()
+	}
+	return 1 - (1-r2)*float64(n-1)/float64(n-p-1)
+}
+
+// ExplainedVariance calcula 1 - Var(residuos) / Var(real). A diferencia de R2
+// no penaliza un sesgo constante de las predicciones
+func ExplainedVariance(predictions, actuals []float64) float64 {
+	_, stdRes := meanStd(residuals(predictions, actuals))
+	_, stdAct := meanStd(actuals)
+	if stdAct == 0 {
+		if stdRes == 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1 - (stdRes*stdRes)/(stdAct*stdAct)
+}
+
+// HuberLoss calcula la pérdida de Huber media: cuadrática para errores
+// menores que delta y lineal para los mayores
+func HuberLoss(predictions, actuals []float64, delta float64) float64 {
+	if len(actuals) == 0 {
+		return 0
+	}
+	p := sumOver(len(actuals), func(i int) (float64, bool) {
+		e := math.Abs(actuals[i] - predictions[i])
+		if e <= delta {
+			return 0.5 * e * e, true
+		}
+		return delta * (e - 0.5*delta), true
+	})
+	return p.sum / float64(len(actuals))
+}
+
+// Residuals resume la distribución de los residuos real - predicho
+func Residuals(predictions, actuals []float64) ResidualSummary {
+	res := residuals(predictions, actuals)
+	if len(res) == 0 {
+		return ResidualSummary{}
+	}
+	mean, std := meanStd(res)
+	slices.Sort(res)
+	return ResidualSummary{
+		Mean:   mean,
+		Std:    std,
+		Min:    res[0],
+		Q1:     quantile(res, 0.25),
+		Median: quantile(res, 0.5),
+		Q3:     quantile(res, 0.75),
+		Max:    res[len(res)-1],
+	}
+}
@@ -0,0 +1,61 @@
+package classification
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRegressionMetrics(t *testing.T) {
+	actuals := []float64{1, 2, 3, 4}
+	predictions := []float64{1.5, 2, 2, 4}
+	// Residuos: -0.5, 0, 1, 0
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"MSE", MSE(predictions, actuals), 1.25 / 4},
+		{"RMSE", RMSE(predictions, actuals), math.Sqrt(1.25 / 4)},
+		{"MAE", MAE(predictions, actuals), 1.5 / 4},
+		{"MAPE", MAPE(predictions, actuals), (0.5 + 0 + 1.0/3 + 0) / 4},
+		{"MedianAE", MedianAE(predictions, actuals), 0.25},
+		{"R2", R2(predictions, actuals), 1 - 1.25/5},
+		{"ExplainedVariance", ExplainedVariance(predictions, actuals), 1 - (1.25/4-0.125*0.125)/1.25},
+		{"Huber", HuberLoss(predictions, actuals, 1), (0.125 + 0 + 0.5 + 0) / 4},
+		{"AdjustedR2", AdjustedR2(0.75, 10, 2), 1 - 0.25*9/7},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-12 {
+			t.Errorf("%s = %v, se esperaba %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestRegressionEdgeCases(t *testing.T) {
+	if got := MAPE([]float64{1, 2}, []float64{0, 0}); !math.IsNaN(got) {
+		t.Errorf("MAPE sin valores reales distintos de 0 = %v, se esperaba NaN", got)
+	}
+	if got := R2([]float64{3, 3}, []float64{3, 3}); got != 1 {
+		t.Errorf("R2 exacto con objetivo constante = %v, se esperaba 1", got)
+	}
+	if got := R2([]float64{2, 4}, []float64{3, 3}); got != 0 {
+		t.Errorf("R2 con objetivo constante = %v, se esperaba 0", got)
+	}
+	if got := AdjustedR2(0.9, 3, 2); !math.IsNaN(got) {
+		t.Errorf("AdjustedR2 con n <= p + 1 = %v, se esperaba NaN", got)
+	}
+	// Un sesgo constante no cambia la varianza explicada, pero sí R2
+	if got := ExplainedVariance([]float64{2, 3, 4}, []float64{1, 2, 3}); got != 1 {
+		t.Errorf("ExplainedVariance con sesgo constante = %v, se esperaba 1", got)
+	}
+}
+
+func TestResiduals(t *testing.T) {
+	r := Residuals([]float64{0, 0, 0, 0, 0}, []float64{1, 2, 3, 4, 5})
+	if r.Min != 1 || r.Q1 != 2 || r.Median != 3 || r.Q3 != 4 || r.Max != 5 || r.Mean != 3 {
+		t.Errorf("Residuals = %+v", r)
+	}
+	if want := math.Sqrt(2); math.Abs(r.Std-want) > 1e-12 {
+		t.Errorf("Std = %v, se esperaba %v", r.Std, want)
+	}
+}
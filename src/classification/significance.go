@@ -0,0 +1,320 @@
+package classification
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// TestResult es el resultado de una prueba de significancia bilateral
+type TestResult struct {
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"p_value"`
+}
+
+// Con menos pares distintos de cero Wilcoxon usa la distribución exacta
+const wilcoxonExactMax = 25
+
+// McNemar compara dos clasificadores evaluados sobre las mismas filas a partir
+// de las filas en que solo uno de los dos acierta. El estadístico es chi
+// cuadrado con corrección de continuidad y 1 grado de libertad
+func McNemar(predictionsA, predictionsB, actuals []float64) (TestResult, error) {
+	if len(predictionsA) != len(actuals) || len(predictionsB) != len(actuals) {
+		return TestResult{}, errors.New("la longitud de las predicciones y las etiquetas no coinciden")
+	}
+	onlyA, onlyB := 0, 0
+	for i, actual := range actuals {
+		okA, okB := predictionsA[i] == actual, predictionsB[i] == actual
+		if okA && !okB {
+			onlyA++
+		} else if okB && !okA {
+			onlyB++
+		}
+	}
+	if onlyA+onlyB == 0 {
+		return TestResult{Statistic: 0, PValue: 1}, nil
+	}
+	d := math.Max(math.Abs(float64(onlyA-onlyB))-1, 0)
+	stat := d * d / float64(onlyA+onlyB)
+	return TestResult{Statistic: stat, PValue: chiSquare1PValue(stat)}, nil
+}
+
+// CorrectedResampledTTest compara dos modelos con los valores de una métrica en
+// los mismos folds (t de Student pareada con la corrección de Nadeau y Bengio).
+// testTrainRatio es filas de prueba / filas de entrenamiento de cada fold, p.
+// ej. 1/(k-1) en k-fold; corrige la dependencia entre folds que comparten filas
+func CorrectedResampledTTest(scoresA, scoresB []float64, testTrainRatio float64) (TestResult, error) {
+	if len(scoresA) != len(scoresB) {
+		return TestResult{}, errors.New("los dos modelos deben evaluarse en los mismos folds")
+	}
+	if len(scoresA) < 2 {
+		return TestResult{}, errors.New("se necesitan al menos dos folds")
+	}
+	diffs := make([]float64, len(scoresA))
+	for i := range diffs {
+		diffs[i] = scoresA[i] - scoresB[i]
+	}
+	mean, std := meanStd(diffs)
+	k := float64(len(diffs))
+	variance := std * std * k / (k - 1)
+	if variance == 0 {
+		if mean == 0 {
+			return TestResult{Statistic: 0, PValue: 1}, nil
+		}
+		return TestResult{Statistic: math.Copysign(math.Inf(1), mean), PValue: 0}, nil
+	}
+	t := mean / math.Sqrt((1/k+testTrainRatio)*variance)
+	return TestResult{Statistic: t, PValue: studentTPValue(t, k-1)}, nil
+}
+
+// CompareCV aplica CorrectedResampledTTest a una métrica de dos validaciones
+// cruzadas. Ambas deben usar los mismos folds (igual K, Repeats y Seed)
+func CompareCV(a, b *CVResult, metric string) (TestResult, error) {
+	if a.Folds != b.Folds || a.K != b.K {
+		return TestResult{}, errors.New("las validaciones cruzadas no usan los mismos folds")
+	}
+	ma, okA := a.Metrics[metric]
+	mb, okB := b.Metrics[metric]
+	if !okA || !okB {
+		return TestResult{}, fmt.Errorf("la métrica %q no está en ambas validaciones cruzadas", metric)
+	}
+	return CorrectedResampledTTest(ma.Values, mb.Values, 1/float64(a.K-1))
+}
+
+// WilcoxonSignedRank compara dos muestras pareadas sin suponer normalidad. El
+// estadístico es la menor de las sumas de rangos positivos y negativos; las
+// diferencias nulas se descartan. Con menos de 25 pares y sin empates el
+// p-valor es exacto, si no se usa la aproximación normal
+func WilcoxonSignedRank(a, b []float64) (TestResult, error) {
+	if len(a) != len(b) {
+		return TestResult{}, errors.New("las muestras pareadas deben tener la misma longitud")
+	}
+	var diffs []float64
+	for i := range a {
+		if d := a[i] - b[i]; d != 0 {
+			diffs = append(diffs, d)
+		}
+	}
+	n := len(diffs)
+	if n == 0 {
+		return TestResult{Statistic: 0, PValue: 1}, nil
+	}
+
+	abs := make([]float64, n)
+	for i, d := range diffs {
+		abs[i] = math.Abs(d)
+	}
+	ranks, ties := midranks(abs)
+	plus, minus := 0.0, 0.0
+	for i, d := range diffs {
+		if d > 0 {
+			plus += ranks[i]
+		} else {
+			minus += ranks[i]
+		}
+	}
+	w := math.Min(plus, minus)
+
+	if n < wilcoxonExactMax && ties == 0 {
+		return TestResult{Statistic: w, PValue: wilcoxonExact(int(w), n)}, nil
+	}
+	nf := float64(n)
+	mean := nf * (nf + 1) / 4
+	variance := nf*(nf+1)*(2*nf+1)/24 - ties/48
+	if variance == 0 {
+		return TestResult{Statistic: w, PValue: 1}, nil
+	}
+	z := (w - mean + 0.5) / math.Sqrt(variance)
+	return TestResult{Statistic: w, PValue: math.Min(1, math.Erfc(math.Abs(z)/math.Sqrt2))}, nil
+}
+
+// P-valor bilateral exacto de Wilcoxon: probabilidad de que la menor suma de
+// rangos sea <= w con n pares sin empates
+func wilcoxonExact(w, n int) float64 {
+	// counts[s]: subconjuntos de {1..n} cuya suma es s
+	total := n * (n + 1) / 2
+	counts := make([]float64, total+1)
+	counts[0] = 1
+	for r := 1; r <= n; r++ {
+		for s := total; s >= r; s-- {
+			counts[s] += counts[s-r]
+		}
+	}
+	tail := 0.0
+	for s := 0; s <= w; s++ {
+		tail += counts[s]
+	}
+	return math.Min(1, 2*tail/math.Pow(2, float64(n)))
+}
+
+// DeLong compara el ROC-AUC de dos clasificadores sobre las mismas filas con
+// la covarianza de DeLong. El estadístico es z = (AUC_A - AUC_B) / error estándar
+func DeLong(scoresA, scoresB, labels []float64) (TestResult, error) {
+	if len(scoresA) != len(labels) || len(scoresB) != len(labels) {
+		return TestResult{}, ErrLengthMismatch
+	}
+	var pos, neg []int
+	for i, label := range labels {
+		if label == 1 {
+			pos = append(pos, i)
+		} else {
+			neg = append(neg, i)
+		}
+	}
+	if len(pos) < 2 || len(neg) < 2 {
+		return TestResult{}, errors.New("DeLong necesita al menos dos filas de cada clase")
+	}
+
+	aucA, v10A, v01A := delongComponents(scoresA, pos, neg)
+	aucB, v10B, v01B := delongComponents(scoresB, pos, neg)
+	variance := (sampleCov(v10A, v10A)+sampleCov(v10B, v10B)-2*sampleCov(v10A, v10B))/float64(len(pos)) +
+		(sampleCov(v01A, v01A)+sampleCov(v01B, v01B)-2*sampleCov(v01A, v01B))/float64(len(neg))
+	if variance <= 0 {
+		if aucA == aucB {
+			return TestResult{Statistic: 0, PValue: 1}, nil
+		}
+		return TestResult{Statistic: math.Copysign(math.Inf(1), aucA-aucB), PValue: 0}, nil
+	}
+	z := (aucA - aucB) / math.Sqrt(variance)
+	return TestResult{Statistic: z, PValue: math.Erfc(math.Abs(z) / math.Sqrt2)}, nil
+}
+
+// AUC y componentes estructurales de DeLong a partir de rangos medios (Sun y
+// Xu): V10[i] es la fracción de negativos por debajo del positivo i y V01[j]
+// la de positivos por encima del negativo j, contando los empates como 1/2
+func delongComponents(scores []float64, pos, neg []int) (float64, []float64, []float64) {
+	m, n := len(pos), len(neg)
+	x, y := pick(scores, pos), pick(scores, neg)
+	rx, _ := midranks(x)
+	ry, _ := midranks(y)
+	rz, _ := midranks(append(append(make([]float64, 0, m+n), x...), y...))
+
+	v10 := make([]float64, m)
+	v01 := make([]float64, n)
+	auc := 0.0
+	for i := range v10 {
+		v10[i] = (rz[i] - rx[i]) / float64(n)
+		auc += v10[i]
+	}
+	for j := range v01 {
+		v01[j] = 1 - (rz[m+j]-ry[j])/float64(m)
+	}
+	return auc / float64(m), v10, v01
+}
+
+// Covarianza muestral de dos vectores de la misma longitud
+func sampleCov(a, b []float64) float64 {
+	meanA, _ := meanStd(a)
+	meanB, _ := meanStd(b)
+	cov := 0.0
+	for i := range a {
+		cov += (a[i] - meanA) * (b[i] - meanB)
+	}
+	return cov / float64(len(a)-1)
+}
+
+// Rangos (desde 1) de los valores, con la media de los rangos en los empates.
+// Devuelve también la corrección de empates Σ(t³ - t)
+func midranks(values []float64) ([]float64, float64) {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sortIndices(order, func(a, b int) int {
+		if c := cmp.Compare(values[a], values[b]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	ranks := make([]float64, len(values))
+	ties := 0.0
+	for i := 0; i < len(order); {
+		j := i
+		for j < len(order) && values[order[j]] == values[order[i]] {
+			j++
+		}
+		// Las posiciones i..j-1 empatan y comparten el rango medio
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			ranks[order[k]] = rank
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+	return ranks, ties
+}
+
+// P-valor de un chi cuadrado con 1 grado de libertad
+func chiSquare1PValue(stat float64) float64 {
+	return math.Erfc(math.Sqrt(stat / 2))
+}
+
+// P-valor bilateral de una t de Student con df grados de libertad
+func studentTPValue(t, df float64) float64 {
+	return regularizedBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// Función beta incompleta regularizada I_x(a, b), por fracción continua
+func regularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// La fracción converge rápido para x < (a+1)/(a+b+2); si no se usa la simetría
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(x, a, b) / a
+	}
+	return 1 - front*betaFraction(1-x, b, a)/b
+}
+
+// Fracción continua de la beta incompleta (método de Lentz)
+func betaFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= 300; m++ {
+		fm := float64(m)
+		// Término par
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Término impar
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-14 {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,92 @@
+package classification
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMcNemar(t *testing.T) {
+	// A acierta sola en 10 filas y B en 2: χ² = (|10 - 2| - 1)² / 12
+	var predictionsA, predictionsB, actuals []float64
+	for i := range 20 {
+		actuals = append(actuals, 1)
+		switch {
+		case i < 10:
+			predictionsA, predictionsB = append(predictionsA, 1), append(predictionsB, 0)
+		case i < 12:
+			predictionsA, predictionsB = append(predictionsA, 0), append(predictionsB, 1)
+		default:
+			predictionsA, predictionsB = append(predictionsA, 1), append(predictionsB, 1)
+		}
+	}
+	result, err := McNemar(predictionsA, predictionsB, actuals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat := 49.0 / 12
+	if math.Abs(result.Statistic-stat) > 1e-12 {
+		t.Errorf("estadístico = %v, se esperaba %v", result.Statistic, stat)
+	}
+	if want := math.Erfc(math.Sqrt(stat / 2)); math.Abs(result.PValue-want) > 1e-9 {
+		t.Errorf("p-valor = %v, se esperaba %v", result.PValue, want)
+	}
+
+	same, _ := McNemar(predictionsA, predictionsA, actuals)
+	if same.PValue != 1 {
+		t.Errorf("modelos idénticos: p-valor = %v, se esperaba 1", same.PValue)
+	}
+}
+
+func TestWilcoxonSignedRankExact(t *testing.T) {
+	// Cinco diferencias positivas sin empates: W = 0 y p = 2/2⁵
+	result, err := WilcoxonSignedRank([]float64{2, 3, 4, 5, 6}, []float64{1, 1, 1, 1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Statistic != 0 || result.PValue != 0.0625 {
+		t.Errorf("resultado = %+v, se esperaba W = 0 y p = 0.0625", result)
+	}
+}
+
+func TestCorrectedResampledTTest(t *testing.T) {
+	a := []float64{0.80, 0.82, 0.78, 0.85, 0.81}
+	b := []float64{0.75, 0.80, 0.76, 0.79, 0.80}
+	result, err := CorrectedResampledTTest(a, b, 0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Diferencias 0.05, 0.02, 0.02, 0.06, 0.01: media 0.032 y varianza muestral 0.00047
+	want := 0.032 / math.Sqrt((1.0/5+0.25)*0.00047)
+	if math.Abs(result.Statistic-want) > 1e-9 {
+		t.Errorf("t = %v, se esperaba %v", result.Statistic, want)
+	}
+	if !(result.PValue > 0 && result.PValue < 0.2) {
+		t.Errorf("p-valor = %v", result.PValue)
+	}
+	if swapped, _ := CorrectedResampledTTest(b, a, 0.25); math.Abs(swapped.PValue-result.PValue) > 1e-12 {
+		t.Errorf("la prueba bilateral debe ser simétrica: %v y %v", swapped.PValue, result.PValue)
+	}
+}
+
+func TestDeLong(t *testing.T) {
+	labels := []float64{1, 1, 1, 1, 0, 0, 0, 0}
+	perfect := []float64{0.9, 0.8, 0.7, 0.6, 0.4, 0.3, 0.2, 0.1}
+	noisy := []float64{0.9, 0.3, 0.7, 0.2, 0.8, 0.4, 0.6, 0.1}
+	same, err := DeLong(perfect, perfect, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.Statistic != 0 || same.PValue != 1 {
+		t.Errorf("mismo modelo: %+v", same)
+	}
+	better, err := DeLong(perfect, noisy, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if better.Statistic <= 0 || better.PValue >= 1 {
+		t.Errorf("modelo perfecto frente a ruidoso: %+v", better)
+	}
+	if _, err := DeLong(perfect[:2], perfect[:2], labels[:2]); err == nil {
+		t.Error("una fila por clase: se esperaba un error")
+	}
+}
@@ -0,0 +1,154 @@
+package classification
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"src/models"
+)
+
+// CostMatrix es el costo de cada resultado de una predicción binaria
+type CostMatrix struct {
+	TP float64 `json:"tp"`
+	FP float64 `json:"fp"`
+	FN float64 `json:"fn"`
+	TN float64 `json:"tn"`
+}
+
+// ThresholdRule es el objetivo con el que TuneThreshold elige el umbral. Se
+// crea con FBetaRule, CostRule, TargetPrecisionRule o TargetRecallRule
+type ThresholdRule struct {
+	name string
+	// Valor a maximizar con los conteos de un umbral; false si el umbral no es admisible
+	value func(tp, fp, fn, tn int) (float64, bool)
+}
+
+func (r ThresholdRule) String() string {
+	return r.name
+}
+
+// FBetaRule maximiza F-beta: beta > 1 da más peso al recall y beta < 1 a la precision
+func FBetaRule(beta float64) ThresholdRule {
+	return ThresholdRule{
+		name: fmt.Sprintf("f%g", beta),
+		value: func(tp, fp, fn, _ int) (float64, bool) {
+			return FBeta(Precision(tp, fp), Recall(tp, fn), beta), true
+		},
+	}
+}
+
+// CostRule minimiza el costo medio por fila según la matriz de costos
+func CostRule(costs CostMatrix) ThresholdRule {
+	return ThresholdRule{
+		name: "cost",
+		value: func(tp, fp, fn, tn int) (float64, bool) {
+			return -expectedCost(costs, tp, fp, fn, tn), true
+		},
+	}
+}
+
+// TargetPrecisionRule maximiza el recall entre los umbrales con precision >= target
+func TargetPrecisionRule(target float64) ThresholdRule {
+	return ThresholdRule{
+		name: fmt.Sprintf("precision>=%g", target),
+		value: func(tp, fp, fn, _ int) (float64, bool) {
+			return Recall(tp, fn), Precision(tp, fp) >= target
+		},
+	}
+}
+
+// TargetRecallRule maximiza la precision entre los umbrales con recall >= target
+func TargetRecallRule(target float64) ThresholdRule {
+	return ThresholdRule{
+		name: fmt.Sprintf("recall>=%g", target),
+		value: func(tp, fp, fn, _ int) (float64, bool) {
+			return Precision(tp, fp), Recall(tp, fn) >= target
+		},
+	}
+}
+
+// ThresholdChoice es el umbral elegido y las métricas que alcanza. Las filas
+// con puntuación >= Threshold se predicen como positivas
+type ThresholdChoice struct {
+	Threshold float64 `json:"threshold"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	TP        int     `json:"tp"`
+	FP        int     `json:"fp"`
+	FN        int     `json:"fn"`
+	TN        int     `json:"tn"`
+}
+
+// ErrNoThreshold indica que ningún umbral cumple la precision o el recall pedidos
+var ErrNoThreshold = errors.New("ningún umbral alcanza el objetivo pedido")
+
+// FBeta calcula la media armónica ponderada de precision y recall
+func FBeta(precision, recall, beta float64) float64 {
+	b2 := beta * beta
+	if b2*precision+recall == 0 {
+		return 0
+	}
+	return (1 + b2) * precision * recall / (b2*precision + recall)
+}
+
+// Costo medio por fila de unos conteos
+func expectedCost(costs CostMatrix, tp, fp, fn, tn int) float64 {
+	total := float64(tp + fp + fn + tn)
+	return (costs.TP*float64(tp) + costs.FP*float64(fp) + costs.FN*float64(fn) + costs.TN*float64(tn)) / total
+}
+
+// Cost devuelve el costo medio por fila del umbral elegido
+func (c ThresholdChoice) Cost(costs CostMatrix) float64 {
+	return expectedCost(costs, c.TP, c.FP, c.FN, c.TN)
+}
+
+// TuneThreshold recorre todos los umbrales distintos de las puntuaciones, más
+// uno por encima de la mayor (ninguna fila positiva), y devuelve el mejor
+// según la regla. Con empates gana el umbral más alto
+func TuneThreshold(scores, labels []float64, rule ThresholdRule) (ThresholdChoice, error) {
+	r, err := rank(scores, labels)
+	if err != nil {
+		return ThresholdChoice{}, err
+	}
+	choose := func(threshold float64, tp, fp int) ThresholdChoice {
+		p, rec := Precision(tp, fp), Recall(tp, r.pos-tp)
+		return ThresholdChoice{
+			Threshold: threshold,
+			Precision: p,
+			Recall:    rec,
+			F1:        F1Score(p, rec),
+			TP:        tp,
+			FP:        fp,
+			FN:        r.pos - tp,
+			TN:        r.neg - fp,
+		}
+	}
+
+	var best ThresholdChoice
+	bestValue, found := math.Inf(-1), false
+	if value, ok := rule.value(0, 0, r.pos, r.neg); ok {
+		best, bestValue, found = choose(math.Nextafter(r.thresholds[0], math.Inf(1)), 0, 0), value, true
+	}
+	for i, t := range r.thresholds {
+		tp, fp := r.tps[i], r.fps[i]
+		if value, ok := rule.value(tp, fp, r.pos-tp, r.neg-fp); ok && value > bestValue {
+			best, bestValue, found = choose(t, tp, fp), value, true
+		}
+	}
+	if !found {
+		return ThresholdChoice{}, ErrNoThreshold
+	}
+	return best, nil
+}
+
+// TuneClassifier elige el umbral de un clasificador entrenado con sus
+// probabilidades sobre un conjunto de validación (X, y) y lo devuelve envuelto
+// en un modelo que predice con ese umbral
+func TuneClassifier(model models.Classifier, X [][]float64, y []float64, rule ThresholdRule) (*models.Thresholded, ThresholdChoice, error) {
+	choice, err := TuneThreshold(model.PredictProba(X), y, rule)
+	if err != nil {
+		return nil, ThresholdChoice{}, err
+	}
+	return &models.Thresholded{Model: model, Threshold: choice.Threshold}, choice, nil
+}
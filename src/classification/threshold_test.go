@@ -0,0 +1,97 @@
+package classification
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Umbrales 0.9, 0.8, 0.7, 0.6, 0.3 y 0.2 con positivos en 0.9, 0.8 y 0.6
+var (
+	thresholdScores = []float64{0.9, 0.8, 0.7, 0.6, 0.3, 0.2}
+	thresholdLabels = []float64{1, 1, 0, 1, 0, 0}
+)
+
+func TestTuneThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   ThresholdRule
+		want   float64
+		tp, fp int
+	}{
+		// F1 = 6/7 en 0.6, frente a 0.8 en 0.8
+		{"f1", FBetaRule(1), 0.6, 3, 1},
+		// Precision 1 con el mayor recall
+		{"precision", TargetPrecisionRule(1), 0.8, 2, 0},
+		// Recall 1 con la mayor precision
+		{"recall", TargetRecallRule(1), 0.6, 3, 1},
+		// Un falso negativo cuesta más que un falso positivo
+		{"costo fn", CostRule(CostMatrix{FP: 1, FN: 5}), 0.6, 3, 1},
+		// Un falso positivo cuesta más que un falso negativo
+		{"costo fp", CostRule(CostMatrix{FP: 10, FN: 1}), 0.8, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TuneThreshold(thresholdScores, thresholdLabels, tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Threshold != tt.want || got.TP != tt.tp || got.FP != tt.fp {
+				t.Errorf("umbral %v con TP %d, FP %d; se esperaba %v con TP %d, FP %d",
+					got.Threshold, got.TP, got.FP, tt.want, tt.tp, tt.fp)
+			}
+			if got.TP+got.FP+got.FN+got.TN != len(thresholdLabels) {
+				t.Errorf("los conteos %+v no suman %d filas", got, len(thresholdLabels))
+			}
+		})
+	}
+}
+
+func TestTuneThresholdTiesPickHighest(t *testing.T) {
+	// Sin costo por falso negativo, no predecir positivos empata con 0.9 y 0.8
+	got, err := TuneThreshold(thresholdScores, thresholdLabels, CostRule(CostMatrix{FP: 10}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Threshold <= 0.9 || got.TP+got.FP != 0 {
+		t.Errorf("umbral %v con %d positivos; se esperaba uno por encima de 0.9 sin positivos",
+			got.Threshold, got.TP+got.FP)
+	}
+}
+
+func TestTuneThresholdErrors(t *testing.T) {
+	// El negativo tiene la mayor puntuación: ningún umbral da precision 1
+	if _, err := TuneThreshold([]float64{0.9, 0.1}, []float64{0, 1}, TargetPrecisionRule(1)); !errors.Is(err, ErrNoThreshold) {
+		t.Errorf("error = %v, se esperaba ErrNoThreshold", err)
+	}
+	if _, err := TuneThreshold([]float64{0.9, 0.5, 0.1}, []float64{0, 1, 2}, FBetaRule(1)); !errors.Is(err, ErrNotBinary) {
+		t.Errorf("error = %v, se esperaba ErrNotBinary", err)
+	}
+}
+
+func TestFBeta(t *testing.T) {
+	tests := []struct {
+		name                    string
+		precision, recall, beta float64
+		want                    float64
+	}{
+		{"f1", 0.5, 1, 1, 2.0 / 3},
+		{"f2 favorece el recall", 0.5, 1, 2, 5.0 / 6},
+		{"f0.5 favorece la precision", 0.5, 1, 0.5, 1.25 * 0.5 / 1.125},
+		{"ceros", 0, 0, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FBeta(tt.precision, tt.recall, tt.beta); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("FBeta = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholdChoiceCost(t *testing.T) {
+	choice := ThresholdChoice{TP: 3, FP: 1, FN: 0, TN: 2}
+	if got := choice.Cost(CostMatrix{FP: 1, FN: 5}); math.Abs(got-1.0/6) > 1e-12 {
+		t.Errorf("Cost = %v, se esperaba 1/6", got)
+	}
+}
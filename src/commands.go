@@ -0,0 +1,915 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"src/classification"
+	"src/data"
+	"src/models"
+	"strconv"
+	"time"
+)
+
+// Resultado de entrenar o evaluar un modelo
+type result struct {
+	Algorithm    string             `json:"algorithm,omitempty"`
+	Mode         string             `json:"mode,omitempty"`
+	TrainSeconds float64            `json:"train_seconds,omitempty"`
+	Metrics      map[string]float64 `json:"metrics"`
+
+	// Intervalos de confianza bootstrap de las métricas, si se pidieron
+	Intervals map[string]classification.Interval `json:"intervals,omitempty"`
+	// Umbral de decisión elegido con -tune
+	Threshold *float64 `json:"threshold,omitempty"`
+}
+
+// Flags compartidos por los comandos que leen un dataset
+type dataFlags struct {
+	data   string
+	target string
+	format string
+	task   string
+}
+
+func (d *dataFlags) register(fs *flag.FlagSet, defaultData string) {
+	fs.StringVar(&d.data, "data", defaultData, "ruta del CSV con encabezado")
+	fs.StringVar(&d.target, "target", "", "nombre de la columna objetivo (por defecto la última)")
+	fs.StringVar(&d.format, "format", "text", "formato de las métricas: text o json")
+	fs.StringVar(&d.task, "task", "auto", "evaluar como classification, regression o auto (según el modelo y el objetivo)")
+}
+
+// Flags del preprocesamiento aplicado antes de entrenar
+type prepFlags struct {
+	impute    string
+	fillValue float64
+	knnK      int
+	indicator bool
+	encode    string
+	unknown   string
+	smoothing float64
+	scale     string
+}
+
+func (p *prepFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&p.impute, "impute", "", "imputación de valores faltantes: mean, median, most_frequent, constant o knn")
+	fs.Float64Var(&p.fillValue, "fill-value", 0, "valor usado por -impute constant en columnas numéricas")
+	fs.IntVar(&p.knnK, "knn-k", 5, "vecinos usados por -impute knn")
+	fs.BoolVar(&p.indicator, "missing-indicator", false, "añadir una columna 0/1 por cada columna con valores faltantes")
+	fs.StringVar(&p.encode, "encode", "", "codificación de columnas categóricas: onehot, ordinal o target")
+	fs.StringVar(&p.unknown, "unknown", "error", "categorías no vistas al entrenar: error o ignore")
+	fs.Float64Var(&p.smoothing, "smoothing", 10, "suavizado de la codificación target")
+	fs.StringVar(&p.scale, "scale", "", "escalado de columnas numéricas: standard, minmax o robust")
+}
+
+// Construye el pipeline sin ajustar descrito por los flags; en modo "con" los
+// escaladores se ajustan en paralelo por columnas
+func (p prepFlags) build(mode string) (*data.Pipeline, error) {
+	var unknown data.UnknownPolicy
+	switch p.unknown {
+	case "error":
+		unknown = data.UnknownError
+	case "ignore":
+		unknown = data.UnknownIgnore
+	default:
+		return nil, fmt.Errorf("política desconocida %q (use error o ignore)", p.unknown)
+	}
+
+	pipeline := &data.Pipeline{}
+	switch p.impute {
+	case "":
+	case "mean":
+		pipeline.Steps = append(pipeline.Steps, &data.SimpleImputer{Strategy: data.ImputeMean, AddIndicator: p.indicator})
+	case "median":
+		pipeline.Steps = append(pipeline.Steps, &data.SimpleImputer{Strategy: data.ImputeMedian, AddIndicator: p.indicator})
+	case "most_frequent":
+		pipeline.Steps = append(pipeline.Steps, &data.SimpleImputer{Strategy: data.ImputeMostFrequent, AddIndicator: p.indicator})
+	case "constant":
+		pipeline.Steps = append(pipeline.Steps, &data.SimpleImputer{Strategy: data.ImputeConstant, FillValue: p.fillValue, AddIndicator: p.indicator})
+	case "knn":
+		pipeline.Steps = append(pipeline.Steps, &data.KNNImputer{K: p.knnK, AddIndicator: p.indicator})
+	default:
+		return nil, fmt.Errorf("imputación desconocida %q (use mean, median, most_frequent, constant o knn)", p.impute)
+	}
+
+	switch p.encode {
+	case "":
+	case "onehot":
+		pipeline.Steps = append(pipeline.Steps, &data.OneHotEncoder{Unknown: unknown})
+	case "ordinal":
+		pipeline.Steps = append(pipeline.Steps, &data.OrdinalEncoder{Unknown: unknown})
+	case "target":
+		pipeline.Steps = append(pipeline.Steps, &data.TargetEncoder{Unknown: unknown, Smoothing: p.smoothing})
+	default:
+		return nil, fmt.Errorf("codificación desconocida %q (use onehot, ordinal o target)", p.encode)
+	}
+
+	concurrent := mode == "con"
+	switch p.scale {
+	case "":
+	case "standard":
+		pipeline.Steps = append(pipeline.Steps, &data.StandardScaler{Concurrent: concurrent})
+	case "minmax":
+		pipeline.Steps = append(pipeline.Steps, &data.MinMaxScaler{Concurrent: concurrent})
+	case "robust":
+		pipeline.Steps = append(pipeline.Steps, &data.RobustScaler{Concurrent: concurrent})
+	default:
+		return nil, fmt.Errorf("escalado desconocido %q (use standard, minmax o robust)", p.scale)
+	}
+	return pipeline, nil
+}
+
+// Flags de los intervalos de confianza bootstrap
+type bootstrapFlags struct {
+	resamples  int
+	method     string
+	confidence float64
+}
+
+func (b *bootstrapFlags) register(fs *flag.FlagSet) {
+	fs.IntVar(&b.resamples, "bootstrap", 0, "remuestreos bootstrap para los intervalos de confianza de las métricas (0 = no calcularlos)")
+	fs.StringVar(&b.method, "ci", "percentile", "método de los intervalos: percentile o bca")
+	fs.Float64Var(&b.confidence, "confidence", 0.95, "nivel de confianza de los intervalos")
+}
+
+// Intervalos de las métricas del modelo sobre el dataset, o nil si no se pidieron
+func (b bootstrapFlags) intervals(model models.Regressor, ds *data.Dataset, task classification.Task, seed int64) (map[string]classification.Interval, error) {
+	if b.resamples <= 0 {
+		return nil, nil
+	}
+	method, err := classification.ParseIntervalMethod(b.method)
+	if err != nil {
+		return nil, err
+	}
+	bootstrap := classification.Bootstrap{Resamples: b.resamples, Confidence: b.confidence, Method: method, Seed: seed, Task: task}
+	return bootstrap.RunDataset(model, ds)
+}
+
+// Flags del ajuste del umbral de decisión
+type tuneFlags struct {
+	rule   string
+	beta   float64
+	costFP float64
+	costFN float64
+	target float64
+	size   float64
+}
+
+func (t *tuneFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&t.rule, "tune", "", "ajustar el umbral de decisión: fbeta, cost, precision o recall (vacío = umbral del modelo)")
+	fs.Float64Var(&t.beta, "beta", 1, "beta de -tune fbeta")
+	fs.Float64Var(&t.costFP, "cost-fp", 1, "costo de un falso positivo para -tune cost")
+	fs.Float64Var(&t.costFN, "cost-fn", 1, "costo de un falso negativo para -tune cost")
+	fs.Float64Var(&t.target, "target-rate", 0.8, "precision o recall mínimo de -tune precision y -tune recall")
+	fs.Float64Var(&t.size, "tune-size", 0.2, "fracción del entrenamiento reservada para elegir el umbral")
+}
+
+// Regla del umbral pedida con -tune
+func (t tuneFlags) build() (classification.ThresholdRule, error) {
+	switch t.rule {
+	case "fbeta":
+		return classification.FBetaRule(t.beta), nil
+	case "cost":
+		return classification.CostRule(classification.CostMatrix{FP: t.costFP, FN: t.costFN}), nil
+	case "precision":
+		return classification.TargetPrecisionRule(t.target), nil
+	case "recall":
+		return classification.TargetRecallRule(t.target), nil
+	}
+	return classification.ThresholdRule{}, fmt.Errorf("ajuste de umbral desconocido %q (use fbeta, cost, precision o recall)", t.rule)
+}
+
+// Configuración de un entrenamiento
+type runConfig struct {
+	data      dataFlags
+	prep      prepFlags
+	params    hyperparams
+	testSize  float64
+	split     string
+	group     string
+	rankK     int
+	relevance float64
+	bootstrap bootstrapFlags
+	tune      tuneFlags
+}
+
+// Registra los flags de la división entre entrenamiento y prueba
+func (cfg *runConfig) registerSplit(fs *flag.FlagSet) {
+	fs.Float64Var(&cfg.testSize, "test-size", 0.2, "fracción de filas reservada para prueba")
+	fs.StringVar(&cfg.split, "split", "random", "división: random, stratified (por clase), group (por -group) o time (en orden)")
+	fs.StringVar(&cfg.group, "group", "", "columna que identifica los grupos de -split group")
+}
+
+// Registra los flags de las métricas de ranking de los recomendadores
+func (cfg *runConfig) registerRanking(fs *flag.FlagSet) {
+	fs.IntVar(&cfg.rankK, "rank-k", 0, "longitud de las listas para las métricas de ranking de cf y factors (0 = no calcularlas)")
+	fs.Float64Var(&cfg.relevance, "relevance", 4, "calificación mínima de prueba para considerar relevante un ítem")
+}
+
+// Divide el dataset en entrenamiento y prueba según -split
+func (cfg runConfig) splitDataset(ds *data.Dataset) (*data.Dataset, *data.Dataset, error) {
+	percentage := 1 - cfg.testSize
+	rng := data.NewRand(cfg.params.seed)
+	switch cfg.split {
+	case "random":
+		return data.SplitDataset(ds, percentage, rng)
+	case "stratified":
+		return data.StratifiedSplitDataset(ds, percentage, rng)
+	case "group":
+		if cfg.group == "" {
+			return nil, nil, fmt.Errorf("-split group necesita -group")
+		}
+		return data.GroupSplitDataset(ds, cfg.group, percentage, rng)
+	case "time":
+		return data.TimeSplitDataset(ds, percentage)
+	}
+	return nil, nil, fmt.Errorf("división desconocida %q (use random, stratified, group o time)", cfg.split)
+}
+
+// Modelo entrenado junto con su preprocesamiento y sus métricas de prueba
+type trained struct {
+	result   result
+	report   classification.Report
+	classes  []string // Nombres de las clases del objetivo, si era categórico
+	model    models.Regressor
+	pipeline *data.Pipeline
+	test     *data.Dataset // Conjunto de prueba ya preprocesado
+}
+
+// Carga el dataset según el tipo de modelo
+func (d dataFlags) load(model models.Regressor) (*data.Dataset, error) {
+	if usesRatings(model) {
+		return getRatingPairs(d.data)
+	}
+	return data.LoadCSV(d.data, data.CSVOptions{Target: d.target})
+}
+
+// train: entrena un algoritmo, lo evalúa en el conjunto de prueba y lo guarda
+func trainCommand(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	var cfg runConfig
+	cfg.data.register(fs, "dataset/bank.csv")
+	cfg.prep.register(fs)
+	cfg.params.register(fs)
+	algoName := fs.String("algo", "svm", "algoritmo: cf, svm, ksvm, svr, ksvr, tree, ann, forest, dnn o factors")
+	mode := fs.String("mode", "seq", "variante: seq (secuencial) o con (concurrente)")
+	cfg.registerSplit(fs)
+	cfg.registerRanking(fs)
+	cfg.bootstrap.register(fs)
+	cfg.tune.register(fs)
+	modelPath := fs.String("model", "", "ruta donde guardar el modelo entrenado")
+	binary := fs.Bool("binary", false, "guardar el modelo en formato binario en lugar de JSON")
+	confusion := fs.Bool("confusion", false, "escribir la matriz de confusión y las métricas por clase del conjunto de prueba")
+	fs.Parse(args)
+
+	algo, err := lookupAlgorithm(*algoName)
+	if err != nil {
+		return err
+	}
+	run, err := runAlgorithm(algo, *mode, cfg)
+	if err != nil {
+		return err
+	}
+	run.result.Algorithm = *algoName
+
+	if *modelPath != "" {
+		if err := saveModel(run.model, *modelPath, *binary); err != nil {
+			return err
+		}
+		if err := savePipeline(run.pipeline, *modelPath); err != nil {
+			return err
+		}
+	}
+	if err := writeResults(os.Stdout, cfg.data.format, []result{run.result}); err != nil {
+		return err
+	}
+	if *confusion {
+		return writeConfusion(os.Stdout, run.report, run.classes)
+	}
+	return nil
+}
+
+// predict: carga un modelo y escribe una predicción por fila del dataset
+func predictCommand(args []string) error {
+	fs := flag.NewFlagSet("predict", flag.ExitOnError)
+	dataPath := fs.String("data", "", "ruta del CSV con encabezado; para recomendadores, columnas usuario,ítem")
+	target := fs.String("target", "", "columna a descartar si aparece en el CSV")
+	modelPath := fs.String("model", "", "ruta del modelo guardado")
+	out := fs.String("out", "", "archivo de salida (por defecto la salida estándar)")
+	proba := fs.Bool("proba", false, "escribir la probabilidad de la clase positiva en lugar de la etiqueta")
+	fs.Parse(args)
+
+	model, pipeline, err := loadModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	ds, err := data.LoadCSV(*dataPath, data.CSVOptions{NoTarget: true})
+	if err != nil {
+		return err
+	}
+	ds.DropFeature(*target)
+	if ds, err = pipeline.Transform(ds); err != nil {
+		return err
+	}
+	features, err := ds.Matrix()
+	if err != nil {
+		return err
+	}
+
+	var predictions []float64
+	if *proba {
+		classifier, ok := model.(models.Classifier)
+		if !ok {
+			return fmt.Errorf("el modelo %s no devuelve probabilidades", *modelPath)
+		}
+		predictions = classifier.PredictProba(features)
+	} else {
+		predictions = model.Predict(features)
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	bw := bufio.NewWriter(w)
+	for _, p := range predictions {
+		fmt.Fprintln(bw, strconv.FormatFloat(p, 'g', -1, 64))
+	}
+	return bw.Flush()
+}
+
+// evaluate: carga un modelo y reporta sus métricas sobre un dataset completo
+func evaluateCommand(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	var df dataFlags
+	df.register(fs, "")
+	modelPath := fs.String("model", "", "ruta del modelo guardado")
+	confusion := fs.Bool("confusion", false, "escribir la matriz de confusión y las métricas por clase")
+	var bf bootstrapFlags
+	bf.register(fs)
+	fs.Parse(args)
+
+	task, err := classification.ParseTask(df.task)
+	if err != nil {
+		return err
+	}
+	model, pipeline, err := loadModel(*modelPath)
+	if err != nil {
+		return err
+	}
+	ds, err := df.load(model)
+	if err != nil {
+		return err
+	}
+	if ds, err = pipeline.Transform(ds); err != nil {
+		return err
+	}
+	report, err := classification.EvaluateDatasetAs(model, ds, task)
+	if err != nil {
+		return err
+	}
+	intervals, err := bf.intervals(model, ds, task, data.DefaultSeed)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(os.Stdout, df.format, []result{{Metrics: report.Metrics(), Intervals: intervals}}); err != nil {
+		return err
+	}
+	if *confusion {
+		return writeConfusion(os.Stdout, report, ds.Classes)
+	}
+	return nil
+}
+
+// benchmark: entrena ambas variantes de uno o todos los algoritmos y compara
+func benchmarkCommand(args []string) error {
+	fs := flag.NewFlagSet("benchmark", flag.ExitOnError)
+	var cfg runConfig
+	cfg.data.register(fs, "dataset/bank.csv")
+	cfg.prep.register(fs)
+	cfg.params.register(fs)
+	ratingsData := fs.String("ratings-data", "dataset/clean_movies.csv", "matriz de calificaciones para cf y factors")
+	algoName := fs.String("algo", "all", "algoritmo a comparar o all")
+	cfg.registerSplit(fs)
+	cfg.registerRanking(fs)
+	cfg.bootstrap.register(fs)
+	cfg.tune.register(fs)
+	fs.Parse(args)
+
+	names := algorithmOrder
+	if *algoName != "all" {
+		names = []string{*algoName}
+	}
+
+	var results []result
+	for _, name := range names {
+		algo, err := lookupAlgorithm(name)
+		if err != nil {
+			return err
+		}
+		algoCfg := cfg
+		if usesRatings(algo.sequential()) {
+			algoCfg.data.data = *ratingsData
+		}
+		for _, mode := range []string{"seq", "con"} {
+			run, err := runAlgorithm(algo, mode, algoCfg)
+			if err != nil {
+				return fmt.Errorf("%s (%s): %w", name, mode, err)
+			}
+			run.result.Algorithm = name
+			results = append(results, run.result)
+		}
+	}
+	return writeResults(os.Stdout, cfg.data.format, results)
+}
+
+// cv: evalúa un algoritmo con validación cruzada k-fold, entrenando los folds en paralelo
+func cvCommand(args []string) error {
+	fs := flag.NewFlagSet("cv", flag.ExitOnError)
+	var cfg runConfig
+	cfg.data.register(fs, "dataset/bank.csv")
+	cfg.prep.register(fs)
+	cfg.params.register(fs)
+	algoName := fs.String("algo", "svm", "algoritmo: cf, svm, ksvm, svr, ksvr, tree, ann, forest, dnn o factors")
+	mode := fs.String("mode", "seq", "variante: seq (secuencial) o con (concurrente)")
+	k := fs.Int("k", 5, "número de folds")
+	stratified := fs.Bool("stratified", false, "conservar en cada fold la proporción de cada clase")
+	repeats := fs.Int("repeats", 1, "repeticiones del k-fold con barajados distintos")
+	workers := fs.Int("workers", 0, "folds entrenados a la vez (0 = GOMAXPROCS)")
+	fs.Parse(args)
+
+	algo, err := lookupAlgorithm(*algoName)
+	if err != nil {
+		return err
+	}
+	task, err := classification.ParseTask(cfg.data.task)
+	if err != nil {
+		return err
+	}
+	cv := classification.CrossValidator{
+		K:          *k,
+		Stratified: *stratified,
+		Repeats:    *repeats,
+		Workers:    *workers,
+		Seed:       cfg.params.seed,
+		Task:       task,
+	}
+	res, err := crossValidate(algo, *mode, cfg, cv)
+	if err != nil {
+		return err
+	}
+
+	switch cfg.data.format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	case "text":
+		fmt.Printf("== %s (%s), %d folds ==\n", *algoName, *mode, res.Folds)
+		names := make([]string, 0, len(res.Metrics))
+		for name := range res.Metrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s: %.4f ± %.4f\n", name, res.Metrics[name].Mean, res.Metrics[name].Std)
+		}
+		return nil
+	}
+	return fmt.Errorf("formato desconocido %q (use text o json)", cfg.data.format)
+}
+
+// Valida con k-fold la variante pedida del algoritmo sobre el dataset completo
+func crossValidate(algo algorithm, mode string, cfg runConfig, cv classification.CrossValidator) (*classification.CVResult, error) {
+	model, err := algo.build(mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.params.apply(model); err != nil {
+		return nil, err
+	}
+	if model, err = cfg.params.wrap(model); err != nil {
+		return nil, err
+	}
+	pipeline, err := cfg.prep.build(mode)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := cfg.data.load(model)
+	if err != nil {
+		return nil, err
+	}
+	return cv.RunDataset(model, ds, pipeline)
+}
+
+// compare: entrena dos algoritmos con la misma división y los mismos folds y
+// prueba si la diferencia entre ellos es significativa
+func compareCommand(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	var cfg runConfig
+	cfg.data.register(fs, "dataset/bank.csv")
+	cfg.prep.register(fs)
+	cfg.params.register(fs)
+	nameA := fs.String("a", "forest", "primer algoritmo")
+	nameB := fs.String("b", "svm", "segundo algoritmo")
+	mode := fs.String("mode", "seq", "variante: seq (secuencial) o con (concurrente)")
+	cfg.registerSplit(fs)
+	k := fs.Int("k", 5, "folds de la validación cruzada para la t corregida y Wilcoxon (0 = omitirlas)")
+	metric := fs.String("metric", "accuracy", "métrica comparada en los folds")
+	fs.Parse(args)
+
+	algoA, err := lookupAlgorithm(*nameA)
+	if err != nil {
+		return err
+	}
+	algoB, err := lookupAlgorithm(*nameB)
+	if err != nil {
+		return err
+	}
+	task, err := classification.ParseTask(cfg.data.task)
+	if err != nil {
+		return err
+	}
+
+	// Ambos modelos se prueban sobre las mismas filas
+	runA, err := runAlgorithm(algoA, *mode, cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *nameA, err)
+	}
+	runB, err := runAlgorithm(algoB, *mode, cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *nameB, err)
+	}
+	xA, err := runA.test.Matrix()
+	if err != nil {
+		return err
+	}
+	xB, err := runB.test.Matrix()
+	if err != nil {
+		return err
+	}
+	actuals := runA.test.Labels()
+
+	tests := make(map[string]classification.TestResult)
+	if runA.report.Classification && runB.report.Classification {
+		if tests["mcnemar"], err = classification.McNemar(runA.model.Predict(xA), runB.model.Predict(xB), actuals); err != nil {
+			return err
+		}
+	}
+	if runA.report.Ranked && runB.report.Ranked {
+		probasA := runA.model.(models.Classifier).PredictProba(xA)
+		probasB := runB.model.(models.Classifier).PredictProba(xB)
+		if tests["delong"], err = classification.DeLong(probasA, probasB, actuals); err != nil {
+			return err
+		}
+	}
+
+	if *k > 0 {
+		cv := classification.CrossValidator{K: *k, Seed: cfg.params.seed, Task: task}
+		cvA, err := crossValidate(algoA, *mode, cfg, cv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *nameA, err)
+		}
+		cvB, err := crossValidate(algoB, *mode, cfg, cv)
+		if err != nil {
+			return fmt.Errorf("%s: %w", *nameB, err)
+		}
+		if tests["corrected_t"], err = classification.CompareCV(cvA, cvB, *metric); err != nil {
+			return err
+		}
+		foldsA, foldsB := cvA.Metrics[*metric].Values, cvB.Metrics[*metric].Values
+		if tests["wilcoxon"], err = classification.WilcoxonSignedRank(foldsA, foldsB); err != nil {
+			return err
+		}
+	}
+
+	switch cfg.data.format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tests)
+	case "text":
+		fmt.Printf("== %s vs %s (%s) ==\n", *nameA, *nameB, *mode)
+		for _, name := range slices.Sorted(maps.Keys(tests)) {
+			fmt.Printf("%s: estadístico %.4f, p-valor %.4f\n", name, tests[name].Statistic, tests[name].PValue)
+		}
+		return nil
+	}
+	return fmt.Errorf("formato desconocido %q (use text o json)", cfg.data.format)
+}
+
+// Entrena la variante pedida del algoritmo y la evalúa en el conjunto de prueba
+func runAlgorithm(algo algorithm, mode string, cfg runConfig) (trained, error) {
+	task, err := classification.ParseTask(cfg.data.task)
+	if err != nil {
+		return trained{}, err
+	}
+	model, err := algo.build(mode)
+	if err != nil {
+		return trained{}, err
+	}
+	if err := cfg.params.apply(model); err != nil {
+		return trained{}, err
+	}
+	if model, err = cfg.params.wrap(model); err != nil {
+		return trained{}, err
+	}
+	pipeline, err := cfg.prep.build(mode)
+	if err != nil {
+		return trained{}, err
+	}
+
+	ds, err := cfg.data.load(model)
+	if err != nil {
+		return trained{}, err
+	}
+	train, test, err := cfg.splitDataset(ds)
+	if err != nil {
+		return trained{}, err
+	}
+	if train.Len() == 0 || test.Len() == 0 {
+		return trained{}, fmt.Errorf("la división dejó vacío el conjunto de entrenamiento o el de prueba")
+	}
+
+	// El preprocesamiento se ajusta solo con el conjunto de entrenamiento
+	if train, err = pipeline.FitTransform(train); err != nil {
+		return trained{}, err
+	}
+	if test, err = pipeline.Transform(test); err != nil {
+		return trained{}, err
+	}
+
+	// Con -tune el umbral se elige sobre filas de entrenamiento que el modelo no ve
+	fitSet, valid := train, (*data.Dataset)(nil)
+	if cfg.tune.rule != "" {
+		if fitSet, valid, err = data.StratifiedSplitDataset(train, 1-cfg.tune.size, data.NewRand(cfg.params.seed)); err != nil {
+			return trained{}, err
+		}
+	}
+
+	start := time.Now()
+	if err := models.FitDataset(model, fitSet); err != nil {
+		return trained{}, err
+	}
+	elapsed := time.Since(start)
+
+	var threshold *float64
+	if valid != nil {
+		if model, threshold, err = tuneThreshold(model, valid, cfg.tune); err != nil {
+			return trained{}, err
+		}
+	}
+
+	report, err := classification.EvaluateDatasetAs(model, test, task)
+	if err != nil {
+		return trained{}, err
+	}
+	intervals, err := cfg.bootstrap.intervals(model, test, task, cfg.params.seed)
+	if err != nil {
+		return trained{}, err
+	}
+	res := result{
+		Mode:         mode,
+		TrainSeconds: elapsed.Seconds(),
+		Metrics:      report.Metrics(),
+		Intervals:    intervals,
+		Threshold:    threshold,
+	}
+	if cfg.rankK > 0 && usesRatings(model) {
+		ranking, err := rankingMetrics(model, train, test, cfg.rankK, cfg.relevance)
+		if err != nil {
+			return trained{}, err
+		}
+		maps.Copy(res.Metrics, ranking)
+	}
+	return trained{result: res, report: report, classes: ds.Classes, model: model, pipeline: pipeline, test: test}, nil
+}
+
+// Elige el umbral del clasificador entrenado con sus probabilidades en valid y
+// lo devuelve envuelto para que se guarde con el modelo
+func tuneThreshold(model models.Regressor, valid *data.Dataset, flags tuneFlags) (models.Regressor, *float64, error) {
+	classifier, ok := model.(models.Classifier)
+	if !ok {
+		return nil, nil, fmt.Errorf("-tune necesita un clasificador con probabilidades")
+	}
+	rule, err := flags.build()
+	if err != nil {
+		return nil, nil, err
+	}
+	X, err := valid.Matrix()
+	if err != nil {
+		return nil, nil, err
+	}
+	tuned, choice, err := classification.TuneClassifier(classifier, X, valid.Labels(), rule)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tuned, &choice.Threshold, nil
+}
+
+// Métricas de ranking de un recomendador. Para cada usuario con ítems
+// relevantes en test se puntúan todos los ítems que no calificó en train y se
+// evalúan los k mejores
+func rankingMetrics(model models.Regressor, train, test *data.Dataset, k int, relevance float64) (map[string]float64, error) {
+	trainX, err := train.Matrix()
+	if err != nil {
+		return nil, err
+	}
+	testX, err := test.Matrix()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]map[int]bool)
+	popularity := make(map[int]int)
+	catalog := make(map[int]bool)
+	for _, pair := range trainX {
+		user, item := int(pair[0]), int(pair[1])
+		if seen[user] == nil {
+			seen[user] = make(map[int]bool)
+		}
+		if !seen[user][item] {
+			seen[user][item] = true
+			popularity[item]++
+		}
+		catalog[item] = true
+	}
+	relevant := make(map[int][]int)
+	for i, pair := range testX {
+		catalog[int(pair[1])] = true
+		if test.Labels()[i] >= relevance {
+			relevant[int(pair[0])] = append(relevant[int(pair[0])], int(pair[1]))
+		}
+	}
+
+	// Todos los pares candidatos se puntúan con una sola llamada a Predict
+	items := slices.Sorted(maps.Keys(catalog))
+	users := slices.Sorted(maps.Keys(relevant))
+	var pairs [][]float64
+	for _, user := range users {
+		for _, item := range items {
+			if !seen[user][item] {
+				pairs = append(pairs, []float64{float64(user), float64(item)})
+			}
+		}
+	}
+	predictions := model.Predict(pairs)
+
+	scores := make(map[int]map[int]float64)
+	for i, pair := range pairs {
+		user := int(pair[0])
+		if scores[user] == nil {
+			scores[user] = make(map[int]float64)
+		}
+		scores[user][int(pair[1])] = predictions[i]
+	}
+	recommended := make(map[int][]int, len(users))
+	for _, user := range users {
+		recommended[user] = classification.TopK(scores[user], k, nil)
+	}
+
+	evaluator := classification.RankingEvaluator{K: k, Catalog: len(items), Popularity: popularity, Users: len(seen)}
+	report, err := evaluator.Evaluate(recommended, relevant)
+	if err != nil {
+		return nil, err
+	}
+	at := fmt.Sprintf("@%d", k)
+	return map[string]float64{
+		"precision" + at: report.Precision,
+		"recall" + at:    report.Recall,
+		"map" + at:       report.MAP,
+		"ndcg" + at:      report.NDCG,
+		"mrr":            report.MRR,
+		"hit_rate" + at:  report.HitRate,
+		"coverage" + at:  report.Coverage,
+		"novelty" + at:   report.Novelty,
+	}, nil
+}
+
+// Escribe los resultados en texto o JSON
+func writeResults(w io.Writer, format string, results []result) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case "text":
+		for _, res := range results {
+			if res.Algorithm != "" {
+				fmt.Fprintf(w, "== %s (%s) ==\n", res.Algorithm, res.Mode)
+			}
+			names := make([]string, 0, len(res.Metrics))
+			for name := range res.Metrics {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if ci, ok := res.Intervals[name]; ok {
+					fmt.Fprintf(w, "%s: %.4f [%.4f, %.4f]\n", name, res.Metrics[name], ci.Lower, ci.Upper)
+				} else {
+					fmt.Fprintf(w, "%s: %.4f\n", name, res.Metrics[name])
+				}
+			}
+			if res.Threshold != nil {
+				fmt.Fprintf(w, "Umbral: %.4f\n", *res.Threshold)
+			}
+			if res.TrainSeconds > 0 {
+				fmt.Fprintf(w, "Tiempo de entrenamiento: %s\n", time.Duration(res.TrainSeconds*float64(time.Second)))
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("formato desconocido %q (use text o json)", format)
+}
+
+// Escribe la matriz de confusión del reporte con los nombres de las clases
+func writeConfusion(w io.Writer, report classification.Report, classes []string) error {
+	if report.Matrix == nil {
+		return fmt.Errorf("la matriz de confusión solo está disponible para clasificadores")
+	}
+	fmt.Fprintln(w)
+	if len(classes) > 0 {
+		return classification.Relabel(report.Matrix, classes).WriteTable(w)
+	}
+	return report.Matrix.WriteTable(w)
+}
+
+// Guarda el modelo en JSON o en formato binario
+func saveModel(model models.Regressor, path string, binary bool) error {
+	persistent, ok := model.(models.Persistent)
+	if !ok {
+		return fmt.Errorf("el modelo no se puede guardar")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if binary {
+		return persistent.SaveBinary(file)
+	}
+	return persistent.Save(file)
+}
+
+// Ruta del pipeline que acompaña a un modelo guardado
+func pipelinePath(modelPath string) string {
+	return modelPath + ".pipeline.json"
+}
+
+// Guarda el pipeline junto al modelo si tiene algún paso
+func savePipeline(pipeline *data.Pipeline, modelPath string) error {
+	if len(pipeline.Steps) == 0 {
+		return nil
+	}
+	file, err := os.Create(pipelinePath(modelPath))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return pipeline.Save(file)
+}
+
+// Carga un modelo de cualquier tipo registrado y, si existe, su pipeline
+func loadModel(path string) (models.Regressor, *data.Pipeline, error) {
+	if path == "" {
+		return nil, nil, fmt.Errorf("falta el flag -model")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	model, err := models.LoadModel(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pipeline := &data.Pipeline{}
+	pfile, err := os.Open(pipelinePath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return model, pipeline, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer pfile.Close()
+	if err := pipeline.Load(pfile); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", pipelinePath(path), err)
+	}
+	return model, pipeline, nil
+}
@@ -2,45 +2,266 @@ package data
 
 import (
 	"errors"
+	"fmt"
+	"maps"
+	"math"
 	"math/rand"
-	"time"
+	"slices"
 )
 
-// Función para dividir los datos
-func SplitData(data [][]float64, labels []float64, percentage float64) ([][]float64, [][]float64, []float64, []float64, error) {
+// ErrNaNLabel indica una etiqueta NaN en una división o validación
+// cruzada estratificada, que no pertenece a ninguna clase
+var ErrNaNLabel = errors.New("la etiqueta es NaN")
+
+// Función para dividir los datos. Las filas se barajan con rng; con nil se usa DefaultSeed
+func SplitData(data [][]float64, labels []float64, percentage float64, rng *rand.Rand) ([][]float64, [][]float64, []float64, []float64, error) {
+	if err := checkSplit(len(data), len(labels), percentage); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	train, test := randomIndices(len(data), percentage, rng)
+	return splitRows(data, labels, train, test)
+}
+
+// StratifiedSplitData divide como SplitData pero conserva en ambos conjuntos la
+// proporción de cada clase de labels
+func StratifiedSplitData(data [][]float64, labels []float64, percentage float64, rng *rand.Rand) ([][]float64, [][]float64, []float64, []float64, error) {
+	if err := checkSplit(len(data), len(labels), percentage); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	train, test, err := stratifiedIndices(labels, percentage, rng)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return splitRows(data, labels, train, test)
+}
+
+// GroupSplitData divide como SplitData pero deja todas las filas de un mismo
+// grupo (p. ej. un cliente) del mismo lado. percentage se aproxima sumando
+// grupos completos al entrenamiento
+func GroupSplitData[G comparable](data [][]float64, labels []float64, groups []G, percentage float64, rng *rand.Rand) ([][]float64, [][]float64, []float64, []float64, error) {
+	if err := checkSplit(len(data), len(labels), percentage); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if len(groups) != len(data) {
+		return nil, nil, nil, nil, errors.New("la longitud de los datos y los grupos no coinciden")
+	}
+	train, test := groupIndices(groups, percentage, rng)
+	return splitRows(data, labels, train, test)
+}
+
+// TimeSplitData entrena con las primeras filas y prueba con las siguientes, sin
+// barajar. Las filas deben estar ordenadas en el tiempo
+func TimeSplitData(data [][]float64, labels []float64, percentage float64) ([][]float64, [][]float64, []float64, []float64, error) {
+	if err := checkSplit(len(data), len(labels), percentage); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	train, test := timeIndices(len(data), percentage)
+	return splitRows(data, labels, train, test)
+}
+
+// SplitDataset divide un dataset igual que SplitData: percentage es la fracción de filas de entrenamiento
+func SplitDataset(ds *Dataset, percentage float64, rng *rand.Rand) (*Dataset, *Dataset, error) {
+	if err := checkSplit(ds.Len(), ds.Len(), percentage); err != nil {
+		return nil, nil, err
+	}
+	train, test := randomIndices(ds.Len(), percentage, rng)
+	return ds.Subset(train), ds.Subset(test), nil
+}
+
+// StratifiedSplitDataset divide un dataset conservando la proporción de cada clase del objetivo
+func StratifiedSplitDataset(ds *Dataset, percentage float64, rng *rand.Rand) (*Dataset, *Dataset, error) {
+	if err := checkSplit(ds.Len(), len(ds.Labels()), percentage); err != nil {
+		return nil, nil, err
+	}
+	train, test, err := stratifiedIndices(ds.Labels(), percentage, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ds.Subset(train), ds.Subset(test), nil
+}
+
+// GroupSplitDataset divide un dataset sin separar las filas que comparten el
+// valor de la columna group. La columna se conserva entre las características
+func GroupSplitDataset(ds *Dataset, group string, percentage float64, rng *rand.Rand) (*Dataset, *Dataset, error) {
+	if err := checkSplit(ds.Len(), ds.Len(), percentage); err != nil {
+		return nil, nil, err
+	}
+	j := ds.Feature(group)
+	if j == -1 {
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownColumn, group)
+	}
+
+	var train, test []int
+	if col := ds.Features[j]; col.Type == Categorical {
+		train, test = groupIndices(col.Strings, percentage, rng)
+	} else {
+		train, test = groupIndices(col.Numbers, percentage, rng)
+	}
+	return ds.Subset(train), ds.Subset(test), nil
+}
+
+// TimeSplitDataset entrena con las primeras filas del dataset y prueba con las siguientes
+func TimeSplitDataset(ds *Dataset, percentage float64) (*Dataset, *Dataset, error) {
+	if err := checkSplit(ds.Len(), ds.Len(), percentage); err != nil {
+		return nil, nil, err
+	}
+	train, test := timeIndices(ds.Len(), percentage)
+	return ds.Subset(train), ds.Subset(test), nil
+}
+
+// Valida las longitudes y el porcentaje de una división
+func checkSplit(rows, labels int, percentage float64) error {
 	// Verificar que las longitudes coincidan
-	if len(data) != len(labels) {
-		return nil, nil, nil, nil, errors.New("la longitud de los datos y las etiquetas no coinciden")
+	if rows != labels {
+		return errors.New("la longitud de los datos y las etiquetas no coinciden")
 	}
 
 	// Verificar que el porcentaje esté en el rango [0.0, 1.0]
 	if percentage < 0.0 || percentage > 1.0 {
-		return nil, nil, nil, nil, errors.New("el porcentaje debe estar entre 0.0 y 1.0")
+		return errors.New("el porcentaje debe estar entre 0.0 y 1.0")
+	}
+	return nil
+}
+
+// Copia las filas indicadas a los conjuntos de entrenamiento y prueba
+func splitRows(data [][]float64, labels []float64, train, test []int) ([][]float64, [][]float64, []float64, []float64, error) {
+	// Inicializar slices con la capacidad adecuada
+	trainData := make([][]float64, 0, len(train))
+	trainLabels := make([]float64, 0, len(train))
+	testData := make([][]float64, 0, len(test))
+	testLabels := make([]float64, 0, len(test))
+
+	// Llenar los conjuntos de entrenamiento y prueba
+	for _, idx := range train {
+		trainData = append(trainData, data[idx])
+		trainLabels = append(trainLabels, labels[idx])
+	}
+	for _, idx := range test {
+		testData = append(testData, data[idx])
+		testLabels = append(testLabels, labels[idx])
 	}
 
-	total := len(data)
+	return trainData, testData, trainLabels, testLabels, nil
+}
+
+// Índices de una división aleatoria simple
+func randomIndices(total int, percentage float64, rng *rand.Rand) ([]int, []int) {
 	splitIndex := int(float64(total) * percentage)
+	indices := orDefault(rng).Perm(total)
+	return indices[:splitIndex], indices[splitIndex:]
+}
 
-	// Crear índices aleatorios
-	rand.Seed(time.Now().UnixNano()) // Usar una semilla para garantizar aleatoriedad
-	indices := rand.Perm(total)
+// Índices de una división estratificada: cada clase aporta al entrenamiento la
+// misma fracción de sus filas. Ambos conjuntos se barajan al final para no
+// dejar las filas agrupadas por clase
+func stratifiedIndices(labels []float64, percentage float64, rng *rand.Rand) ([]int, []int, error) {
+	rng = orDefault(rng)
+	byClass, err := rowsByClass(labels)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Inicializar slices con la capacidad adecuada
-	trainData := make([][]float64, 0, splitIndex)
-	trainLabels := make([]float64, 0, splitIndex)
-	testData := make([][]float64, 0, total-splitIndex)
-	testLabels := make([]float64, 0, total-splitIndex)
+	var train, test []int
+	for _, label := range slices.Sorted(maps.Keys(byClass)) {
+		rows := byClass[label]
+		rng.Shuffle(len(rows), func(a, b int) { rows[a], rows[b] = rows[b], rows[a] })
+		splitIndex := int(math.Round(float64(len(rows)) * percentage))
+		train = append(train, rows[:splitIndex]...)
+		test = append(test, rows[splitIndex:]...)
+	}
+	rng.Shuffle(len(train), func(a, b int) { train[a], train[b] = train[b], train[a] })
+	rng.Shuffle(len(test), func(a, b int) { test[a], test[b] = test[b], test[a] })
+	return train, test, nil
+}
 
-	// Llenar los conjuntos de entrenamiento y prueba
-	for i, idx := range indices {
-		if i < splitIndex {
-			trainData = append(trainData, data[idx])
-			trainLabels = append(trainLabels, labels[idx])
+// Filas de cada clase de labels. NaN se rechaza: como clave de un mapa nunca
+// es igual a sí mismo, así que sus filas se perderían
+func rowsByClass(labels []float64) (map[float64][]int, error) {
+	byClass := make(map[float64][]int)
+	for i, label := range labels {
+		if math.IsNaN(label) {
+			return nil, fmt.Errorf("%w: fila %d", ErrNaNLabel, i+1)
+		}
+		byClass[label] = append(byClass[label], i)
+	}
+	return byClass, nil
+}
+
+// Índices de una división por grupos: los grupos se barajan y cada uno va
+// completo al entrenamiento si así se acerca a la fracción pedida de filas
+func groupIndices[G comparable](groups []G, percentage float64, rng *rand.Rand) ([]int, []int) {
+	rng = orDefault(rng)
+	var order []G
+	rows := make(map[G][]int)
+	for i, g := range groups {
+		if _, ok := rows[g]; !ok {
+			order = append(order, g)
+		}
+		rows[g] = append(rows[g], i)
+	}
+	rng.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+
+	target := int(math.Round(float64(len(groups)) * percentage))
+	var train, test []int
+	for _, g := range order {
+		if missing := target - len(train); missing > 0 && len(rows[g])-missing <= missing {
+			train = append(train, rows[g]...)
 		} else {
-			testData = append(testData, data[idx])
-			testLabels = append(testLabels, labels[idx])
+			test = append(test, rows[g]...)
 		}
 	}
+	return train, test
+}
 
-	return trainData, testData, trainLabels, testLabels, nil
+// Índices de una división temporal: las primeras filas para entrenamiento
+func timeIndices(total int, percentage float64) ([]int, []int) {
+	splitIndex := int(float64(total) * percentage)
+	indices := make([]int, total)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices[:splitIndex], indices[splitIndex:]
+}
+
+// KFold reparte total filas barajadas con rng en k folds de prueba de tamaño
+// casi igual; el fold i se usa para probar y el resto para entrenar
+func KFold(total, k int, rng *rand.Rand) ([][]int, error) {
+	if k < 2 || k > total {
+		return nil, fmt.Errorf("k debe estar entre 2 y el número de filas (%d): %d", total, k)
+	}
+	folds := make([][]int, k)
+	for i, idx := range orDefault(rng).Perm(total) {
+		folds[i%k] = append(folds[i%k], idx)
+	}
+	return folds, nil
+}
+
+// StratifiedKFold reparte las filas en k folds conservando en cada uno la
+// proporción de cada clase de labels
+func StratifiedKFold(labels []float64, k int, rng *rand.Rand) ([][]int, error) {
+	if k < 2 || k > len(labels) {
+		return nil, fmt.Errorf("k debe estar entre 2 y el número de filas (%d): %d", len(labels), k)
+	}
+	rng = orDefault(rng)
+	byClass, err := rowsByClass(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	// Las filas de cada clase se reparten en turno rotatorio, continuando por
+	// el fold siguiente para que los tamaños queden equilibrados
+	folds := make([][]int, k)
+	next := 0
+	for _, label := range slices.Sorted(maps.Keys(byClass)) {
+		rows := byClass[label]
+		rng.Shuffle(len(rows), func(a, b int) { rows[a], rows[b] = rows[b], rows[a] })
+		for _, idx := range rows {
+			folds[next] = append(folds[next], idx)
+			next = (next + 1) % k
+		}
+	}
+	for _, fold := range folds {
+		rng.Shuffle(len(fold), func(a, b int) { fold[a], fold[b] = fold[b], fold[a] })
+	}
+	return folds, nil
 }
@@ -0,0 +1,117 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// 30 filas de la clase 0 y 10 de la clase 1; cada fila guarda su índice
+func imbalanced() ([][]float64, []float64) {
+	X := make([][]float64, 40)
+	y := make([]float64, 40)
+	for i := range X {
+		X[i] = []float64{float64(i)}
+		if i%4 == 0 {
+			y[i] = 1
+		}
+	}
+	return X, y
+}
+
+func count(labels []float64, class float64) int {
+	n := 0
+	for _, label := range labels {
+		if label == class {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSplitDataErrors(t *testing.T) {
+	X, y := imbalanced()
+	if _, _, _, _, err := SplitData(X, y[1:], 0.5, nil); err == nil {
+		t.Error("longitudes distintas: se esperaba un error")
+	}
+	if _, _, _, _, err := SplitData(X, y, 1.5, nil); err == nil {
+		t.Error("porcentaje fuera de [0, 1]: se esperaba un error")
+	}
+}
+
+func TestStratifiedSplitDataKeepsProportions(t *testing.T) {
+	X, y := imbalanced()
+	trainX, testX, trainY, testY, err := StratifiedSplitData(X, y, 0.8, NewRand(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count(trainY, 1) != 8 || count(testY, 1) != 2 {
+		t.Errorf("clase 1: %d en entrenamiento y %d en prueba; se esperaba 8 y 2", count(trainY, 1), count(testY, 1))
+	}
+	if len(trainX)+len(testX) != len(X) {
+		t.Errorf("%d filas tras dividir, se esperaban %d", len(trainX)+len(testX), len(X))
+	}
+}
+
+func TestStratifiedRejectsNaNLabels(t *testing.T) {
+	X, y := imbalanced()
+	y[5] = math.NaN()
+	if _, _, _, _, err := StratifiedSplitData(X, y, 0.8, nil); !errors.Is(err, ErrNaNLabel) {
+		t.Errorf("StratifiedSplitData: error = %v, se esperaba ErrNaNLabel", err)
+	}
+	if _, err := StratifiedKFold(y, 5, nil); !errors.Is(err, ErrNaNLabel) {
+		t.Errorf("StratifiedKFold: error = %v, se esperaba ErrNaNLabel", err)
+	}
+}
+
+func TestStratifiedKFold(t *testing.T) {
+	_, y := imbalanced()
+	folds, err := StratifiedKFold(y, 5, NewRand(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []int
+	for i, fold := range folds {
+		if len(fold) != 8 {
+			t.Errorf("fold %d: %d filas, se esperaban 8", i, len(fold))
+		}
+		positives := 0
+		for _, idx := range fold {
+			positives += int(y[idx])
+		}
+		if positives != 2 {
+			t.Errorf("fold %d: %d filas de la clase 1, se esperaban 2", i, positives)
+		}
+		all = append(all, fold...)
+	}
+	slices.Sort(all)
+	for i, idx := range all {
+		if idx != i {
+			t.Fatalf("los folds deben cubrir cada fila una sola vez: %v", all)
+		}
+	}
+}
+
+func TestKFold(t *testing.T) {
+	folds, err := KFold(10, 3, NewRand(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []int
+	for _, fold := range folds {
+		if len(fold) < 3 || len(fold) > 4 {
+			t.Errorf("fold de %d filas, se esperaban 3 o 4", len(fold))
+		}
+		all = append(all, fold...)
+	}
+	slices.Sort(all)
+	if !slices.Equal(all, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("los folds deben cubrir cada fila una sola vez: %v", all)
+	}
+	for _, k := range []int{1, 11} {
+		if _, err := KFold(10, k, nil); err == nil {
+			t.Errorf("k = %d: se esperaba un error", k)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package data
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Tipo de una columna del dataset
+type ColumnType int
+
+const (
+	Numeric     ColumnType = iota // Valores reales
+	Categorical                   // Valores de texto
+)
+
+func (t ColumnType) String() string {
+	if t == Categorical {
+		return "categorical"
+	}
+	return "numeric"
+}
+
+// Errores devueltos al leer o usar un dataset
+var (
+	ErrNoColumns       = errors.New("el CSV no tiene columnas")
+	ErrUnknownColumn   = errors.New("la columna no existe")
+	ErrCategoricalData = errors.New("el dataset tiene columnas categóricas sin codificar")
+)
+
+// ParseError indica la celda exacta que no se pudo interpretar
+type ParseError struct {
+	Line   int    // Línea del archivo, empezando en 1 y contando el encabezado
+	Column string // Nombre de la columna
+	Value  string // Texto de la celda
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("línea %d, columna %q: valor %q: %v", e.Line, e.Column, e.Value, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Columna del dataset; según su tipo usa Numbers o Strings
+type Column struct {
+	Name    string
+	Type    ColumnType
+	Numbers []float64
+	Strings []string
+}
+
+// Dataset es una tabla con nombres de columna, sus tipos y una columna objetivo
+type Dataset struct {
+	Features []Column
+	Target   Column   // Siempre numérica; si el CSV la trae como texto se indexa con Classes
+	Classes  []string // Nombres de las clases cuando el objetivo era categórico
+}
+
+// Opciones para leer un CSV
+type CSVOptions struct {
+	Target   string                // Columna objetivo; por defecto la última
+	NoTarget bool                  // El CSV no tiene columna objetivo (p. ej. datos para predecir)
+	Types    map[string]ColumnType // Tipos forzados por nombre; el resto se infiere
+	Missing  []string              // Textos que marcan una celda faltante; por defecto MissingTokens
+}
+
+// LoadCSV lee un CSV con encabezado desde un archivo
+func LoadCSV(path string, opts CSVOptions) (*Dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	ds, err := ReadCSV(file, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ds, nil
+}
+
+// ReadCSV lee un CSV con encabezado, infiere el tipo de cada columna y separa la columna objetivo
+func ReadCSV(r io.Reader, opts CSVOptions) (*Dataset, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) == 0 {
+		return nil, ErrNoColumns
+	}
+	header, rows := records[0], records[1:]
+
+	targetCol := -1
+	if !opts.NoTarget {
+		targetCol = len(header) - 1
+		if opts.Target != "" {
+			targetCol = indexOf(header, opts.Target)
+			if targetCol == -1 {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, opts.Target)
+			}
+		}
+	}
+
+	missing := opts.Missing
+	if missing == nil {
+		missing = MissingTokens
+	}
+	isMissing := missingSet(missing)
+
+	ds := &Dataset{}
+	for j, name := range header {
+		colType, forced := opts.Types[name]
+		if !forced {
+			colType = inferType(rows, j, isMissing)
+		}
+		col, err := parseColumn(rows, j, name, colType, isMissing)
+		if err != nil {
+			return nil, err
+		}
+		if j == targetCol {
+			ds.Target, ds.Classes = encodeTarget(col)
+		} else {
+			ds.Features = append(ds.Features, col)
+		}
+	}
+	return ds, nil
+}
+
+// Una columna es numérica si todas sus celdas no faltantes se pueden convertir a número
+func inferType(rows [][]string, j int, isMissing map[string]bool) ColumnType {
+	for _, row := range rows {
+		value := strings.TrimSpace(row[j])
+		if isMissing[value] {
+			continue
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return Categorical
+		}
+	}
+	return Numeric
+}
+
+// Convierte la columna j al tipo indicado, reportando la celda que falle. Las
+// celdas faltantes quedan como NaN en columnas numéricas y "" en categóricas
+func parseColumn(rows [][]string, j int, name string, colType ColumnType, isMissing map[string]bool) (Column, error) {
+	col := Column{Name: name, Type: colType}
+	if colType == Categorical {
+		col.Strings = make([]string, len(rows))
+		for i, row := range rows {
+			if value := strings.TrimSpace(row[j]); !isMissing[value] {
+				col.Strings[i] = value
+			}
+		}
+		return col, nil
+	}
+
+	col.Numbers = make([]float64, len(rows))
+	for i, row := range rows {
+		text := strings.TrimSpace(row[j])
+		if isMissing[text] {
+			col.Numbers[i] = math.NaN()
+			continue
+		}
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return col, &ParseError{Line: i + 2, Column: name, Value: row[j], Err: err}
+		}
+		col.Numbers[i] = value
+	}
+	return col, nil
+}
+
+// Los objetivos categóricos se convierten en el índice de su clase (clases en
+// orden alfabético); un objetivo faltante queda como NaN
+func encodeTarget(col Column) (Column, []string) {
+	if col.Type == Numeric {
+		return col, nil
+	}
+
+	classes := uniqueSorted(col.Strings)
+	index := make(map[string]float64, len(classes))
+	for i, c := range classes {
+		index[c] = float64(i)
+	}
+	target := Column{Name: col.Name, Type: Numeric, Numbers: make([]float64, len(col.Strings))}
+	for i, v := range col.Strings {
+		if v == "" {
+			target.Numbers[i] = math.NaN()
+			continue
+		}
+		target.Numbers[i] = index[v]
+	}
+	return target, classes
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Len devuelve el número de filas
+func (ds *Dataset) Len() int {
+	if len(ds.Features) > 0 {
+		return ds.Features[0].len()
+	}
+	return len(ds.Target.Numbers)
+}
+
+func (c Column) len() int {
+	if c.Type == Categorical {
+		return len(c.Strings)
+	}
+	return len(c.Numbers)
+}
+
+// FeatureNames devuelve los nombres de las características en orden
+func (ds *Dataset) FeatureNames() []string {
+	names := make([]string, len(ds.Features))
+	for i, col := range ds.Features {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// Feature devuelve el índice de la característica con el nombre dado, o -1
+func (ds *Dataset) Feature(name string) int {
+	return indexOf(ds.FeatureNames(), name)
+}
+
+// DropFeature elimina la característica con el nombre dado si existe
+func (ds *Dataset) DropFeature(name string) bool {
+	j := ds.Feature(name)
+	if j == -1 {
+		return false
+	}
+	ds.Features = append(ds.Features[:j:j], ds.Features[j+1:]...)
+	return true
+}
+
+// Matrix devuelve las características como filas numéricas para los modelos
+func (ds *Dataset) Matrix() ([][]float64, error) {
+	for _, col := range ds.Features {
+		if col.Type == Categorical {
+			return nil, fmt.Errorf("%w: %q", ErrCategoricalData, col.Name)
+		}
+	}
+
+	X := make([][]float64, ds.Len())
+	for i := range X {
+		X[i] = make([]float64, len(ds.Features))
+		for j, col := range ds.Features {
+			X[i][j] = col.Numbers[i]
+		}
+	}
+	return X, nil
+}
+
+// Labels devuelve la columna objetivo
+func (ds *Dataset) Labels() []float64 {
+	return ds.Target.Numbers
+}
+
+// Subset devuelve un nuevo dataset con las filas indicadas, en ese orden
+func (ds *Dataset) Subset(indices []int) *Dataset {
+	sub := &Dataset{
+		Features: make([]Column, len(ds.Features)),
+		Target:   ds.Target.subset(indices),
+		Classes:  ds.Classes,
+	}
+	for j, col := range ds.Features {
+		sub.Features[j] = col.subset(indices)
+	}
+	return sub
+}
+
+func (c Column) subset(indices []int) Column {
+	sub := Column{Name: c.Name, Type: c.Type}
+	if c.Numbers != nil {
+		sub.Numbers = make([]float64, len(indices))
+		for i, idx := range indices {
+			sub.Numbers[i] = c.Numbers[idx]
+		}
+	}
+	if c.Strings != nil {
+		sub.Strings = make([]string, len(indices))
+		for i, idx := range indices {
+			sub.Strings[i] = c.Strings[idx]
+		}
+	}
+	return sub
+}
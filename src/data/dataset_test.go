@@ -0,0 +1,139 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const people = `age,city,income,bought
+34,Lima,1200.5,yes
+NA,Quito,980,no
+51,,1500,yes
+28,Lima,?,no
+`
+
+func TestReadCSVInfersTypes(t *testing.T) {
+	ds, err := ReadCSV(strings.NewReader(people), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// El encabezado se conserva en orden, sin la columna objetivo (la última)
+	if got, want := ds.FeatureNames(), []string{"age", "city", "income"}; !slices.Equal(got, want) {
+		t.Errorf("características = %v, se esperaba %v", got, want)
+	}
+	for name, want := range map[string]ColumnType{"age": Numeric, "city": Categorical, "income": Numeric} {
+		if got := ds.Features[ds.Feature(name)].Type; got != want {
+			t.Errorf("%s: tipo %v, se esperaba %v", name, got, want)
+		}
+	}
+
+	// Las celdas faltantes no impiden inferir el tipo y quedan como NaN o ""
+	age := ds.Features[ds.Feature("age")].Numbers
+	if age[0] != 34 || !math.IsNaN(age[1]) {
+		t.Errorf("age = %v", age)
+	}
+	if city := ds.Features[ds.Feature("city")].Strings; !slices.Equal(city, []string{"Lima", "Quito", "", "Lima"}) {
+		t.Errorf("city = %v", city)
+	}
+	if income := ds.Features[ds.Feature("income")].Numbers; !math.IsNaN(income[3]) {
+		t.Errorf("income[3] = %v, se esperaba NaN", income[3])
+	}
+
+	// El objetivo categórico se indexa por sus clases en orden alfabético
+	if !slices.Equal(ds.Classes, []string{"no", "yes"}) || !slices.Equal(ds.Labels(), []float64{1, 0, 1, 0}) {
+		t.Errorf("Classes = %v, Labels = %v", ds.Classes, ds.Labels())
+	}
+}
+
+func TestReadCSVTargetByName(t *testing.T) {
+	ds, err := ReadCSV(strings.NewReader(people), CSVOptions{Target: "income"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ds.FeatureNames(), []string{"age", "city", "bought"}; !slices.Equal(got, want) {
+		t.Errorf("características = %v, se esperaba %v", got, want)
+	}
+	if ds.Target.Name != "income" || ds.Classes != nil || ds.Labels()[0] != 1200.5 {
+		t.Errorf("objetivo %q = %v, clases %v", ds.Target.Name, ds.Labels(), ds.Classes)
+	}
+
+	if _, err := ReadCSV(strings.NewReader(people), CSVOptions{Target: "salary"}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("objetivo inexistente: error = %v, se esperaba ErrUnknownColumn", err)
+	}
+	ds, err = ReadCSV(strings.NewReader(people), CSVOptions{NoTarget: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.Features) != 4 || ds.Labels() != nil {
+		t.Errorf("sin objetivo: %d características, etiquetas %v", len(ds.Features), ds.Labels())
+	}
+}
+
+func TestReadCSVForcedTypesAndMissing(t *testing.T) {
+	ds, err := ReadCSV(strings.NewReader("zip,n,y\n01010,-,1\n20020,3,0\n"), CSVOptions{
+		Types:   map[string]ColumnType{"zip": Categorical},
+		Missing: []string{"-"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Forzado a categórico, el código postal conserva sus ceros a la izquierda
+	if zip := ds.Features[0]; zip.Type != Categorical || zip.Strings[0] != "01010" {
+		t.Errorf("zip = %+v", zip)
+	}
+	if n := ds.Features[1].Numbers; !math.IsNaN(n[0]) || n[1] != 3 {
+		t.Errorf("n = %v", n)
+	}
+}
+
+func TestReadCSVReportsBadCell(t *testing.T) {
+	// "income" es numérica por su tipo forzado, pero la fila 3 de datos (línea 4) no lo es
+	input := "age,income,y\n30,100,0\n40,200,1\n50,mucho,0\n"
+	_, err := ReadCSV(strings.NewReader(input), CSVOptions{Types: map[string]ColumnType{"income": Numeric}})
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("error = %v, se esperaba un *ParseError", err)
+	}
+	if parseErr.Line != 4 || parseErr.Column != "income" || parseErr.Value != "mucho" {
+		t.Errorf("Line, Column, Value = %d, %q, %q; se esperaba 4, \"income\", \"mucho\"", parseErr.Line, parseErr.Column, parseErr.Value)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("el error no envuelve strconv.ErrSyntax: %v", err)
+	}
+	if want := `línea 4, columna "income": valor "mucho"`; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("mensaje = %q, se esperaba que empezara por %q", err, want)
+	}
+
+	if _, err := ReadCSV(strings.NewReader(""), CSVOptions{}); !errors.Is(err, ErrNoColumns) {
+		t.Errorf("CSV vacío: error = %v, se esperaba ErrNoColumns", err)
+	}
+}
+
+func TestDatasetMatrixAndSubset(t *testing.T) {
+	ds, err := ReadCSV(strings.NewReader(people), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.Matrix(); !errors.Is(err, ErrCategoricalData) {
+		t.Errorf("Matrix con city: error = %v, se esperaba ErrCategoricalData", err)
+	}
+	if !ds.DropFeature("city") || ds.DropFeature("city") {
+		t.Error("DropFeature debe quitar city una sola vez")
+	}
+
+	sub := ds.Subset([]int{3, 0})
+	X, err := sub.Matrix()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if X[1][0] != 34 || X[1][1] != 1200.5 || X[0][0] != 28 || !slices.Equal(sub.Labels(), []float64{0, 1}) {
+		t.Errorf("Subset = %v, etiquetas %v", X, sub.Labels())
+	}
+	if !slices.Equal(sub.Classes, ds.Classes) {
+		t.Errorf("Subset perdió las clases: %v", sub.Classes)
+	}
+}
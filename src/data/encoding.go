@@ -0,0 +1,286 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Política para las categorías que no aparecieron al ajustar el codificador
+type UnknownPolicy int
+
+const (
+	UnknownError  UnknownPolicy = iota // Transform devuelve ErrUnknownCategory
+	UnknownIgnore                      // One-hot: todo ceros; ordinal: -1; target: media global
+)
+
+// Errores devueltos por los codificadores
+var (
+	ErrUnknownCategory = errors.New("categoría no vista en el entrenamiento")
+	ErrNotFitted       = errors.New("el transformador no se ha ajustado")
+	ErrNoTarget        = errors.New("el dataset no tiene columna objetivo")
+)
+
+// Transformer aprende una transformación con Fit sobre el conjunto de
+// entrenamiento y la aplica con Transform a cualquier dataset
+type Transformer interface {
+	Kind() string // Nombre usado al serializar
+	Fit(ds *Dataset) error
+	Transform(ds *Dataset) (*Dataset, error)
+}
+
+// Devuelve las columnas categóricas a codificar: las pedidas o, si no hay, todas
+func categoricalColumns(ds *Dataset, columns []string) ([]string, error) {
+	if len(columns) > 0 {
+		for _, name := range columns {
+			j := ds.Feature(name)
+			if j == -1 {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+			}
+			if ds.Features[j].Type != Categorical {
+				return nil, fmt.Errorf("la columna %q no es categórica", name)
+			}
+		}
+		return columns, nil
+	}
+
+	var names []string
+	for _, col := range ds.Features {
+		if col.Type == Categorical {
+			names = append(names, col.Name)
+		}
+	}
+	return names, nil
+}
+
+// Categorías distintas de una columna en orden alfabético, sin la celda faltante
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if v != "" {
+			seen[v] = true
+		}
+	}
+	categories := make([]string, 0, len(seen))
+	for v := range seen {
+		categories = append(categories, v)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Error para la fila i (empezando en 1) con una categoría desconocida
+func unknownCategory(column string, row int, value string) error {
+	return fmt.Errorf("%w: fila %d, columna %q, valor %q", ErrUnknownCategory, row+1, column, value)
+}
+
+// Reemplaza cada columna codificada por las columnas que produce encode
+func replaceColumns(ds *Dataset, encoded map[string]bool, encode func(col Column) ([]Column, error)) (*Dataset, error) {
+	out := &Dataset{Target: ds.Target, Classes: ds.Classes}
+	for _, col := range ds.Features {
+		if !encoded[col.Name] {
+			out.Features = append(out.Features, col)
+			continue
+		}
+		if col.Type != Categorical {
+			return nil, fmt.Errorf("la columna %q no es categórica", col.Name)
+		}
+		cols, err := encode(col)
+		if err != nil {
+			return nil, err
+		}
+		out.Features = append(out.Features, cols...)
+	}
+	return out, nil
+}
+
+// OneHotEncoder crea una columna 0/1 por categoría, llamada "columna=categoría".
+// Una celda faltante produce ceros en todas sus columnas
+type OneHotEncoder struct {
+	Columns    []string // Columnas a codificar; vacío = todas las categóricas
+	Unknown    UnknownPolicy
+	Categories map[string][]string // Aprendido por Fit
+}
+
+func (e *OneHotEncoder) Kind() string { return "onehot" }
+
+// Fit aprende las categorías de cada columna
+func (e *OneHotEncoder) Fit(ds *Dataset) error {
+	columns, err := categoricalColumns(ds, e.Columns)
+	if err != nil {
+		return err
+	}
+	e.Categories = make(map[string][]string, len(columns))
+	for _, name := range columns {
+		e.Categories[name] = uniqueSorted(ds.Features[ds.Feature(name)].Strings)
+	}
+	return nil
+}
+
+// Transform reemplaza cada columna aprendida por sus columnas one-hot
+func (e *OneHotEncoder) Transform(ds *Dataset) (*Dataset, error) {
+	if e.Categories == nil {
+		return nil, ErrNotFitted
+	}
+	encoded := make(map[string]bool, len(e.Categories))
+	for name := range e.Categories {
+		encoded[name] = true
+	}
+
+	return replaceColumns(ds, encoded, func(col Column) ([]Column, error) {
+		categories := e.Categories[col.Name]
+		index := make(map[string]int, len(categories))
+		cols := make([]Column, len(categories))
+		for k, c := range categories {
+			index[c] = k
+			cols[k] = Column{Name: col.Name + "=" + c, Type: Numeric, Numbers: make([]float64, len(col.Strings))}
+		}
+		for i, v := range col.Strings {
+			k, ok := index[v]
+			if v == "" {
+				continue
+			}
+			if !ok {
+				if e.Unknown == UnknownError {
+					return nil, unknownCategory(col.Name, i, v)
+				}
+				continue
+			}
+			cols[k].Numbers[i] = 1
+		}
+		return cols, nil
+	})
+}
+
+// OrdinalEncoder reemplaza cada categoría por su posición en orden alfabético.
+// Una celda faltante queda como NaN
+type OrdinalEncoder struct {
+	Columns    []string // Columnas a codificar; vacío = todas las categóricas
+	Unknown    UnknownPolicy
+	Categories map[string][]string // Aprendido por Fit
+}
+
+func (e *OrdinalEncoder) Kind() string { return "ordinal" }
+
+// Fit aprende las categorías de cada columna
+func (e *OrdinalEncoder) Fit(ds *Dataset) error {
+	columns, err := categoricalColumns(ds, e.Columns)
+	if err != nil {
+		return err
+	}
+	e.Categories = make(map[string][]string, len(columns))
+	for _, name := range columns {
+		e.Categories[name] = uniqueSorted(ds.Features[ds.Feature(name)].Strings)
+	}
+	return nil
+}
+
+// Transform reemplaza cada columna aprendida por el índice de su categoría
+func (e *OrdinalEncoder) Transform(ds *Dataset) (*Dataset, error) {
+	if e.Categories == nil {
+		return nil, ErrNotFitted
+	}
+	encoded := make(map[string]bool, len(e.Categories))
+	for name := range e.Categories {
+		encoded[name] = true
+	}
+
+	return replaceColumns(ds, encoded, func(col Column) ([]Column, error) {
+		index := make(map[string]float64)
+		for k, c := range e.Categories[col.Name] {
+			index[c] = float64(k)
+		}
+		out := Column{Name: col.Name, Type: Numeric, Numbers: make([]float64, len(col.Strings))}
+		for i, v := range col.Strings {
+			code, ok := index[v]
+			if v == "" {
+				code, ok = math.NaN(), true
+			}
+			if !ok {
+				if e.Unknown == UnknownError {
+					return nil, unknownCategory(col.Name, i, v)
+				}
+				code = -1
+			}
+			out.Numbers[i] = code
+		}
+		return []Column{out}, nil
+	})
+}
+
+// TargetEncoder reemplaza cada categoría por la media suavizada del objetivo:
+// (n·media + Smoothing·media global) / (n + Smoothing). Una celda faltante queda como NaN
+type TargetEncoder struct {
+	Columns   []string // Columnas a codificar; vacío = todas las categóricas
+	Unknown   UnknownPolicy
+	Smoothing float64 // Peso de la media global; 0 usa la media de la categoría sin suavizar
+
+	Prior    float64                       // Media global del objetivo, aprendida por Fit
+	Encoding map[string]map[string]float64 // Valor de cada categoría, aprendido por Fit
+}
+
+func (e *TargetEncoder) Kind() string { return "target" }
+
+// Fit aprende la media suavizada del objetivo para cada categoría
+func (e *TargetEncoder) Fit(ds *Dataset) error {
+	y := ds.Labels()
+	if len(y) == 0 || len(y) != ds.Len() {
+		return ErrNoTarget
+	}
+	columns, err := categoricalColumns(ds, e.Columns)
+	if err != nil {
+		return err
+	}
+
+	e.Prior = meanObserved(y)
+
+	e.Encoding = make(map[string]map[string]float64, len(columns))
+	for _, name := range columns {
+		sums := make(map[string]float64)
+		counts := make(map[string]float64)
+		for i, v := range ds.Features[ds.Feature(name)].Strings {
+			if v == "" || math.IsNaN(y[i]) {
+				continue
+			}
+			sums[v] += y[i]
+			counts[v]++
+		}
+		values := make(map[string]float64, len(sums))
+		for c, sum := range sums {
+			values[c] = (sum + e.Smoothing*e.Prior) / (counts[c] + e.Smoothing)
+		}
+		e.Encoding[name] = values
+	}
+	return nil
+}
+
+// Transform reemplaza cada columna aprendida por la media suavizada de su categoría
+func (e *TargetEncoder) Transform(ds *Dataset) (*Dataset, error) {
+	if e.Encoding == nil {
+		return nil, ErrNotFitted
+	}
+	encoded := make(map[string]bool, len(e.Encoding))
+	for name := range e.Encoding {
+		encoded[name] = true
+	}
+
+	return replaceColumns(ds, encoded, func(col Column) ([]Column, error) {
+		values := e.Encoding[col.Name]
+		out := Column{Name: col.Name, Type: Numeric, Numbers: make([]float64, len(col.Strings))}
+		for i, v := range col.Strings {
+			value, ok := values[v]
+			if v == "" {
+				value, ok = math.NaN(), true
+			}
+			if !ok {
+				if e.Unknown == UnknownError {
+					return nil, unknownCategory(col.Name, i, v)
+				}
+				value = e.Prior
+			}
+			out.Numbers[i] = value
+		}
+		return []Column{out}, nil
+	})
+}
@@ -0,0 +1,196 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Dataset con una columna categórica, una numérica y un objetivo 0/1
+func colors(t *testing.T, csv string) *Dataset {
+	t.Helper()
+	ds, err := ReadCSV(strings.NewReader(csv), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+const (
+	colorsTrain = "color,size,y\nred,1,1\nblue,2,0\nred,3,1\ngreen,4,0\n,5,1\nblue,6,1\n"
+	colorsTest  = "color,size,y\ngreen,7,0\nred,8,1\npurple,9,0\n"
+)
+
+// Valores numéricos de la columna con el nombre dado
+func numbers(t *testing.T, ds *Dataset, name string) []float64 {
+	t.Helper()
+	j := ds.Feature(name)
+	if j == -1 {
+		t.Fatalf("falta la columna %q en %v", name, ds.FeatureNames())
+	}
+	return ds.Features[j].Numbers
+}
+
+// Compara admitiendo NaN en las mismas posiciones
+func equalNaN(a, b []float64) bool {
+	return slices.EqualFunc(a, b, func(x, y float64) bool { return x == y || math.IsNaN(x) && math.IsNaN(y) })
+}
+
+func TestOneHotEncoder(t *testing.T) {
+	enc := &OneHotEncoder{}
+	train, err := (&Pipeline{Steps: []Transformer{enc}}).FitTransform(colors(t, colorsTrain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := train.FeatureNames(), []string{"color=blue", "color=green", "color=red", "size"}; !slices.Equal(got, want) {
+		t.Errorf("columnas = %v, se esperaba %v", got, want)
+	}
+	// La celda faltante (fila 4) queda en ceros
+	if got := numbers(t, train, "color=red"); !slices.Equal(got, []float64{1, 0, 1, 0, 0, 0}) {
+		t.Errorf("color=red = %v", got)
+	}
+
+	// La prueba usa las categorías del entrenamiento, aunque no las tenga todas
+	_, err = enc.Transform(colors(t, colorsTest))
+	if !errors.Is(err, ErrUnknownCategory) || !strings.Contains(err.Error(), `fila 3, columna "color", valor "purple"`) {
+		t.Errorf("categoría nueva con UnknownError: %v", err)
+	}
+	enc.Unknown = UnknownIgnore
+	test, err := enc.Transform(colors(t, colorsTest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(test.Features) != 4 || !slices.Equal(numbers(t, test, "color=blue"), []float64{0, 0, 0}) {
+		t.Errorf("prueba = %v", test.Features)
+	}
+	for _, name := range []string{"color=blue", "color=green", "color=red"} {
+		if numbers(t, test, name)[2] != 0 {
+			t.Errorf("%s: la categoría ignorada debe quedar en ceros", name)
+		}
+	}
+}
+
+func TestOrdinalEncoder(t *testing.T) {
+	enc := &OrdinalEncoder{Unknown: UnknownIgnore}
+	if err := enc.Fit(colors(t, colorsTrain)); err != nil {
+		t.Fatal(err)
+	}
+	train, err := enc.Transform(colors(t, colorsTrain))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := numbers(t, train, "color"); !equalNaN(got, []float64{2, 0, 2, 1, math.NaN(), 0}) {
+		t.Errorf("entrenamiento = %v", got)
+	}
+	test, err := enc.Transform(colors(t, colorsTest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := numbers(t, test, "color"); !slices.Equal(got, []float64{1, 2, -1}) {
+		t.Errorf("prueba = %v, se esperaba [1 2 -1]", got)
+	}
+
+	if _, err := (&OrdinalEncoder{}).Transform(colors(t, colorsTest)); !errors.Is(err, ErrNotFitted) {
+		t.Errorf("sin ajustar: error = %v, se esperaba ErrNotFitted", err)
+	}
+	if err := (&OrdinalEncoder{Columns: []string{"size"}}).Fit(colors(t, colorsTrain)); err == nil {
+		t.Error("codificar una columna numérica debe fallar")
+	}
+}
+
+func TestTargetEncoderSmoothing(t *testing.T) {
+	enc := &TargetEncoder{Smoothing: 2, Unknown: UnknownIgnore}
+	if err := enc.Fit(colors(t, colorsTrain)); err != nil {
+		t.Fatal(err)
+	}
+	// Media global 4/6; red: (2 + 2·4/6) / (2 + 2) = 5/6; green: (0 + 4/3) / 3 = 4/9
+	prior := 4.0 / 6
+	if math.Abs(enc.Prior-prior) > 1e-12 {
+		t.Errorf("Prior = %v, se esperaba %v", enc.Prior, prior)
+	}
+	test, err := enc.Transform(colors(t, colorsTest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{4.0 / 9, 5.0 / 6, prior} // La categoría nueva recibe la media global
+	for i, got := range numbers(t, test, "color") {
+		if math.Abs(got-want[i]) > 1e-12 {
+			t.Errorf("fila %d = %v, se esperaba %v", i, got, want[i])
+		}
+	}
+
+	// Sin suavizado cada categoría vale la media de su objetivo
+	raw := &TargetEncoder{}
+	if err := raw.Fit(colors(t, colorsTrain)); err != nil {
+		t.Fatal(err)
+	}
+	if red, blue := raw.Encoding["color"]["red"], raw.Encoding["color"]["blue"]; red != 1 || blue != 0.5 {
+		t.Errorf("red, blue = %v, %v; se esperaba 1, 0.5", red, blue)
+	}
+	if _, err := raw.Transform(colors(t, colorsTest)); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("categoría nueva con UnknownError: %v", err)
+	}
+	noTarget, err := ReadCSV(strings.NewReader("color\nred\n"), CSVOptions{NoTarget: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := raw.Fit(noTarget); !errors.Is(err, ErrNoTarget) {
+		t.Errorf("sin objetivo: error = %v, se esperaba ErrNoTarget", err)
+	}
+}
+
+func TestPipelineSavesEncoders(t *testing.T) {
+	pipeline := &Pipeline{Steps: []Transformer{
+		&TargetEncoder{Columns: []string{"color"}, Smoothing: 1, Unknown: UnknownIgnore},
+	}}
+	if _, err := pipeline.FitTransform(colors(t, colorsTrain)); err != nil {
+		t.Fatal(err)
+	}
+	for _, steps := range [][]Transformer{
+		pipeline.Steps,
+		{fitted(t, &OneHotEncoder{Unknown: UnknownIgnore})},
+		{fitted(t, &OrdinalEncoder{Unknown: UnknownIgnore})},
+	} {
+		p := &Pipeline{Steps: steps}
+		var buf bytes.Buffer
+		if err := p.Save(&buf); err != nil {
+			t.Fatal(err)
+		}
+		loaded := &Pipeline{}
+		if err := loaded.Load(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(loaded.Steps, p.Steps) {
+			t.Errorf("%s: pasos cargados %+v, se esperaba %+v", steps[0].Kind(), loaded.Steps[0], p.Steps[0])
+		}
+		// El pipeline cargado transforma la prueba con lo aprendido en el entrenamiento
+		want, err := p.Transform(colors(t, colorsTest))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := loaded.Transform(colors(t, colorsTest))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: el pipeline cargado transforma distinto", steps[0].Kind())
+		}
+	}
+
+	if err := (&Pipeline{}).Load(strings.NewReader(`{"version":1,"steps":[{"kind":"hash","params":{}}]}`)); err == nil {
+		t.Error("un transformador desconocido debe fallar al cargar")
+	}
+}
+
+// Transformador ajustado con el conjunto de entrenamiento
+func fitted(t *testing.T, step Transformer) Transformer {
+	t.Helper()
+	if err := step.Fit(colors(t, colorsTrain)); err != nil {
+		t.Fatal(err)
+	}
+	return step
+}
@@ -0,0 +1,571 @@
+package data
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Textos que ReadCSV considera celdas faltantes si no se indican otros
+var MissingTokens = []string{"", "NA", "N/A", "NaN", "nan", "null", "?"}
+
+// Error devuelto al imputar una columna sin ningún valor observado
+var ErrNoObserved = errors.New("la columna no tiene valores observados")
+
+// Sufijo de las columnas indicadoras de valores faltantes
+const indicatorSuffix = "_missing"
+
+func missingSet(tokens []string) map[string]bool {
+	set := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		set[t] = true
+	}
+	return set
+}
+
+// IsMissing indica si la celda i de la columna falta (NaN en numéricas, "" en categóricas)
+func (c Column) IsMissing(i int) bool {
+	if c.Type == Categorical {
+		return c.Strings[i] == ""
+	}
+	return math.IsNaN(c.Numbers[i])
+}
+
+// MissingCount devuelve el número de celdas faltantes de la columna
+func (c Column) MissingCount() int {
+	count := 0
+	for i := 0; i < c.len(); i++ {
+		if c.IsMissing(i) {
+			count++
+		}
+	}
+	return count
+}
+
+// MissingMask devuelve, por fila y característica, si la celda falta
+func (ds *Dataset) MissingMask() [][]bool {
+	mask := make([][]bool, ds.Len())
+	for i := range mask {
+		mask[i] = make([]bool, len(ds.Features))
+		for j, col := range ds.Features {
+			mask[i][j] = col.IsMissing(i)
+		}
+	}
+	return mask
+}
+
+// Media de los valores observados; NaN si no hay ninguno
+func meanObserved(values []float64) float64 {
+	sum, n := 0.0, 0
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// Mediana de los valores observados; NaN si no hay ninguno
+func medianObserved(values []float64) float64 {
+	observed := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			observed = append(observed, v)
+		}
+	}
+	if len(observed) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(observed)
+	mid := len(observed) / 2
+	if len(observed)%2 == 0 {
+		return (observed[mid-1] + observed[mid]) / 2
+	}
+	return observed[mid]
+}
+
+// Valor numérico observado más frecuente (el menor en caso de empate); NaN si no hay ninguno
+func mostFrequentNumber(values []float64) float64 {
+	counts := make(map[float64]int)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			counts[v]++
+		}
+	}
+	best, bestCount := math.NaN(), 0
+	for v, n := range counts {
+		if n > bestCount || (n == bestCount && v < best) {
+			best, bestCount = v, n
+		}
+	}
+	return best
+}
+
+// Categoría observada más frecuente (la primera alfabéticamente en caso de empate)
+func mostFrequentString(values []string) string {
+	counts := make(map[string]int)
+	for _, v := range values {
+		if v != "" {
+			counts[v]++
+		}
+	}
+	best, bestCount := "", 0
+	for v, n := range counts {
+		if n > bestCount || (n == bestCount && v < best) {
+			best, bestCount = v, n
+		}
+	}
+	return best
+}
+
+// Devuelve las columnas pedidas (comprobando que existan) o, si no hay, todas
+func selectColumns(ds *Dataset, columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return ds.FeatureNames(), nil
+	}
+	for _, name := range columns {
+		if ds.Feature(name) == -1 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+	}
+	return columns, nil
+}
+
+// Columnas que tienen algún valor faltante
+func columnsWithMissing(ds *Dataset, columns []string) []string {
+	var names []string
+	for _, name := range columns {
+		if ds.Features[ds.Feature(name)].MissingCount() > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Añade al final una columna 0/1 "columna_missing" por cada columna indicada.
+// Las indicadoras se calculan sobre el dataset original, antes de imputar
+func appendIndicators(out, original *Dataset, indicators []string) error {
+	for _, name := range indicators {
+		j := original.Feature(name)
+		if j == -1 {
+			return fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+		col := original.Features[j]
+		indicator := Column{Name: name + indicatorSuffix, Type: Numeric, Numbers: make([]float64, col.len())}
+		for i := range indicator.Numbers {
+			if col.IsMissing(i) {
+				indicator.Numbers[i] = 1
+			}
+		}
+		out.Features = append(out.Features, indicator)
+	}
+	return nil
+}
+
+// Estrategia de imputación de SimpleImputer
+type ImputeStrategy int
+
+const (
+	ImputeMean         ImputeStrategy = iota // Media de la columna
+	ImputeMedian                             // Mediana de la columna
+	ImputeMostFrequent                       // Valor más frecuente
+	ImputeConstant                           // FillValue o FillString
+)
+
+func (s ImputeStrategy) String() string {
+	switch s {
+	case ImputeMedian:
+		return "median"
+	case ImputeMostFrequent:
+		return "most_frequent"
+	case ImputeConstant:
+		return "constant"
+	}
+	return "mean"
+}
+
+// SimpleImputer rellena las celdas faltantes con un valor por columna aprendido
+// en el entrenamiento. En columnas categóricas la media y la mediana se
+// sustituyen por la categoría más frecuente
+type SimpleImputer struct {
+	Columns      []string // Columnas a imputar; vacío = todas
+	Strategy     ImputeStrategy
+	FillValue    float64 // Relleno de ImputeConstant en columnas numéricas
+	FillString   string  // Relleno de ImputeConstant en columnas categóricas; vacío = "missing"
+	AddIndicator bool    // Añade "columna_missing" por cada columna con faltantes en el entrenamiento
+
+	Numbers    map[string]float64 // Relleno de cada columna numérica, aprendido por Fit
+	Strings    map[string]string  // Relleno de cada columna categórica, aprendido por Fit
+	Indicators []string           // Columnas con indicadora, aprendidas por Fit
+}
+
+func (m *SimpleImputer) Kind() string { return "impute" }
+
+// Fit aprende el valor de relleno de cada columna
+func (m *SimpleImputer) Fit(ds *Dataset) error {
+	columns, err := selectColumns(ds, m.Columns)
+	if err != nil {
+		return err
+	}
+
+	m.Numbers = make(map[string]float64)
+	m.Strings = make(map[string]string)
+	for _, name := range columns {
+		col := ds.Features[ds.Feature(name)]
+		if col.Type == Categorical {
+			fill := mostFrequentString(col.Strings)
+			if m.Strategy == ImputeConstant {
+				fill = m.FillString
+				if fill == "" {
+					fill = "missing"
+				}
+			}
+			if fill == "" {
+				return fmt.Errorf("%w: %q", ErrNoObserved, name)
+			}
+			m.Strings[name] = fill
+			continue
+		}
+
+		var fill float64
+		switch m.Strategy {
+		case ImputeMean:
+			fill = meanObserved(col.Numbers)
+		case ImputeMedian:
+			fill = medianObserved(col.Numbers)
+		case ImputeMostFrequent:
+			fill = mostFrequentNumber(col.Numbers)
+		case ImputeConstant:
+			fill = m.FillValue
+		default:
+			return fmt.Errorf("estrategia de imputación desconocida: %d", m.Strategy)
+		}
+		if math.IsNaN(fill) {
+			return fmt.Errorf("%w: %q", ErrNoObserved, name)
+		}
+		m.Numbers[name] = fill
+	}
+
+	m.Indicators = nil
+	if m.AddIndicator {
+		m.Indicators = columnsWithMissing(ds, columns)
+	}
+	return nil
+}
+
+// Transform rellena las celdas faltantes y añade las columnas indicadoras
+func (m *SimpleImputer) Transform(ds *Dataset) (*Dataset, error) {
+	if m.Numbers == nil && m.Strings == nil {
+		return nil, ErrNotFitted
+	}
+
+	out := &Dataset{Target: ds.Target, Classes: ds.Classes}
+	for _, col := range ds.Features {
+		if fill, ok := m.Numbers[col.Name]; ok && col.Type == Numeric {
+			filled := Column{Name: col.Name, Type: Numeric, Numbers: make([]float64, len(col.Numbers))}
+			for i, v := range col.Numbers {
+				if math.IsNaN(v) {
+					v = fill
+				}
+				filled.Numbers[i] = v
+			}
+			col = filled
+		} else if fill, ok := m.Strings[col.Name]; ok && col.Type == Categorical {
+			filled := Column{Name: col.Name, Type: Categorical, Strings: make([]string, len(col.Strings))}
+			for i, v := range col.Strings {
+				if v == "" {
+					v = fill
+				}
+				filled.Strings[i] = v
+			}
+			col = filled
+		}
+		out.Features = append(out.Features, col)
+	}
+
+	if err := appendIndicators(out, ds, m.Indicators); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// KNNImputer rellena cada celda faltante con los K vecinos más cercanos del
+// entrenamiento que tienen esa columna observada: la media en columnas
+// numéricas y la categoría más votada en categóricas. La distancia es la
+// euclídea sobre las columnas numéricas observadas en ambas filas, reescalada
+// por la fracción de columnas usadas
+type KNNImputer struct {
+	Columns      []string // Columnas a imputar; vacío = todas
+	K            int      // Número de vecinos; 0 usa 5
+	AddIndicator bool     // Añade "columna_missing" por cada columna con faltantes en el entrenamiento
+
+	Features   []string            // Columnas numéricas usadas en la distancia, aprendidas por Fit
+	Rows       nanMatrix           // Filas de entrenamiento sobre Features, con NaN en las faltantes
+	Categories map[string][]string // Valores de entrenamiento de las columnas categóricas a imputar
+	Indicators []string            // Columnas con indicadora, aprendidas por Fit
+}
+
+func (m *KNNImputer) Kind() string { return "knn_impute" }
+
+// Fit guarda las filas de entrenamiento que servirán de vecinos
+func (m *KNNImputer) Fit(ds *Dataset) error {
+	columns, err := selectColumns(ds, m.Columns)
+	if err != nil {
+		return err
+	}
+
+	m.Features = nil
+	for _, col := range ds.Features {
+		if col.Type == Numeric {
+			m.Features = append(m.Features, col.Name)
+		}
+	}
+	if m.Rows, err = numericRows(ds, m.Features); err != nil {
+		return err
+	}
+
+	m.Categories = make(map[string][]string)
+	for _, name := range columns {
+		col := ds.Features[ds.Feature(name)]
+		if col.MissingCount() == ds.Len() {
+			return fmt.Errorf("%w: %q", ErrNoObserved, name)
+		}
+		if col.Type == Categorical {
+			m.Categories[name] = col.Strings
+		}
+	}
+
+	m.Indicators = nil
+	if m.AddIndicator {
+		m.Indicators = columnsWithMissing(ds, columns)
+	}
+	return nil
+}
+
+// Indica si la columna se imputa
+func (m *KNNImputer) imputes(col Column) bool {
+	if col.Type == Categorical {
+		_, ok := m.Categories[col.Name]
+		return ok
+	}
+	return indexOf(m.Features, col.Name) != -1 && (len(m.Columns) == 0 || indexOf(m.Columns, col.Name) != -1)
+}
+
+// Filas del dataset restringidas a las columnas indicadas
+func numericRows(ds *Dataset, names []string) ([][]float64, error) {
+	cols := make([][]float64, len(names))
+	for k, name := range names {
+		j := ds.Feature(name)
+		if j == -1 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+		if ds.Features[j].Type != Numeric {
+			return nil, fmt.Errorf("la columna %q no es numérica", name)
+		}
+		cols[k] = ds.Features[j].Numbers
+	}
+
+	rows := make([][]float64, ds.Len())
+	for i := range rows {
+		rows[i] = make([]float64, len(names))
+		for k := range names {
+			rows[i][k] = cols[k][i]
+		}
+	}
+	return rows, nil
+}
+
+// Distancia euclídea sobre las posiciones observadas en ambas filas
+func nanEuclidean(a, b []float64) float64 {
+	sum, present := 0.0, 0
+	for k := range a {
+		if math.IsNaN(a[k]) || math.IsNaN(b[k]) {
+			continue
+		}
+		d := a[k] - b[k]
+		sum += d * d
+		present++
+	}
+	if present == 0 {
+		return math.Inf(1)
+	}
+	return math.Sqrt(sum * float64(len(a)) / float64(present))
+}
+
+// Transform rellena las celdas faltantes de las columnas imputadas y añade las indicadoras
+func (m *KNNImputer) Transform(ds *Dataset) (*Dataset, error) {
+	if m.Categories == nil {
+		return nil, ErrNotFitted
+	}
+	rows, err := numericRows(ds, m.Features)
+	if err != nil {
+		return nil, err
+	}
+	k := m.K
+	if k <= 0 {
+		k = 5
+	}
+
+	out := &Dataset{Target: ds.Target, Classes: ds.Classes}
+	imputed := make([]bool, len(ds.Features))
+	for j, col := range ds.Features {
+		if m.imputes(col) && col.MissingCount() > 0 {
+			imputed[j] = true
+			col.Numbers, col.Strings = slices.Clone(col.Numbers), slices.Clone(col.Strings)
+		}
+		out.Features = append(out.Features, col)
+	}
+
+	// Las distancias de una fila se calculan una vez para todas sus celdas
+	// faltantes; de cada columna solo se guardan sus k vecinos
+	distances := make([]float64, len(m.Rows))
+	for i := range rows {
+		measured := false
+		for j, col := range out.Features {
+			if !imputed[j] || !col.IsMissing(i) {
+				continue
+			}
+			if !measured {
+				for r, ref := range m.Rows {
+					distances[r] = nanEuclidean(rows[i], ref)
+				}
+				measured = true
+			}
+			if col.Type == Categorical {
+				values := m.Categories[col.Name]
+				near := nearest(distances, k, func(r int) bool { return values[r] != "" })
+				col.Strings[i] = neighbourVote(near, values)
+			} else {
+				f := indexOf(m.Features, col.Name)
+				near := nearest(distances, k, func(r int) bool { return !math.IsNaN(m.Rows[r][f]) })
+				col.Numbers[i] = m.neighbourMean(near, f)
+			}
+		}
+	}
+
+	if err := appendIndicators(out, ds, m.Indicators); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Fila de entrenamiento a cierta distancia de la fila imputada
+type neighbour struct {
+	row      int
+	distance float64
+}
+
+// Indica si a está más lejos que b; a igual distancia, la fila posterior
+func farther(a, b neighbour) bool {
+	if a.distance != b.distance {
+		return a.distance > b.distance
+	}
+	return a.row > b.row
+}
+
+// Montículo con el vecino más lejano en la raíz
+type neighbourHeap []neighbour
+
+func (h neighbourHeap) Len() int           { return len(h) }
+func (h neighbourHeap) Less(a, b int) bool { return farther(h[a], h[b]) }
+func (h neighbourHeap) Swap(a, b int)      { h[a], h[b] = h[b], h[a] }
+func (h *neighbourHeap) Push(x any)        { *h = append(*h, x.(neighbour)) }
+func (h *neighbourHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// Los k vecinos más cercanos, del más cercano al más lejano, entre las filas
+// a distancia finita en las que observed es cierto. El montículo guarda solo k
+// filas: cada fila más cercana que la raíz la reemplaza
+func nearest(distances []float64, k int, observed func(r int) bool) []neighbour {
+	h := make(neighbourHeap, 0, k)
+	for r, d := range distances {
+		if math.IsInf(d, 1) || !observed(r) {
+			continue
+		}
+		n := neighbour{r, d}
+		if len(h) < k {
+			heap.Push(&h, n)
+		} else if farther(h[0], n) {
+			h[0] = n
+			heap.Fix(&h, 0)
+		}
+	}
+	sort.Slice(h, func(a, b int) bool { return farther(h[b], h[a]) })
+	return h
+}
+
+// Media de la columna f en los vecinos; sin ninguno se usa la media de la columna
+func (m *KNNImputer) neighbourMean(near []neighbour, f int) float64 {
+	if len(near) > 0 {
+		sum := 0.0
+		for _, n := range near {
+			sum += m.Rows[n.row][f]
+		}
+		return sum / float64(len(near))
+	}
+
+	column := make([]float64, len(m.Rows))
+	for r, ref := range m.Rows {
+		column[r] = ref[f]
+	}
+	return meanObserved(column)
+}
+
+// Categoría más votada entre los vecinos; sin ninguno se usa la más frecuente
+func neighbourVote(near []neighbour, values []string) string {
+	if len(near) == 0 {
+		return mostFrequentString(values)
+	}
+	votes := make([]string, len(near))
+	for i, n := range near {
+		votes[i] = values[n.row]
+	}
+	return mostFrequentString(votes)
+}
+
+// Matriz que se serializa en JSON con null en lugar de NaN
+type nanMatrix [][]float64
+
+func (m nanMatrix) MarshalJSON() ([]byte, error) {
+	rows := make([][]*float64, len(m))
+	for i, row := range m {
+		rows[i] = make([]*float64, len(row))
+		for j := range row {
+			if !math.IsNaN(row[j]) {
+				rows[i][j] = &row[j]
+			}
+		}
+	}
+	return json.Marshal(rows)
+}
+
+func (m *nanMatrix) UnmarshalJSON(b []byte) error {
+	var rows [][]*float64
+	if err := json.Unmarshal(b, &rows); err != nil {
+		return err
+	}
+	*m = make(nanMatrix, len(rows))
+	for i, row := range rows {
+		(*m)[i] = make([]float64, len(row))
+		for j, v := range row {
+			if v == nil {
+				(*m)[i][j] = math.NaN()
+			} else {
+				(*m)[i][j] = *v
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package data
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Lee un CSV sin columna objetivo
+func table(t *testing.T, csv string) *Dataset {
+	t.Helper()
+	ds, err := ReadCSV(strings.NewReader(csv), CSVOptions{NoTarget: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+// Entrenamiento con faltantes en a, b y c
+const incomplete = "a,b,c\n1,10,x\n2,NA,x\nNA,30,y\n4,30,\n8,NA,y\n"
+
+func TestSimpleImputerStrategies(t *testing.T) {
+	tests := []struct {
+		imputer SimpleImputer
+		a, b    float64
+		c       string
+	}{
+		{SimpleImputer{Strategy: ImputeMean}, 3.75, 70.0 / 3, "x"},
+		{SimpleImputer{Strategy: ImputeMedian}, 3, 30, "x"},
+		// En a cada valor aparece una vez: gana el menor; en c empatan x e y: gana x
+		{SimpleImputer{Strategy: ImputeMostFrequent}, 1, 30, "x"},
+		{SimpleImputer{Strategy: ImputeConstant, FillValue: -1}, -1, -1, "missing"},
+		{SimpleImputer{Strategy: ImputeConstant, FillString: "?"}, 0, 0, "?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.imputer.Strategy.String(), func(t *testing.T) {
+			m := tt.imputer
+			ds := table(t, incomplete)
+			if err := m.Fit(ds); err != nil {
+				t.Fatal(err)
+			}
+			out, err := m.Transform(ds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a := numbers(t, out, "a"); a[2] != tt.a || a[0] != 1 {
+				t.Errorf("a = %v, se esperaba %v en la fila 2", a, tt.a)
+			}
+			if b := numbers(t, out, "b"); math.Abs(b[1]-tt.b) > 1e-12 || b[4] != b[1] {
+				t.Errorf("b = %v, se esperaba %v en las filas 1 y 4", b, tt.b)
+			}
+			if c := out.Features[out.Feature("c")].Strings; c[3] != tt.c || c[2] != "y" {
+				t.Errorf("c = %v, se esperaba %q en la fila 3", c, tt.c)
+			}
+			if out.Features[0].MissingCount()+out.Features[1].MissingCount()+out.Features[2].MissingCount() != 0 {
+				t.Error("quedaron celdas faltantes")
+			}
+			// Transform no modifica el dataset de entrada
+			if !math.IsNaN(ds.Features[0].Numbers[2]) {
+				t.Error("Transform modificó la entrada")
+			}
+		})
+	}
+}
+
+func TestSimpleImputerErrors(t *testing.T) {
+	empty := table(t, "a,b\nNA,1\nNA,2\n")
+	if err := (&SimpleImputer{}).Fit(empty); !errors.Is(err, ErrNoObserved) {
+		t.Errorf("columna sin observados: error = %v, se esperaba ErrNoObserved", err)
+	}
+	if err := (&SimpleImputer{Columns: []string{"z"}}).Fit(empty); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("columna inexistente: error = %v, se esperaba ErrUnknownColumn", err)
+	}
+	if _, err := (&SimpleImputer{}).Transform(empty); !errors.Is(err, ErrNotFitted) {
+		t.Errorf("sin ajustar: error = %v, se esperaba ErrNotFitted", err)
+	}
+}
+
+func TestMissingIndicators(t *testing.T) {
+	for _, m := range []Transformer{
+		&SimpleImputer{Columns: []string{"a", "c"}, AddIndicator: true},
+		&KNNImputer{Columns: []string{"a", "c"}, AddIndicator: true},
+	} {
+		if err := m.Fit(table(t, incomplete)); err != nil {
+			t.Fatal(err)
+		}
+		out, err := m.Transform(table(t, incomplete))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Solo las columnas imputadas con faltantes en el entrenamiento tienen indicadora
+		if got, want := out.FeatureNames(), []string{"a", "b", "c", "a_missing", "c_missing"}; !slices.Equal(got, want) {
+			t.Errorf("%s: columnas = %v, se esperaba %v", m.Kind(), got, want)
+		}
+		if got := numbers(t, out, "a_missing"); !slices.Equal(got, []float64{0, 0, 1, 0, 0}) {
+			t.Errorf("%s: a_missing = %v", m.Kind(), got)
+		}
+		if got := numbers(t, out, "c_missing"); !slices.Equal(got, []float64{0, 0, 0, 1, 0}) {
+			t.Errorf("%s: c_missing = %v", m.Kind(), got)
+		}
+		// b no se imputa
+		if b := numbers(t, out, "b"); !math.IsNaN(b[1]) {
+			t.Errorf("%s: b se imputó: %v", m.Kind(), b)
+		}
+
+		// Las indicadoras se aprenden en Fit: la prueba sin faltantes las conserva en cero
+		test, err := m.Transform(table(t, "a,b,c\n5,5,x\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(test.Features) != 5 || numbers(t, test, "a_missing")[0] != 0 {
+			t.Errorf("%s: prueba = %v", m.Kind(), test.FeatureNames())
+		}
+	}
+}
+
+// Vecinos: dos grupos en torno a (0, 0) y (10, 10); la fila 5 no tiene a
+const neighbourhood = "a,b,c\n0,0,p\n1,0,p\n0,1,q\n10,10,r\n11,10,r\nNA,10,s\n"
+
+func TestKNNImputer(t *testing.T) {
+	tests := []struct {
+		name string
+		k    int
+		row  string
+		a    float64
+		c    string
+	}{
+		// Solo cuenta b: las filas 0, 1 y 2 empatan y desempata el orden de las filas
+		{"empate", 2, "NA,0.5,", 0.5, "p"},
+		{"empate k=3", 3, "NA,0.5,", 1.0 / 3, "p"},
+		// Las filas 3, 4 y 5 están a distancia 0, pero la 5 no tiene a observada
+		{"vecino sin la columna", 2, "NA,10,", 10.5, "r"},
+		// Sin columnas en común todos están a distancia infinita: media y moda de la columna
+		{"sin distancia", 2, "NA,NA,", 4.4, "p"},
+	}
+	m := &KNNImputer{}
+	if err := m.Fit(table(t, neighbourhood)); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.K = tt.k
+			out, err := m.Transform(table(t, "a,b,c\n"+tt.row+"\n3,3,q\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if a := numbers(t, out, "a"); math.Abs(a[0]-tt.a) > 1e-12 || a[1] != 3 {
+				t.Errorf("a = %v, se esperaba %v", a, tt.a)
+			}
+			if c := out.Features[out.Feature("c")].Strings; c[0] != tt.c {
+				t.Errorf("c = %q, se esperaba %q", c[0], tt.c)
+			}
+		})
+	}
+
+	if err := (&KNNImputer{}).Fit(table(t, "a,b\nNA,1\nNA,2\n")); !errors.Is(err, ErrNoObserved) {
+		t.Errorf("columna sin observados: error = %v, se esperaba ErrNoObserved", err)
+	}
+}
+
+func TestNearestKeepsKClosest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for range 50 {
+		// Distancias con empates e infinitos, y filas sin la columna
+		distances := make([]float64, 40)
+		for r := range distances {
+			distances[r] = float64(rng.Intn(8))
+			if rng.Intn(10) == 0 {
+				distances[r] = math.Inf(1)
+			}
+		}
+		observed := func(r int) bool { return r%3 != 0 }
+		k := 1 + rng.Intn(10)
+
+		// Referencia: ordenar todas las filas y tomar las k primeras válidas
+		order := make([]int, len(distances))
+		for r := range order {
+			order[r] = r
+		}
+		sort.SliceStable(order, func(a, b int) bool { return distances[order[a]] < distances[order[b]] })
+		var want []int
+		for _, r := range order {
+			if len(want) < k && observed(r) && !math.IsInf(distances[r], 1) {
+				want = append(want, r)
+			}
+		}
+
+		var got []int
+		for _, n := range nearest(distances, k, observed) {
+			got = append(got, n.row)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("k = %d: vecinos %v, se esperaba %v", k, got, want)
+		}
+	}
+}
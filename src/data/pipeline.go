@@ -0,0 +1,138 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Versión actual del formato de serialización del pipeline
+const PipelineVersion = 1
+
+// Fábricas de transformadores vacíos por tipo, usadas al cargar un pipeline
+var transformers = map[string]func() Transformer{
+	"onehot":     func() Transformer { return &OneHotEncoder{} },
+	"ordinal":    func() Transformer { return &OrdinalEncoder{} },
+	"target":     func() Transformer { return &TargetEncoder{} },
+	"impute":     func() Transformer { return &SimpleImputer{} },
+	"knn_impute": func() Transformer { return &KNNImputer{} },
+	"standard":   func() Transformer { return &StandardScaler{} },
+	"minmax":     func() Transformer { return &MinMaxScaler{} },
+	"robust":     func() Transformer { return &RobustScaler{} },
+}
+
+// Pipeline aplica una secuencia de transformadores en orden. Se ajusta con el
+// conjunto de entrenamiento y se guarda junto al modelo para repetir la misma
+// transformación al predecir
+type Pipeline struct {
+	Steps []Transformer
+}
+
+// Fit ajusta cada paso con la salida del paso anterior
+func (p *Pipeline) Fit(ds *Dataset) error {
+	_, err := p.FitTransform(ds)
+	return err
+}
+
+// FitTransform ajusta cada paso y devuelve el dataset transformado
+func (p *Pipeline) FitTransform(ds *Dataset) (*Dataset, error) {
+	for _, step := range p.Steps {
+		if err := step.Fit(ds); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.Kind(), err)
+		}
+		var err error
+		if ds, err = step.Transform(ds); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.Kind(), err)
+		}
+	}
+	return ds, nil
+}
+
+// Transform aplica todos los pasos ya ajustados
+func (p *Pipeline) Transform(ds *Dataset) (*Dataset, error) {
+	for _, step := range p.Steps {
+		var err error
+		if ds, err = step.Transform(ds); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.Kind(), err)
+		}
+	}
+	return ds, nil
+}
+
+// InverseTransform deshace los pasos en orden inverso; todos deben ser InverseTransformer
+func (p *Pipeline) InverseTransform(ds *Dataset) (*Dataset, error) {
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		step, ok := p.Steps[i].(InverseTransformer)
+		if !ok {
+			return nil, fmt.Errorf("%s: el paso no tiene transformación inversa", p.Steps[i].Kind())
+		}
+		var err error
+		if ds, err = step.InverseTransform(ds); err != nil {
+			return nil, fmt.Errorf("%s: %w", step.Kind(), err)
+		}
+	}
+	return ds, nil
+}
+
+// Clone devuelve una copia independiente del pipeline y de sus pasos
+func (p *Pipeline) Clone() (*Pipeline, error) {
+	var buf bytes.Buffer
+	if err := p.Save(&buf); err != nil {
+		return nil, err
+	}
+	clone := &Pipeline{}
+	if err := clone.Load(&buf); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// Documento JSON de un pipeline
+type pipelineDoc struct {
+	Version int       `json:"version"`
+	Steps   []stepDoc `json:"steps"`
+}
+
+type stepDoc struct {
+	Kind   string          `json:"kind"`
+	Params json.RawMessage `json:"params"`
+}
+
+// Save escribe el pipeline ajustado en JSON
+func (p *Pipeline) Save(w io.Writer) error {
+	doc := pipelineDoc{Version: PipelineVersion, Steps: make([]stepDoc, len(p.Steps))}
+	for i, step := range p.Steps {
+		params, err := json.Marshal(step)
+		if err != nil {
+			return err
+		}
+		doc.Steps[i] = stepDoc{Kind: step.Kind(), Params: params}
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// Load lee un pipeline escrito con Save
+func (p *Pipeline) Load(r io.Reader) error {
+	var doc pipelineDoc
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	if doc.Version < 1 || doc.Version > PipelineVersion {
+		return fmt.Errorf("versión de pipeline no soportada: %d", doc.Version)
+	}
+
+	steps := make([]Transformer, len(doc.Steps))
+	for i, sd := range doc.Steps {
+		factory, ok := transformers[sd.Kind]
+		if !ok {
+			return fmt.Errorf("transformador desconocido %q", sd.Kind)
+		}
+		steps[i] = factory()
+		if err := json.Unmarshal(sd.Params, steps[i]); err != nil {
+			return fmt.Errorf("%s: %w", sd.Kind, err)
+		}
+	}
+	p.Steps = steps
+	return nil
+}
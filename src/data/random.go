@@ -0,0 +1,30 @@
+package data
+
+import "math/rand"
+
+// Semilla usada por los modelos y divisiones cuando no se indica otra
+const DefaultSeed int64 = 42
+
+// NewRand crea un generador propio con la semilla dada, independiente del global de math/rand
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// DeriveSeeds extrae n semillas de rng, una por goroutine. Cada goroutine crea
+// su propio generador con NewRand, de modo que el resultado no depende del
+// orden en que se ejecuten
+func DeriveSeeds(rng *rand.Rand, n int) []int64 {
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	return seeds
+}
+
+// Devuelve rng o, si es nil, un generador con DefaultSeed
+func orDefault(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return NewRand(DefaultSeed)
+	}
+	return rng
+}
@@ -0,0 +1,36 @@
+package data
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitDataIsSeeded(t *testing.T) {
+	X, y := imbalanced()
+	trainA, testA, _, _, err := SplitData(X, y, 0.75, NewRand(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trainB, testB, _, _, _ := SplitData(X, y, 0.75, NewRand(7))
+	if len(trainA) != 30 || len(testA) != 10 {
+		t.Fatalf("tamaños = %d, %d; se esperaba 30, 10", len(trainA), len(testA))
+	}
+	for i := range trainA {
+		if trainA[i][0] != trainB[i][0] {
+			t.Fatal("la misma semilla debe dar la misma división")
+		}
+	}
+	for i := range testA {
+		if testA[i][0] != testB[i][0] {
+			t.Fatal("la misma semilla debe dar la misma división")
+		}
+	}
+}
+
+func TestDeriveSeedsIsDeterministic(t *testing.T) {
+	a := DeriveSeeds(NewRand(DefaultSeed), 4)
+	b := DeriveSeeds(NewRand(DefaultSeed), 4)
+	if !slices.Equal(a, b) {
+		t.Errorf("DeriveSeeds = %v y %v con la misma semilla", a, b)
+	}
+}
@@ -0,0 +1,304 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// InverseTransformer es un transformador que puede deshacer su transformación
+type InverseTransformer interface {
+	Transformer
+	InverseTransform(ds *Dataset) (*Dataset, error)
+}
+
+// Devuelve las columnas numéricas a escalar: las pedidas o, si no hay, todas
+func numericColumns(ds *Dataset, columns []string) ([]string, error) {
+	if len(columns) > 0 {
+		for _, name := range columns {
+			j := ds.Feature(name)
+			if j == -1 {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+			}
+			if ds.Features[j].Type != Numeric {
+				return nil, fmt.Errorf("la columna %q no es numérica", name)
+			}
+		}
+		return columns, nil
+	}
+
+	var names []string
+	for _, col := range ds.Features {
+		if col.Type == Numeric {
+			names = append(names, col.Name)
+		}
+	}
+	return names, nil
+}
+
+// Calcula dos estadísticos por columna con stat. En modo concurrente reparte
+// las columnas entre GOMAXPROCS goroutines
+func fitColumns(ds *Dataset, names []string, concurrent bool, stat func(values []float64) (float64, float64)) (map[string]float64, map[string]float64) {
+	first := make([]float64, len(names))
+	second := make([]float64, len(names))
+	fit := func(k int) {
+		first[k], second[k] = stat(ds.Features[ds.Feature(names[k])].Numbers)
+	}
+
+	if concurrent {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := range jobs {
+					fit(k)
+				}
+			}()
+		}
+		for k := range names {
+			jobs <- k
+		}
+		close(jobs)
+		wg.Wait()
+	} else {
+		for k := range names {
+			fit(k)
+		}
+	}
+
+	a := make(map[string]float64, len(names))
+	b := make(map[string]float64, len(names))
+	for k, name := range names {
+		a[name], b[name] = first[k], second[k]
+	}
+	return a, b
+}
+
+// Aplica x' = (x - center) / scale (o su inversa) a las columnas aprendidas.
+// Los valores faltantes siguen siendo NaN
+func scaleColumns(ds *Dataset, center, scale map[string]float64, inverse bool) (*Dataset, error) {
+	out := &Dataset{Target: ds.Target, Classes: ds.Classes, Features: make([]Column, len(ds.Features))}
+	copy(out.Features, ds.Features)
+	for name := range center {
+		j := ds.Feature(name)
+		if j == -1 {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+		}
+		col := ds.Features[j]
+		if col.Type != Numeric {
+			return nil, fmt.Errorf("la columna %q no es numérica", name)
+		}
+
+		c, s := center[name], scale[name]
+		scaled := Column{Name: col.Name, Type: Numeric, Numbers: make([]float64, len(col.Numbers))}
+		for i, v := range col.Numbers {
+			if inverse {
+				scaled.Numbers[i] = v*s + c
+			} else {
+				scaled.Numbers[i] = (v - c) / s
+			}
+		}
+		out.Features[j] = scaled
+	}
+	return out, nil
+}
+
+// Una escala nula (columna constante) o indefinida se reemplaza por 1
+func safeScale(scale float64) float64 {
+	if scale == 0 || math.IsNaN(scale) {
+		return 1
+	}
+	return scale
+}
+
+// Comprueba que ninguna columna escalada esté completamente vacía
+func checkObserved(center map[string]float64) error {
+	for name, c := range center {
+		if math.IsNaN(c) {
+			return fmt.Errorf("%w: %q", ErrNoObserved, name)
+		}
+	}
+	return nil
+}
+
+// StandardScaler centra cada columna en su media y la divide por su desviación estándar
+type StandardScaler struct {
+	Columns    []string // Columnas a escalar; vacío = todas las numéricas
+	Concurrent bool     // Ajusta las columnas en paralelo
+
+	Mean map[string]float64 // Aprendido por Fit
+	Std  map[string]float64 // Aprendido por Fit; 1 en columnas constantes
+}
+
+func (s *StandardScaler) Kind() string { return "standard" }
+
+// Fit aprende la media y la desviación estándar de cada columna
+func (s *StandardScaler) Fit(ds *Dataset) error {
+	names, err := numericColumns(ds, s.Columns)
+	if err != nil {
+		return err
+	}
+	s.Mean, s.Std = fitColumns(ds, names, s.Concurrent, func(values []float64) (float64, float64) {
+		mean := meanObserved(values)
+		sum, n := 0.0, 0
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				sum += (v - mean) * (v - mean)
+				n++
+			}
+		}
+		return mean, safeScale(math.Sqrt(sum / float64(n)))
+	})
+	return checkObserved(s.Mean)
+}
+
+// Transform aplica (x - media) / desviación
+func (s *StandardScaler) Transform(ds *Dataset) (*Dataset, error) {
+	if s.Mean == nil {
+		return nil, ErrNotFitted
+	}
+	return scaleColumns(ds, s.Mean, s.Std, false)
+}
+
+// InverseTransform devuelve los valores a su escala original
+func (s *StandardScaler) InverseTransform(ds *Dataset) (*Dataset, error) {
+	if s.Mean == nil {
+		return nil, ErrNotFitted
+	}
+	return scaleColumns(ds, s.Mean, s.Std, true)
+}
+
+// MinMaxScaler lleva cada columna al rango [RangeMin, RangeMax] según el
+// mínimo y máximo vistos en el entrenamiento
+type MinMaxScaler struct {
+	Columns    []string // Columnas a escalar; vacío = todas las numéricas
+	Concurrent bool     // Ajusta las columnas en paralelo
+	RangeMin   float64  // Rango de salida; RangeMin = RangeMax = 0 usa [0, 1]
+	RangeMax   float64
+
+	Min map[string]float64 // Aprendido por Fit
+	Max map[string]float64 // Aprendido por Fit
+}
+
+func (s *MinMaxScaler) Kind() string { return "minmax" }
+
+// Fit aprende el mínimo y el máximo de cada columna
+func (s *MinMaxScaler) Fit(ds *Dataset) error {
+	if s.RangeMin == 0 && s.RangeMax == 0 {
+		s.RangeMax = 1
+	}
+	if s.RangeMin >= s.RangeMax {
+		return fmt.Errorf("rango de salida inválido [%g, %g]", s.RangeMin, s.RangeMax)
+	}
+	names, err := numericColumns(ds, s.Columns)
+	if err != nil {
+		return err
+	}
+	s.Min, s.Max = fitColumns(ds, names, s.Concurrent, func(values []float64) (float64, float64) {
+		lo, hi := math.NaN(), math.NaN()
+		for _, v := range values {
+			if math.IsNaN(v) {
+				continue
+			}
+			if math.IsNaN(lo) || v < lo {
+				lo = v
+			}
+			if math.IsNaN(hi) || v > hi {
+				hi = v
+			}
+		}
+		return lo, hi
+	})
+	return checkObserved(s.Min)
+}
+
+// Expresa el escalado min-max como (x - center) / scale
+func (s *MinMaxScaler) affine() (map[string]float64, map[string]float64) {
+	center := make(map[string]float64, len(s.Min))
+	scale := make(map[string]float64, len(s.Min))
+	for name, lo := range s.Min {
+		scale[name] = safeScale((s.Max[name] - lo) / (s.RangeMax - s.RangeMin))
+		center[name] = lo - s.RangeMin*scale[name]
+	}
+	return center, scale
+}
+
+// Transform lleva cada columna al rango de salida
+func (s *MinMaxScaler) Transform(ds *Dataset) (*Dataset, error) {
+	if s.Min == nil {
+		return nil, ErrNotFitted
+	}
+	center, scale := s.affine()
+	return scaleColumns(ds, center, scale, false)
+}
+
+// InverseTransform devuelve los valores a su escala original
+func (s *MinMaxScaler) InverseTransform(ds *Dataset) (*Dataset, error) {
+	if s.Min == nil {
+		return nil, ErrNotFitted
+	}
+	center, scale := s.affine()
+	return scaleColumns(ds, center, scale, true)
+}
+
+// RobustScaler centra cada columna en su mediana y la divide por su rango
+// intercuartílico, por lo que los valores atípicos apenas influyen
+type RobustScaler struct {
+	Columns    []string // Columnas a escalar; vacío = todas las numéricas
+	Concurrent bool     // Ajusta las columnas en paralelo
+
+	Median map[string]float64 // Aprendido por Fit
+	IQR    map[string]float64 // Aprendido por Fit; 1 en columnas constantes
+}
+
+func (s *RobustScaler) Kind() string { return "robust" }
+
+// Fit aprende la mediana y el rango intercuartílico de cada columna
+func (s *RobustScaler) Fit(ds *Dataset) error {
+	names, err := numericColumns(ds, s.Columns)
+	if err != nil {
+		return err
+	}
+	s.Median, s.IQR = fitColumns(ds, names, s.Concurrent, func(values []float64) (float64, float64) {
+		observed := make([]float64, 0, len(values))
+		for _, v := range values {
+			if !math.IsNaN(v) {
+				observed = append(observed, v)
+			}
+		}
+		if len(observed) == 0 {
+			return math.NaN(), 1
+		}
+		sort.Float64s(observed)
+		return quantile(observed, 0.5), safeScale(quantile(observed, 0.75) - quantile(observed, 0.25))
+	})
+	return checkObserved(s.Median)
+}
+
+// Transform aplica (x - mediana) / IQR
+func (s *RobustScaler) Transform(ds *Dataset) (*Dataset, error) {
+	if s.Median == nil {
+		return nil, ErrNotFitted
+	}
+	return scaleColumns(ds, s.Median, s.IQR, false)
+}
+
+// InverseTransform devuelve los valores a su escala original
+func (s *RobustScaler) InverseTransform(ds *Dataset) (*Dataset, error) {
+	if s.Median == nil {
+		return nil, ErrNotFitted
+	}
+	return scaleColumns(ds, s.Median, s.IQR, true)
+}
+
+// Cuantil q de valores ordenados, interpolando linealmente entre posiciones
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
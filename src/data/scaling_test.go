@@ -0,0 +1,155 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Dataset con una columna creciente, una con un valor atípico y un faltante,
+// y una constante
+func measurements(t *testing.T) *Dataset {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("x,outlier,constant,y\n")
+	for i := range 20 {
+		outlier := fmt.Sprint(i % 5)
+		switch i {
+		case 7:
+			outlier = "1000"
+		case 11:
+			outlier = "NA"
+		}
+		fmt.Fprintf(&b, "%d,%s,3,%d\n", i, outlier, i%2)
+	}
+	ds, err := ReadCSV(strings.NewReader(b.String()), CSVOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+// Escaladores sin ajustar de cada tipo
+func scalers(concurrent bool) []InverseTransformer {
+	return []InverseTransformer{
+		&StandardScaler{Concurrent: concurrent},
+		&MinMaxScaler{Concurrent: concurrent},
+		&MinMaxScaler{Concurrent: concurrent, RangeMin: -1, RangeMax: 1},
+		&RobustScaler{Concurrent: concurrent},
+	}
+}
+
+func TestScalersRoundTrip(t *testing.T) {
+	ds := measurements(t)
+	for _, s := range scalers(false) {
+		if err := s.Fit(ds); err != nil {
+			t.Fatal(err)
+		}
+		scaled, err := s.Transform(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := s.InverseTransform(scaled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, col := range ds.Features {
+			for i, v := range col.Numbers {
+				got := back.Features[j].Numbers[i]
+				if math.IsNaN(v) != math.IsNaN(got) || math.Abs(got-v) > 1e-9 {
+					t.Errorf("%s, %s[%d]: %v tras ida y vuelta, se esperaba %v", s.Kind(), col.Name, i, got, v)
+				}
+			}
+		}
+		// La columna constante queda en un único valor finito, sin dividir por 0
+		for _, v := range numbers(t, scaled, "constant") {
+			if v != numbers(t, scaled, "constant")[0] || math.IsInf(v, 0) || math.IsNaN(v) {
+				t.Errorf("%s: constante escalada = %v", s.Kind(), numbers(t, scaled, "constant"))
+				break
+			}
+		}
+		if !reflect.DeepEqual(scaled.Labels(), ds.Labels()) {
+			t.Errorf("%s: el objetivo no debe escalarse", s.Kind())
+		}
+	}
+}
+
+func TestScalersLearnedStatistics(t *testing.T) {
+	ds := measurements(t)
+
+	standard := &StandardScaler{Columns: []string{"x"}}
+	if err := standard.Fit(ds); err != nil {
+		t.Fatal(err)
+	}
+	// x = 0..19: media 9.5 y varianza poblacional (20² - 1) / 12
+	if standard.Mean["x"] != 9.5 || math.Abs(standard.Std["x"]-math.Sqrt(399.0/12)) > 1e-12 {
+		t.Errorf("media, desviación = %v, %v", standard.Mean["x"], standard.Std["x"])
+	}
+	if _, ok := standard.Mean["outlier"]; ok {
+		t.Error("Columns limita las columnas escaladas")
+	}
+
+	minmax := &MinMaxScaler{RangeMin: -1, RangeMax: 1}
+	if err := minmax.Fit(ds); err != nil {
+		t.Fatal(err)
+	}
+	scaled, err := minmax.Transform(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x := numbers(t, scaled, "x"); x[0] != -1 || x[19] != 1 {
+		t.Errorf("min-max en [-1, 1]: x = %v", x)
+	}
+	if err := (&MinMaxScaler{RangeMin: 1, RangeMax: 0}).Fit(ds); err == nil {
+		t.Error("un rango de salida invertido debe fallar")
+	}
+
+	// La mediana y el IQR ignoran el valor atípico y el faltante
+	robust := &RobustScaler{}
+	if err := robust.Fit(ds); err != nil {
+		t.Fatal(err)
+	}
+	// Observados ordenados: cuatro 0, tres 1, tres 2, cuatro 3, cuatro 4 y 1000
+	if robust.Median["outlier"] != 2 || robust.IQR["outlier"] != 2.5 || robust.IQR["constant"] != 1 {
+		t.Errorf("mediana, IQR = %v, %v; constante IQR %v", robust.Median["outlier"], robust.IQR["outlier"], robust.IQR["constant"])
+	}
+	if out := numbers(t, mustTransform(t, robust, ds), "outlier"); !math.IsNaN(out[11]) {
+		t.Errorf("el faltante debe seguir como NaN: %v", out[11])
+	}
+
+	if _, err := (&RobustScaler{}).Transform(ds); err != ErrNotFitted {
+		t.Errorf("sin ajustar: error = %v, se esperaba ErrNotFitted", err)
+	}
+}
+
+func TestScalersConcurrentFitMatchesSequential(t *testing.T) {
+	ds := measurements(t)
+	sequential, concurrent := scalers(false), scalers(true)
+	for i := range sequential {
+		if err := sequential[i].Fit(ds); err != nil {
+			t.Fatal(err)
+		}
+		if err := concurrent[i].Fit(ds); err != nil {
+			t.Fatal(err)
+		}
+		// Solo difiere la opción Concurrent: lo aprendido debe ser idéntico
+		want := mustTransform(t, sequential[i], ds)
+		got := mustTransform(t, concurrent[i], ds)
+		for j, col := range want.Features {
+			if !equalNaN(got.Features[j].Numbers, col.Numbers) {
+				t.Errorf("%s, %s: concurrente %v, secuencial %v", sequential[i].Kind(), col.Name, got.Features[j].Numbers, col.Numbers)
+			}
+		}
+	}
+}
+
+func mustTransform(t *testing.T, s Transformer, ds *Dataset) *Dataset {
+	t.Helper()
+	out, err := s.Transform(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
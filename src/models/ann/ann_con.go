@@ -1,148 +1,214 @@
 package ann
 
 import (
-	"fmt"
+	"io"
+	"src/classification"
+	"src/data"
+	"src/models"
 	"sync"
-	"time"
 )
 
-// Propagación hacia adelante
-func (ann *ANN) forwardConcurrent(inputs []float64) []float64 {
-	hiddenLayer := make([]float64, ann.hiddenSize)
-	outputLayer := make([]float64, ann.outputSize)
+var (
+	_ models.Classifier = (*ANNC)(nil)
+	_ models.Persistent = (*ANNC)(nil)
+)
 
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	// Cálculo de la capa oculta
-	go func() {
-		defer wg.Done()
-		for i := range hiddenLayer {
-			sum := ann.bias1[i]
-			for j := range inputs {
-				sum += inputs[j] * ann.weights1[j][i]
-			}
-			hiddenLayer[i] = sigmoid(sum)
-		}
-	}()
-
-	// Cálculo de la capa de salida
-	go func() {
-		defer wg.Done()
-		for i := range outputLayer {
-			sum := ann.bias2[i]
-			for j := range hiddenLayer {
-				sum += hiddenLayer[j] * ann.weights2[j][i]
-			}
-			outputLayer[i] = sigmoid(sum)
-		}
-	}()
+// Red Neuronal Artificial entrenada concurrentemente por mini-lotes: cada
+// paso suma la actualización de BatchSize filas, que se reparten entre
+// Workers goroutines con los pesos fijos, y la aplica una sola vez. Los
+// bloques de models.BatchChunk filas se combinan en orden, así que la misma
+// semilla da la misma red con cualquier número de Workers
+type ANNC struct {
+	ANN
+	BatchSize int // Filas por paso; 0 usa 64
+	Workers   int // Goroutines por lote; 0 usa GOMAXPROCS
+}
 
+// NewANNConcurrent crea una red neuronal concurrente con los hiperparámetros por defecto
+func NewANNConcurrent() *ANNC {
+	return &ANNC{ANN: *NewANN(), BatchSize: 64}
+}
+
+// Fit entrena la red concurrentemente con X e y
+func (ann *ANNC) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	ann.init(len(X[0]))
+	ann.trainConcurrent(X, y)
+	return nil
+}
+
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (ann *ANNC) Predict(X [][]float64) []float64 {
+	return threshold(ann.PredictProba(X))
+}
+
+// PredictProba devuelve la salida de la red para cada fila de X, una goroutine por fila
+func (ann *ANNC) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	var wg sync.WaitGroup
+	wg.Add(len(X))
+	for i, d := range X {
+		go func(i int, d []float64) {
+			defer wg.Done()
+			probas[i] = ann.forward(d)[0]
+		}(i, d)
+	}
 	wg.Wait()
-	return outputLayer
+	return probas
+}
+
+// Estado serializable de la red concurrente
+type annCState struct {
+	Net       annState
+	BatchSize int
+	Workers   int
+}
+
+// Save guarda la red en JSON
+func (ann *ANNC) Save(w io.Writer) error {
+	return models.Save(w, kindANNC, ann.state())
+}
+
+// SaveBinary guarda la red en formato binario
+func (ann *ANNC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindANNC, ann.state())
+}
+
+// Load carga una red guardada con Save o SaveBinary
+func (ann *ANNC) Load(r io.Reader) error {
+	var st annCState
+	if err := models.Load(r, kindANNC, &st); err != nil {
+		return err
+	}
+	ann.restore(st.Net)
+	ann.BatchSize, ann.Workers = st.BatchSize, st.Workers
+	return nil
+}
+
+func (ann *ANNC) state() annCState {
+	return annCState{ann.ANN.state(), ann.BatchSize, ann.Workers}
+}
+
+// Actualización acumulada de un bloque de filas, con los pesos de la capa
+// oculta, los de la salida y los sesgos de ambas capas uno detrás de otro
+type gradient []float64
+
+func addGradients(a, b gradient) gradient {
+	for j := range a {
+		a[j] += b[j]
+	}
+	return a
 }
 
-// Ajuste de pesos y sesgos (Backpropagation)
-func (ann *ANN) backpropagateConcurrent(inputs []float64, labels []float64, output []float64, learningRate float64) {
+// Número de parámetros de la red
+func (ann *ANN) parameters() int {
+	return ann.inputSize*ann.hiddenSize + ann.hiddenSize*ann.outputSize + ann.hiddenSize + ann.outputSize
+}
+
+// Suma a g la actualización que backpropagate haría con la fila, sin tocar
+// los pesos: la propagación va capa por capa y solo lee la red
+func (ann *ANN) accumulate(g gradient, inputs []float64, label float64) {
+	output := ann.forward(inputs)
+
 	// Calcular el error de la capa de salida
 	outputError := make([]float64, ann.outputSize)
 	for i := range outputError {
-		outputError[i] = labels[i] - output[i]
+		outputError[i] = label - output[i]
 	}
 
 	// Calcular el error de la capa oculta
 	hiddenLayerError := make([]float64, ann.hiddenSize)
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	// Error de la capa oculta
-	go func() {
-		defer wg.Done()
-		for i := range hiddenLayerError {
-			sum := 0.0
-			for j := range outputError {
-				sum += outputError[j] * ann.weights2[i][j]
-			}
-			hiddenLayerError[i] = sigmoidDeriv(sum) * sum
-		}
-	}()
-
-	// Actualizar pesos y sesgos
-	go func() {
-		defer wg.Done()
-		for i := 0; i < ann.inputSize; i++ {
-			for j := 0; j < ann.hiddenSize; j++ {
-				ann.weights1[i][j] += learningRate * hiddenLayerError[j] * inputs[i]
-			}
+	for i := range hiddenLayerError {
+		sum := 0.0
+		for j := range outputError {
+			sum += outputError[j] * ann.weights2[i][j]
 		}
+		hiddenLayerError[i] = sigmoidDeriv(sum) * sum
+	}
 
-		for i := 0; i < ann.hiddenSize; i++ {
-			for j := 0; j < ann.outputSize; j++ {
-				ann.weights2[i][j] += learningRate * outputError[j] * hiddenLayerError[i]
-			}
+	k := 0
+	for i := 0; i < ann.inputSize; i++ {
+		for j := 0; j < ann.hiddenSize; j++ {
+			g[k] += hiddenLayerError[j] * inputs[i]
+			k++
+		}
+	}
+	for i := 0; i < ann.hiddenSize; i++ {
+		for j := 0; j < ann.outputSize; j++ {
+			g[k] += outputError[j] * hiddenLayerError[i]
+			k++
 		}
+	}
+	for i := range hiddenLayerError {
+		g[k] += hiddenLayerError[i]
+		k++
+	}
+	for i := range outputError {
+		g[k] += outputError[i]
+		k++
+	}
+}
 
-		for i := range ann.bias1 {
-			ann.bias1[i] += learningRate * hiddenLayerError[i]
+// Aplica la actualización acumulada g escalada por learningRate
+func (ann *ANN) apply(g gradient, learningRate float64) {
+	k := 0
+	for i := 0; i < ann.inputSize; i++ {
+		for j := 0; j < ann.hiddenSize; j++ {
+			ann.weights1[i][j] += learningRate * g[k]
+			k++
 		}
-		for i := range ann.bias2 {
-			ann.bias2[i] += learningRate * outputError[i]
+	}
+	for i := 0; i < ann.hiddenSize; i++ {
+		for j := 0; j < ann.outputSize; j++ {
+			ann.weights2[i][j] += learningRate * g[k]
+			k++
 		}
-	}()
-
-	wg.Wait()
+	}
+	for i := range ann.bias1 {
+		ann.bias1[i] += learningRate * g[k]
+		k++
+	}
+	for i := range ann.bias2 {
+		ann.bias2[i] += learningRate * g[k]
+		k++
+	}
 }
 
-// Entrenamiento de la red neuronal
-func (ann *ANN) trainConcurrent(data [][]float64, labels []float64, epochs int, learningRate float64) {
-	var wg sync.WaitGroup
-	for epoch := 0; epoch < epochs; epoch++ {
-		wg.Add(len(data))
-		for i := range data {
-			go func(i int) {
-				defer wg.Done()
-				output := ann.forwardConcurrent(data[i])
-				ann.backpropagateConcurrent(data[i], []float64{labels[i]}, output, learningRate)
-			}(i)
+// Entrenamiento de la red neuronal por mini-lotes: las filas se recorren en
+// orden, como en train, y la actualización de cada lote se calcula en
+// paralelo y se suma sin promediar, así que una época mueve los pesos lo
+// mismo que en la red secuencial
+func (ann *ANNC) trainConcurrent(X [][]float64, labels []float64) {
+	batchSize := ann.BatchSize
+	if batchSize <= 0 {
+		batchSize = 64
+	}
+	size := ann.parameters()
+	for epoch := 0; epoch < ann.Epochs; epoch++ {
+		for start := 0; start < len(X); start += batchSize {
+			end := min(start+batchSize, len(X))
+			g := classification.MapReduce(end-start, models.BatchChunk, ann.Workers, func(lo, hi int) gradient {
+				g := make(gradient, size)
+				for i := start + lo; i < start+hi; i++ {
+					ann.accumulate(g, X[i], labels[i])
+				}
+				return g
+			}, addGradients)
+			ann.apply(g, ann.LearningRate)
 		}
-		wg.Wait()
 	}
 }
 
-// Nueva función para ejecutar la red neuronal
-func ANNConcurrent(data [][]float64, labels []float64) {
-	start := time.Now()
-
-	// Definir dimensiones
-	inputSize := len(data[0])
-	hiddenSize := 5
-	outputSize := 1
-
-	// Crear red neuronal
-	ann := newANN(inputSize, hiddenSize, outputSize)
-
-	// Entrenar red neuronal
-	ann.trainConcurrent(data, labels, 1000, 0.00001)
-
-	// Hacer predicciones
-	predictions := make([]float64, len(data))
-	var wg sync.WaitGroup
-	wg.Add(len(data))
-	for i, d := range data {
-		go func(i int, d []float64) {
-			defer wg.Done()
-			pred := ann.forward(d)[0]
-			predictions[i] = pred
-		}(i, d)
+// ANNConcurrent entrena la red neuronal concurrente con ds y devuelve sus métricas sobre el mismo dataset
+func ANNConcurrent(ds *data.Dataset) (classification.Report, error) {
+	ann := NewANNConcurrent()
+	if err := models.FitDataset(ann, ds); err != nil {
+		return classification.Report{}, err
 	}
-	wg.Wait()
-
-	// Evaluar el modelo
-	precision, recall, f1 := evaluate(predictions, labels)
-	fmt.Printf("Precision: %v\n", precision)
-	fmt.Printf("Recall: %v\n", recall)
-	fmt.Printf("F1 Score: %v\n", f1)
-
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+	return classification.EvaluateDataset(ann, ds)
 }
@@ -1,26 +1,55 @@
 package ann
 
 import (
-	"fmt"
+	"io"
 	"math"
 	"math/rand"
-	"time"
+	"src/classification"
+	"src/data"
+	"src/models"
 )
 
+var (
+	_ models.Classifier = (*ANN)(nil)
+	_ models.Persistent = (*ANN)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindANN  = "ann"
+	kindANNC = "ann_concurrent"
+)
+
+func init() {
+	models.Register(kindANN, func() models.Regressor { return NewANN() })
+	models.Register(kindANNC, func() models.Regressor { return NewANNConcurrent() })
+}
+
 // Red Neuronal Artificial (ANN)
 type ANN struct {
+	// Hiperparámetros usados por Fit
+	HiddenSize   int
+	Epochs       int
+	LearningRate float64
+	Seed         int64 // Semilla de la inicialización de los pesos
+
 	weights1, weights2                [][]float64
 	bias1, bias2                      []float64
 	inputSize, hiddenSize, outputSize int
 }
 
-// Inicializa la red neuronal
-func newANN(inputSize, hiddenSize, outputSize int) *ANN {
+// NewANN crea una red neuronal secuencial con los hiperparámetros por defecto
+func NewANN() *ANN {
+	return &ANN{HiddenSize: 5, Epochs: 1000, LearningRate: 0.00001, Seed: data.DefaultSeed}
+}
+
+// Inicializa la red neuronal con pesos aleatorios generados por rng
+func newANN(inputSize, hiddenSize, outputSize int, rng *rand.Rand) *ANN {
 	weights1 := make([][]float64, inputSize)
 	for i := range weights1 {
 		weights1[i] = make([]float64, hiddenSize)
 		for j := range weights1[i] {
-			weights1[i][j] = rand.Float64()*2 - 1
+			weights1[i][j] = rng.Float64()*2 - 1
 		}
 	}
 
@@ -28,18 +57,18 @@ func newANN(inputSize, hiddenSize, outputSize int) *ANN {
 	for i := range weights2 {
 		weights2[i] = make([]float64, outputSize)
 		for j := range weights2[i] {
-			weights2[i][j] = rand.Float64()*2 - 1
+			weights2[i][j] = rng.Float64()*2 - 1
 		}
 	}
 
 	bias1 := make([]float64, hiddenSize)
 	for i := range bias1 {
-		bias1[i] = rand.Float64()*2 - 1
+		bias1[i] = rng.Float64()*2 - 1
 	}
 
 	bias2 := make([]float64, outputSize)
 	for i := range bias2 {
-		bias2[i] = rand.Float64()*2 - 1
+		bias2[i] = rng.Float64()*2 - 1
 	}
 
 	return &ANN{
@@ -90,19 +119,6 @@ func (ann *ANN) forward(inputs []float64) []float64 {
 	return outputLayer
 }
 
-// Función de entropía cruzada para clasificación binaria
-func crossEntropy(yTrue, yPred []float64) float64 {
-	sum := 0.0
-	for i := range yTrue {
-		if yTrue[i] == 1 {
-			sum += -math.Log(yPred[i] + 1e-15)
-		} else {
-			sum += -math.Log(1 - yPred[i] + 1e-15)
-		}
-	}
-	return sum / float64(len(yTrue))
-}
-
 // Ajuste de pesos y sesgos (Backpropagation)
 func (ann *ANN) backpropagate(inputs []float64, labels []float64, output []float64, learningRate float64) {
 	// Calcular el error de la capa de salida
@@ -152,60 +168,117 @@ func (ann *ANN) train(data [][]float64, labels []float64, epochs int, learningRa
 	}
 }
 
-// Función de evaluación para entropía cruzada
-func evaluate(predictions []float64, labels []float64) (float64, float64, float64) {
-	var tp, fp, fn, tn float64
-	for i := range predictions {
-		pred := predictions[i] > 0.5
-		trueLabel := labels[i] > 0.5
-
-		if pred && trueLabel {
-			tp++
-		} else if pred && !trueLabel {
-			fp++
-		} else if !pred && trueLabel {
-			fn++
-		} else if !pred && !trueLabel {
-			tn++
-		}
+// Fit entrena la red secuencialmente con X e y
+func (ann *ANN) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
 	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	ann.init(len(X[0]))
+	ann.train(X, y, ann.Epochs, ann.LearningRate)
+	return nil
+}
 
-	precision := tp / (tp + fp)
-	recall := tp / (tp + fn)
-	f1 := 2 * (precision * recall) / (precision + recall)
+// Inicializa los pesos conservando los hiperparámetros
+func (ann *ANN) init(inputSize int) {
+	net := newANN(inputSize, ann.HiddenSize, 1, data.NewRand(ann.Seed))
+	ann.weights1, ann.weights2 = net.weights1, net.weights2
+	ann.bias1, ann.bias2 = net.bias1, net.bias2
+	ann.inputSize, ann.hiddenSize, ann.outputSize = net.inputSize, net.hiddenSize, net.outputSize
+}
 
-	return precision, recall, f1
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (ann *ANN) Predict(X [][]float64) []float64 {
+	return threshold(ann.PredictProba(X))
 }
 
-// Nueva función para ejecutar la red neuronal
-func ANNSecuential(data [][]float64, labels []float64) {
+// PredictProba devuelve la salida sigmoide de la red para cada fila de X
+func (ann *ANN) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	for i, x := range X {
+		probas[i] = ann.forward(x)[0]
+	}
+	return probas
+}
 
-	start := time.Now()
+// Estado serializable de la red
+type annState struct {
+	HiddenSize   int
+	Epochs       int
+	LearningRate float64
+	Seed         int64
+	Weights1     [][]float64
+	Weights2     [][]float64
+	Bias1        []float64
+	Bias2        []float64
+	InputSize    int
+	OutputSize   int
+}
 
-	// Definir dimensiones
-	inputSize := len(data[0])
-	hiddenSize := 5
-	outputSize := 1
+// Save guarda la red en JSON
+func (ann *ANN) Save(w io.Writer) error {
+	return models.Save(w, kindANN, ann.state())
+}
 
-	// Crear red neuronal
-	ann := newANN(inputSize, hiddenSize, outputSize)
+// SaveBinary guarda la red en formato binario
+func (ann *ANN) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindANN, ann.state())
+}
 
-	// Entrenar red neuronal
-	ann.train(data, labels, 1000, 0.00001)
+// Load carga una red guardada con Save o SaveBinary
+func (ann *ANN) Load(r io.Reader) error {
+	return ann.load(r, kindANN)
+}
 
-	// Hacer predicciones
-	predictions := make([]float64, len(data))
-	for i, d := range data {
-		pred := ann.forward(d)[0]
-		predictions[i] = pred
+func (ann *ANN) load(r io.Reader, kind string) error {
+	var st annState
+	if err := models.Load(r, kind, &st); err != nil {
+		return err
 	}
+	ann.restore(st)
+	return nil
+}
 
-	// Evaluar el modelo
-	precision, recall, f1 := evaluate(predictions, labels)
-	fmt.Printf("Precision: %v\n", precision)
-	fmt.Printf("Recall: %v\n", recall)
-	fmt.Printf("F1 Score: %v\n", f1)
+func (ann *ANN) state() annState {
+	return annState{
+		HiddenSize:   ann.HiddenSize,
+		Epochs:       ann.Epochs,
+		LearningRate: ann.LearningRate,
+		Seed:         ann.Seed,
+		Weights1:     ann.weights1,
+		Weights2:     ann.weights2,
+		Bias1:        ann.bias1,
+		Bias2:        ann.bias2,
+		InputSize:    ann.inputSize,
+		OutputSize:   ann.outputSize,
+	}
+}
+
+func (ann *ANN) restore(st annState) {
+	ann.HiddenSize, ann.Epochs, ann.LearningRate, ann.Seed = st.HiddenSize, st.Epochs, st.LearningRate, st.Seed
+	ann.weights1, ann.weights2 = st.Weights1, st.Weights2
+	ann.bias1, ann.bias2 = st.Bias1, st.Bias2
+	ann.inputSize, ann.hiddenSize, ann.outputSize = st.InputSize, len(st.Bias1), st.OutputSize
+}
+
+// Convierte probabilidades en etiquetas con el umbral 0.5
+func threshold(probas []float64) []float64 {
+	labels := make([]float64, len(probas))
+	for i, p := range probas {
+		if p > 0.5 {
+			labels[i] = 1
+		}
+	}
+	return labels
+}
 
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+// ANNSecuential entrena la red neuronal con ds y devuelve sus métricas sobre el mismo dataset
+func ANNSecuential(ds *data.Dataset) (classification.Report, error) {
+	ann := NewANN()
+	if err := models.FitDataset(ann, ds); err != nil {
+		return classification.Report{}, err
+	}
+	return classification.EvaluateDataset(ann, ds)
 }
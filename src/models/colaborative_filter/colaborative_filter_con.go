@@ -1,39 +1,79 @@
 package colaborativefilter
 
 import (
-	"math"
+	"io"
+	"src/models"
 	"sync"
 )
 
-// Función para predecir la calificación de un usuario para un ítem
-func predictRatingC(userIndex, itemIndex int, ratings [][]float64, similarities []float64, wg *sync.WaitGroup, ch chan float64) {
-	defer wg.Done()
+var (
+	_ models.Regressor  = (*CollaborativeFilterC)(nil)
+	_ models.Persistent = (*CollaborativeFilterC)(nil)
+)
 
-	var numerator, denominator float64
-	for i, sim := range similarities {
-		if i != userIndex && ratings[i][itemIndex] > 0 {
-			numerator += sim * ratings[i][itemIndex]
-			denominator += math.Abs(sim)
-		}
+// Filtro colaborativo basado en usuarios que calcula similitudes y predicciones concurrentemente
+type CollaborativeFilterC struct {
+	CollaborativeFilter
+}
+
+// NewCollaborativeFilterConcurrent crea un filtro colaborativo concurrente
+func NewCollaborativeFilterConcurrent() *CollaborativeFilterC {
+	return &CollaborativeFilterC{}
+}
+
+// Fit construye la matriz de calificaciones y la similitud entre usuarios en paralelo
+func (cf *CollaborativeFilterC) Fit(X [][]float64, y []float64) error {
+	ratings, err := ratingMatrix(X, y)
+	if err != nil {
+		return err
 	}
+	cf.ratings = ratings
+	cf.similarities = similarityMatrixC(ratings)
+	return nil
+}
 
-	if denominator == 0 {
-		ch <- 0 // Si no hay denominador, retornar 0
-		return
+// Predict devuelve la calificación estimada para cada par (usuario, ítem) de X, una goroutine por par
+func (cf *CollaborativeFilterC) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	var wg sync.WaitGroup
+	for i, pair := range X {
+		user, item, ok := cf.lookup(pair)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i, user, item int) {
+			defer wg.Done()
+			predictions[i] = predictRating(user, item, cf.ratings, cf.similarities[user])
+		}(i, user, item)
 	}
+	wg.Wait()
+	return predictions
+}
 
-	ch <- numerator / denominator
+// Save guarda el filtro en JSON
+func (cf *CollaborativeFilterC) Save(w io.Writer) error {
+	return models.Save(w, kindCollaborativeFilterC, filterState{cf.ratings, cf.similarities})
 }
 
-// Función pública para obtener recomendaciones
-func GetRecommendationsC(ratings [][]float64, userIndex int, itemIndices []int) []float64 {
+// SaveBinary guarda el filtro en formato binario
+func (cf *CollaborativeFilterC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindCollaborativeFilterC, filterState{cf.ratings, cf.similarities})
+}
+
+// Load carga un filtro guardado con Save o SaveBinary
+func (cf *CollaborativeFilterC) Load(r io.Reader) error {
+	return cf.load(r, kindCollaborativeFilterC)
+}
+
+// Calcula la similitud entre todos los pares de usuarios en paralelo
+func similarityMatrixC(ratings [][]float64) [][]float64 {
 	numUsers := len(ratings)
 	similarityMatrix := make([][]float64, numUsers)
 	for i := range similarityMatrix {
 		similarityMatrix[i] = make([]float64, numUsers)
 	}
 
-	// Calcular la similitud entre usuarios en paralelo
 	var wg sync.WaitGroup
 	for i := 0; i < numUsers; i++ {
 		for j := i + 1; j < numUsers; j++ {
@@ -47,21 +87,26 @@ func GetRecommendationsC(ratings [][]float64, userIndex int, itemIndices []int)
 		}
 	}
 	wg.Wait()
+	return similarityMatrix
+}
+
+// Función pública para obtener recomendaciones. Cada calificación se escribe
+// en la posición de su ítem, así que el orden es el de itemIndices
+func GetRecommendationsC(ratings [][]float64, userIndex int, itemIndices []int) []float64 {
+	// Calcular la similitud entre usuarios en paralelo
+	similarityMatrix := similarityMatrixC(ratings)
+	var wg sync.WaitGroup
 
 	// Predecir las calificaciones para el usuario especificado
-	ch := make(chan float64, len(itemIndices))
+	recommendations := make([]float64, len(itemIndices))
 	wg.Add(len(itemIndices))
-	for _, itemIndex := range itemIndices {
-		go predictRatingC(userIndex, itemIndex, ratings, similarityMatrix[userIndex], &wg, ch)
+	for k, itemIndex := range itemIndices {
+		go func() {
+			defer wg.Done()
+			recommendations[k] = predictRating(userIndex, itemIndex, ratings, similarityMatrix[userIndex])
+		}()
 	}
 	wg.Wait()
-	close(ch)
-
-	// Recoger las predicciones
-	var recommendations []float64
-	for rating := range ch {
-		recommendations = append(recommendations, rating)
-	}
 
 	return recommendations
 }
@@ -1,34 +1,140 @@
 package colaborativefilter
 
 import (
+	"errors"
+	"io"
 	"math"
+	"src/models"
 )
 
-// Función para calcular la similitud del coseno entre dos usuarios
-func cosineSimilarity(userA, userB []float64) float64 {
-	var dotProduct, normA, normB float64
-	for i := range userA {
-		dotProduct += userA[i] * userB[i]
-		normA += userA[i] * userA[i]
-		normB += userB[i] * userB[i]
+var (
+	_ models.Regressor  = (*CollaborativeFilter)(nil)
+	_ models.Persistent = (*CollaborativeFilter)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindCollaborativeFilter  = "collaborative_filter"
+	kindCollaborativeFilterC = "collaborative_filter_concurrent"
+)
+
+func init() {
+	models.Register(kindCollaborativeFilter, func() models.Regressor { return NewCollaborativeFilter() })
+	models.Register(kindCollaborativeFilterC, func() models.Regressor { return NewCollaborativeFilterConcurrent() })
+}
+
+// ErrInvalidPair se devuelve cuando una fila de X no es un par (usuario, ítem) válido
+var ErrInvalidPair = errors.New("cada fila de X debe ser un par (usuario, ítem) de índices no negativos")
+
+// Filtro colaborativo basado en usuarios. Cada fila de X es un par (usuario, ítem)
+// y cada valor de y la calificación que el usuario dio al ítem
+type CollaborativeFilter struct {
+	ratings      [][]float64
+	similarities [][]float64
+}
+
+// NewCollaborativeFilter crea un filtro colaborativo secuencial
+func NewCollaborativeFilter() *CollaborativeFilter {
+	return &CollaborativeFilter{}
+}
+
+// Fit construye la matriz de calificaciones y la similitud entre usuarios de forma secuencial
+func (cf *CollaborativeFilter) Fit(X [][]float64, y []float64) error {
+	ratings, err := ratingMatrix(X, y)
+	if err != nil {
+		return err
 	}
+	cf.ratings = ratings
+	cf.similarities = similarityMatrix(ratings)
+	return nil
+}
 
-	if normA == 0 || normB == 0 {
-		return 0
+// Predict devuelve la calificación estimada para cada par (usuario, ítem) de X
+func (cf *CollaborativeFilter) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	for i, pair := range X {
+		user, item, ok := cf.lookup(pair)
+		if ok {
+			predictions[i] = predictRating(user, item, cf.ratings, cf.similarities[user])
+		}
 	}
+	return predictions
+}
 
-	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+// Estado serializable del filtro
+type filterState struct {
+	Ratings      [][]float64
+	Similarities [][]float64
 }
 
-// Función pública para obtener recomendaciones sin concurrencia
-func GetRecommendations(ratings [][]float64, userIndex int, itemIndices []int) []float64 {
+// Save guarda el filtro en JSON
+func (cf *CollaborativeFilter) Save(w io.Writer) error {
+	return models.Save(w, kindCollaborativeFilter, filterState{cf.ratings, cf.similarities})
+}
+
+// SaveBinary guarda el filtro en formato binario
+func (cf *CollaborativeFilter) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindCollaborativeFilter, filterState{cf.ratings, cf.similarities})
+}
+
+// Load carga un filtro guardado con Save o SaveBinary
+func (cf *CollaborativeFilter) Load(r io.Reader) error {
+	return cf.load(r, kindCollaborativeFilter)
+}
+
+func (cf *CollaborativeFilter) load(r io.Reader, kind string) error {
+	var st filterState
+	if err := models.Load(r, kind, &st); err != nil {
+		return err
+	}
+	cf.ratings, cf.similarities = st.Ratings, st.Similarities
+	return nil
+}
+
+// Devuelve los índices del par si el usuario y el ítem se vieron en Fit
+func (cf *CollaborativeFilter) lookup(pair []float64) (int, int, bool) {
+	if len(pair) < 2 || pair[0] < 0 || pair[1] < 0 {
+		return 0, 0, false
+	}
+	user, item := int(pair[0]), int(pair[1])
+	if user >= len(cf.ratings) || item >= len(cf.ratings[user]) {
+		return 0, 0, false
+	}
+	return user, item, true
+}
+
+// Convierte pares (usuario, ítem) y calificaciones en una matriz densa; 0 significa sin calificar
+func ratingMatrix(X [][]float64, y []float64) ([][]float64, error) {
+	if err := models.CheckFit(X, y); err != nil {
+		return nil, err
+	}
+	numUsers, numItems := 0, 0
+	for _, pair := range X {
+		if len(pair) < 2 || pair[0] < 0 || pair[1] < 0 {
+			return nil, ErrInvalidPair
+		}
+		numUsers = max(numUsers, int(pair[0])+1)
+		numItems = max(numItems, int(pair[1])+1)
+	}
+
+	ratings := make([][]float64, numUsers)
+	for i := range ratings {
+		ratings[i] = make([]float64, numItems)
+	}
+	for i, pair := range X {
+		ratings[int(pair[0])][int(pair[1])] = y[i]
+	}
+	return ratings, nil
+}
+
+// Calcula la similitud entre todos los pares de usuarios de forma secuencial
+func similarityMatrix(ratings [][]float64) [][]float64 {
 	numUsers := len(ratings)
 	similarityMatrix := make([][]float64, numUsers)
 	for i := range similarityMatrix {
 		similarityMatrix[i] = make([]float64, numUsers)
 	}
 
-	// Calcular la similitud entre usuarios de forma secuencial
 	for i := 0; i < numUsers; i++ {
 		for j := i + 1; j < numUsers; j++ {
 			similarity := cosineSimilarity(ratings[i], ratings[j])
@@ -36,6 +142,29 @@ func GetRecommendations(ratings [][]float64, userIndex int, itemIndices []int) [
 			similarityMatrix[j][i] = similarity
 		}
 	}
+	return similarityMatrix
+}
+
+// Función para calcular la similitud del coseno entre dos usuarios
+func cosineSimilarity(userA, userB []float64) float64 {
+	var dotProduct, normA, normB float64
+	for i := range userA {
+		dotProduct += userA[i] * userB[i]
+		normA += userA[i] * userA[i]
+		normB += userB[i] * userB[i]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// Función pública para obtener recomendaciones sin concurrencia
+func GetRecommendations(ratings [][]float64, userIndex int, itemIndices []int) []float64 {
+	// Calcular la similitud entre usuarios de forma secuencial
+	similarityMatrix := similarityMatrix(ratings)
 
 	// Predecir las calificaciones para el usuario especificado de forma secuencial
 	var recommendations []float64
@@ -0,0 +1,66 @@
+package colaborativefilter
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// Calificaciones de tres usuarios sobre tres ítems como pares (usuario, ítem)
+func ratingPairs() ([][]float64, []float64) {
+	X := [][]float64{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {1, 2}, {2, 0}, {2, 2}}
+	y := []float64{5, 3, 5, 3, 4, 1, 2}
+	return X, y
+}
+
+func TestPredictWeightsNeighboursBySimilarity(t *testing.T) {
+	X, y := ratingPairs()
+	cf := NewCollaborativeFilter()
+	if err := cf.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	// El usuario 0 no calificó el ítem 2: se estima con los usuarios 1 y 2
+	// ponderados por su similitud del coseno con el 0
+	sim1 := (5*5 + 3*3) / (math.Sqrt(34) * math.Sqrt(50))
+	sim2 := 5 / (math.Sqrt(34) * math.Sqrt(5))
+	want := (sim1*4 + sim2*2) / (sim1 + sim2)
+	got := cf.Predict([][]float64{{0, 2}, {5, 0}, {0, 9}})
+	if math.Abs(got[0]-want) > 1e-12 {
+		t.Errorf("Predict(0, 2) = %v, se esperaba %v", got[0], want)
+	}
+	// Los usuarios e ítems que no se vieron en Fit dan 0
+	if got[1] != 0 || got[2] != 0 {
+		t.Errorf("pares desconocidos = %v, %v; se esperaba 0", got[1], got[2])
+	}
+}
+
+func TestSequentialAndConcurrentAgree(t *testing.T) {
+	X, y := ratingPairs()
+	seq, con := NewCollaborativeFilter(), NewCollaborativeFilterConcurrent()
+	if err := seq.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	pairs := [][]float64{{0, 0}, {0, 2}, {1, 1}, {2, 1}}
+	if !slices.Equal(seq.Predict(pairs), con.Predict(pairs)) {
+		t.Errorf("Predict: secuencial %v, concurrente %v", seq.Predict(pairs), con.Predict(pairs))
+	}
+
+	// Las recomendaciones siguen el orden de los ítems pedidos
+	items := []int{2, 0, 1, 2, 1, 0, 2, 1}
+	want := GetRecommendations(seq.ratings, 0, items)
+	if got := GetRecommendationsC(seq.ratings, 0, items); !slices.Equal(got, want) {
+		t.Errorf("GetRecommendationsC = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestFitRejectsInvalidPairs(t *testing.T) {
+	for _, X := range [][][]float64{{{0}}, {{-1, 0}}, {{0, -2}}} {
+		if err := NewCollaborativeFilter().Fit(X, []float64{1}); !errors.Is(err, ErrInvalidPair) {
+			t.Errorf("Fit(%v): error = %v, se esperaba ErrInvalidPair", X, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package models_test
+
+import (
+	"slices"
+	"src/models"
+	ann "src/models/ann"
+	dnn "src/models/dnn"
+	svmachine "src/models/svm"
+	"testing"
+)
+
+// Los modelos entrenados por mini-lotes reparten cada lote en bloques fijos de
+// models.BatchChunk filas, así que con la misma semilla deben dar exactamente
+// el mismo modelo con cualquier número de Workers
+func TestMiniBatchModelsDoNotDependOnWorkers(t *testing.T) {
+	X, y := binary()
+	for _, tt := range []struct {
+		name  string
+		model func(workers int) models.Regressor
+	}{
+		{"SVMC", func(workers int) models.Regressor {
+			m := svmachine.NewSVMConcurrent()
+			m.Epochs, m.BatchSize, m.Workers = 10, 32, workers
+			return m
+		}},
+		{"SVRC", func(workers int) models.Regressor {
+			m := svmachine.NewSVRConcurrent()
+			m.Epochs, m.BatchSize, m.Workers = 10, 32, workers
+			return m
+		}},
+		{"ANNC", func(workers int) models.Regressor {
+			m := ann.NewANNConcurrent()
+			m.Epochs, m.BatchSize, m.Workers = 20, 32, workers
+			return m
+		}},
+		{"DNNC", func(workers int) models.Regressor {
+			m := dnn.NewDNNConcurrent()
+			m.Epochs, m.BatchSize, m.Workers = 20, 32, workers
+			return m
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// Salida sin umbral, para que cualquier diferencia en los pesos se note
+			output := func(workers int) []float64 {
+				m := tt.model(workers)
+				if err := m.Fit(X, y); err != nil {
+					t.Fatal(err)
+				}
+				if c, ok := m.(models.Classifier); ok {
+					return c.PredictProba(X)
+				}
+				return m.Predict(X)
+			}
+			want := output(1)
+			for _, workers := range []int{2, 3, 8} {
+				if got := output(workers); !slices.Equal(got, want) {
+					t.Errorf("Workers = %d: %v\nWorkers = 1: %v", workers, got[:3], want[:3])
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,86 @@
 package decisiontree
 
 import (
-	"fmt"
+	"io"
 	"math"
+	"src/classification"
+	"src/data"
+	"src/models"
 	"sync"
-	"time"
 )
 
+var (
+	_ models.Classifier = (*DecisionTreeC)(nil)
+	_ models.Persistent = (*DecisionTreeC)(nil)
+)
+
+// Árbol de decisión entrenado concurrentemente
+type DecisionTreeC struct {
+	MaxDepth int // Hiperparámetro usado por Fit
+	Root     *Node
+}
+
+// NewDecisionTreeConcurrent crea un árbol de decisión concurrente con la profundidad por defecto
+func NewDecisionTreeConcurrent() *DecisionTreeC {
+	return &DecisionTreeC{MaxDepth: 3}
+}
+
+// Fit entrena el árbol concurrentemente con X e y
+func (dt *DecisionTreeC) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	dt.Root = trainDecisionTreeConcurrente(X, y, dt.MaxDepth)
+	return nil
+}
+
+// Predict devuelve la etiqueta redondeada de la hoja de cada fila de X
+func (dt *DecisionTreeC) Predict(X [][]float64) []float64 {
+	return roundAll(dt.PredictProba(X))
+}
+
+// PredictProba devuelve la media de las etiquetas de la hoja de cada fila de X, una goroutine por fila
+func (dt *DecisionTreeC) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	var wg sync.WaitGroup
+	wg.Add(len(X))
+	for i := range X {
+		go func(i int) {
+			defer wg.Done()
+			probas[i] = predictConcurrente(dt.Root, X[i])
+		}(i)
+	}
+	wg.Wait()
+	return probas
+}
+
+// Save guarda el árbol en JSON
+func (dt *DecisionTreeC) Save(w io.Writer) error {
+	return models.Save(w, kindDecisionTreeC, treeState{dt.MaxDepth, dt.Root})
+}
+
+// SaveBinary guarda el árbol en formato binario
+func (dt *DecisionTreeC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindDecisionTreeC, treeState{dt.MaxDepth, dt.Root})
+}
+
+// Load carga un árbol guardado con Save o SaveBinary
+func (dt *DecisionTreeC) Load(r io.Reader) error {
+	var st treeState
+	if err := models.Load(r, kindDecisionTreeC, &st); err != nil {
+		return err
+	}
+	dt.MaxDepth, dt.Root = st.MaxDepth, st.Root
+	return nil
+}
+
 // Función para dividir los datos basada en el feature y threshold de manera concurrente
-func splitDataConcurrente(data [][]float64, labels []float64, feature int, threshold float64) ([][]float64, [][]float64, []float64, []float64) {
+func splitDataConcurrente(data [][]float64, labels []float64, feature int, threshold float64, defaultLeft bool) ([][]float64, [][]float64, []float64, []float64) {
 	var leftData, rightData [][]float64
 	var leftLabels, rightLabels []float64
 
 	for i, point := range data {
-		if point[feature] <= threshold {
+		if goesLeft(point[feature], threshold, defaultLeft) {
 			leftData = append(leftData, point)
 			leftLabels = append(leftLabels, labels[i])
 		} else {
@@ -31,12 +98,12 @@ func trainDecisionTreeConcurrente(data [][]float64, labels []float64, depth int)
 		return &Node{Prediction: mean(labels)}
 	}
 
-	bestFeature, bestThreshold := findBestSplitConcurrente(data, labels)
+	bestFeature, bestThreshold, defaultLeft := findBestSplitConcurrente(data, labels)
 	if bestFeature == -1 {
 		return &Node{Prediction: mean(labels)}
 	}
 
-	leftData, rightData, leftLabels, rightLabels := splitDataConcurrente(data, labels, bestFeature, bestThreshold)
+	leftData, rightData, leftLabels, rightLabels := splitDataConcurrente(data, labels, bestFeature, bestThreshold, defaultLeft)
 
 	if len(leftData) == 0 || len(rightData) == 0 {
 		return &Node{Prediction: mean(labels)}
@@ -61,17 +128,19 @@ func trainDecisionTreeConcurrente(data [][]float64, labels []float64, depth int)
 	wg.Wait()
 
 	return &Node{
-		Feature:   bestFeature,
-		Threshold: bestThreshold,
-		Left:      leftNode,
-		Right:     rightNode,
+		Feature:     bestFeature,
+		Threshold:   bestThreshold,
+		DefaultLeft: defaultLeft,
+		Left:        leftNode,
+		Right:       rightNode,
 	}
 }
 
-// Función para encontrar la mejor división concurrentemente
-func findBestSplitConcurrente(data [][]float64, labels []float64) (int, float64) {
+// Función para encontrar la mejor división concurrentemente, un feature por goroutine
+func findBestSplitConcurrente(data [][]float64, labels []float64) (int, float64, bool) {
 	bestFeature := -1
 	bestThreshold := 0.0
+	bestDefaultLeft := false
 	bestImpurity := math.Inf(1)
 	numFeatures := len(data[0])
 
@@ -79,6 +148,7 @@ func findBestSplitConcurrente(data [][]float64, labels []float64) (int, float64)
 	type SplitResult struct {
 		feature       int
 		threshold     float64
+		defaultLeft   bool
 		totalImpurity float64
 	}
 
@@ -89,32 +159,10 @@ func findBestSplitConcurrente(data [][]float64, labels []float64) (int, float64)
 	featureWorker := func(feature int) {
 		defer wg.Done()
 
-		uniqueValues := make(map[float64]bool)
-		for _, point := range data {
-			uniqueValues[point[feature]] = true
-		}
-
-		bestFeatureImpurity := math.Inf(1)
-		bestFeatureThreshold := 0.0
-
-		for threshold := range uniqueValues {
-			_, _, leftLabels, rightLabels := splitDataConcurrente(data, labels, feature, threshold)
-			if len(leftLabels) == 0 || len(rightLabels) == 0 {
-				continue
-			}
-
-			leftImpurity := giniImpurity(leftLabels)
-			rightImpurity := giniImpurity(rightLabels)
-			totalImpurity := (leftImpurity*float64(len(leftLabels)) + rightImpurity*float64(len(rightLabels))) / float64(len(labels))
-
-			if totalImpurity < bestFeatureImpurity {
-				bestFeatureImpurity = totalImpurity
-				bestFeatureThreshold = threshold
-			}
-		}
+		threshold, defaultLeft, impurity := bestThresholdForFeature(data, labels, feature)
 
 		// Enviar el resultado a través del canal
-		resultChan <- SplitResult{feature, bestFeatureThreshold, bestFeatureImpurity}
+		resultChan <- SplitResult{feature, threshold, defaultLeft, impurity}
 	}
 
 	wg.Add(numFeatures)
@@ -128,16 +176,18 @@ func findBestSplitConcurrente(data [][]float64, labels []float64) (int, float64)
 		close(resultChan)
 	}()
 
-	// Encontrar el mejor resultado de las goroutines
+	// Encontrar el mejor resultado de las goroutines; a igual impureza gana el
+	// feature de menor índice, como en la versión secuencial
 	for result := range resultChan {
-		if result.totalImpurity < bestImpurity {
+		if result.totalImpurity < bestImpurity || (result.totalImpurity == bestImpurity && result.feature < bestFeature) {
 			bestImpurity = result.totalImpurity
 			bestFeature = result.feature
 			bestThreshold = result.threshold
+			bestDefaultLeft = result.defaultLeft
 		}
 	}
 
-	return bestFeature, bestThreshold
+	return bestFeature, bestThreshold, bestDefaultLeft
 }
 
 // Función para hacer predicciones (sin cambios)
@@ -146,53 +196,18 @@ func predictConcurrente(node *Node, point []float64) float64 {
 		return node.Prediction
 	}
 
-	if point[node.Feature] <= node.Threshold {
+	if goesLeft(point[node.Feature], node.Threshold, node.DefaultLeft) {
 		return predictConcurrente(node.Left, point)
 	} else {
 		return predictConcurrente(node.Right, point)
 	}
 }
 
-// Función para evaluar el rendimiento del árbol concurrentemente
-func evaluateConcurrente(data [][]float64, labels []float64, tree *Node) {
-	var tp, fp, tn, fn int
-
-	for i, point := range data {
-		predLabel := math.Round(predictConcurrente(tree, point))
-
-		if predLabel == 1.0 && labels[i] == 1.0 {
-			tp++
-		} else if predLabel == 1.0 && labels[i] == 0.0 {
-			fp++
-		} else if predLabel == 0.0 && labels[i] == 0.0 {
-			tn++
-		} else if predLabel == 0.0 && labels[i] == 1.0 {
-			fn++
-		}
-	}
-
-	fmt.Printf("TP: %d, FP: %d, TN: %d, FN: %d\n", tp, fp, tn, fn)
-
-	// Calcular precisión
-	precision := precisionScore(tp, fp)
-	fmt.Printf("Precisión: %.2f\n", precision)
-}
-
-// Función para calcular la precisión
-func precisionScore(tp, fp int) float64 {
-	if tp+fp == 0 {
-		return 0
+// DecisionTreeConcurrente entrena un árbol de decisión concurrente con ds y devuelve sus métricas sobre el mismo dataset
+func DecisionTreeConcurrente(ds *data.Dataset) (classification.Report, error) {
+	tree := NewDecisionTreeConcurrent()
+	if err := models.FitDataset(tree, ds); err != nil {
+		return classification.Report{}, err
 	}
-	return float64(tp) / float64(tp+fp)
-}
-
-// Función principal para el árbol de decisión concurrente
-func DecisionTreeConcurrente(data [][]float64, labels []float64) {
-	start := time.Now()
-
-	tree := trainDecisionTreeConcurrente(data, labels, 3)
-	evaluateConcurrente(data, labels, tree)
-
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+	return classification.EvaluateDataset(tree, ds)
 }
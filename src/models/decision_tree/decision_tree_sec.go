@@ -1,27 +1,135 @@
 package decisiontree
 
 import (
-	"fmt"
+	"io"
+	"maps"
 	"math"
-	"time"
+	"slices"
+	"src/classification"
+	"src/data"
+	"src/models"
 )
 
+var (
+	_ models.Classifier = (*DecisionTree)(nil)
+	_ models.Persistent = (*DecisionTree)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindDecisionTree  = "decision_tree"
+	kindDecisionTreeC = "decision_tree_concurrent"
+)
+
+func init() {
+	models.Register(kindDecisionTree, func() models.Regressor { return NewDecisionTree() })
+	models.Register(kindDecisionTreeC, func() models.Regressor { return NewDecisionTreeConcurrent() })
+}
+
 // Nodo del árbol de decisión
 type Node struct {
-	Feature    int
-	Threshold  float64
-	Left       *Node
-	Right      *Node
-	Prediction float64
+	Feature     int
+	Threshold   float64
+	DefaultLeft bool // Rama que siguen los valores faltantes (NaN), aprendida al entrenar
+	Left        *Node
+	Right       *Node
+	Prediction  float64
+}
+
+// Árbol de decisión entrenado secuencialmente
+type DecisionTree struct {
+	MaxDepth int // Hiperparámetro usado por Fit
+	Root     *Node
+}
+
+// NewDecisionTree crea un árbol de decisión secuencial con la profundidad por defecto
+func NewDecisionTree() *DecisionTree {
+	return &DecisionTree{MaxDepth: 3}
+}
+
+// Fit entrena el árbol secuencialmente con X e y
+func (dt *DecisionTree) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	dt.Root = trainDecisionTree(X, y, dt.MaxDepth)
+	return nil
+}
+
+// Predict devuelve la etiqueta redondeada de la hoja de cada fila de X
+func (dt *DecisionTree) Predict(X [][]float64) []float64 {
+	return roundAll(dt.PredictProba(X))
+}
+
+// PredictProba devuelve la media de las etiquetas de la hoja de cada fila de X
+func (dt *DecisionTree) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	for i, x := range X {
+		probas[i] = predict(dt.Root, x)
+	}
+	return probas
+}
+
+// Estado serializable del árbol
+type treeState struct {
+	MaxDepth int
+	Root     *Node
+}
+
+// Save guarda el árbol en JSON
+func (dt *DecisionTree) Save(w io.Writer) error {
+	return models.Save(w, kindDecisionTree, treeState{dt.MaxDepth, dt.Root})
+}
+
+// SaveBinary guarda el árbol en formato binario
+func (dt *DecisionTree) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindDecisionTree, treeState{dt.MaxDepth, dt.Root})
+}
+
+// Load carga un árbol guardado con Save o SaveBinary
+func (dt *DecisionTree) Load(r io.Reader) error {
+	var st treeState
+	if err := models.Load(r, kindDecisionTree, &st); err != nil {
+		return err
+	}
+	dt.MaxDepth, dt.Root = st.MaxDepth, st.Root
+	return nil
+}
+
+// Redondea las predicciones para obtener 0 o 1
+func roundAll(values []float64) []float64 {
+	labels := make([]float64, len(values))
+	for i, v := range values {
+		labels[i] = math.Round(v)
+	}
+	return labels
+}
+
+// Indica si un valor va a la rama izquierda; los faltantes siguen la rama por defecto
+func goesLeft(value, threshold float64, defaultLeft bool) bool {
+	if math.IsNaN(value) {
+		return defaultLeft
+	}
+	return value <= threshold
+}
+
+// Indica si alguna fila tiene el feature faltante
+func hasMissing(data [][]float64, feature int) bool {
+	for _, point := range data {
+		if math.IsNaN(point[feature]) {
+			return true
+		}
+	}
+	return false
 }
 
 // Function to split data based on feature and threshold
-func splitData(data [][]float64, labels []float64, feature int, threshold float64) ([][]float64, [][]float64, []float64, []float64) {
+func splitData(data [][]float64, labels []float64, feature int, threshold float64, defaultLeft bool) ([][]float64, [][]float64, []float64, []float64) {
 	var leftData, rightData [][]float64
 	var leftLabels, rightLabels []float64
 
 	for i, point := range data {
-		if point[feature] <= threshold {
+		if goesLeft(point[feature], threshold, defaultLeft) {
 			leftData = append(leftData, point)
 			leftLabels = append(leftLabels, labels[i])
 		} else {
@@ -39,12 +147,12 @@ func trainDecisionTree(data [][]float64, labels []float64, depth int) *Node {
 		return &Node{Prediction: mean(labels)}
 	}
 
-	bestFeature, bestThreshold := findBestSplit(data, labels)
+	bestFeature, bestThreshold, defaultLeft := findBestSplit(data, labels)
 	if bestFeature == -1 {
 		return &Node{Prediction: mean(labels)}
 	}
 
-	leftData, rightData, leftLabels, rightLabels := splitData(data, labels, bestFeature, bestThreshold)
+	leftData, rightData, leftLabels, rightLabels := splitData(data, labels, bestFeature, bestThreshold, defaultLeft)
 
 	if len(leftData) == 0 || len(rightData) == 0 {
 		return &Node{Prediction: mean(labels)}
@@ -53,28 +161,57 @@ func trainDecisionTree(data [][]float64, labels []float64, depth int) *Node {
 	// fmt.Printf("División: Feature %d, Threshold %.2f\n", bestFeature, bestThreshold)
 
 	return &Node{
-		Feature:   bestFeature,
-		Threshold: bestThreshold,
-		Left:      trainDecisionTree(leftData, leftLabels, depth-1),
-		Right:     trainDecisionTree(rightData, rightLabels, depth-1),
+		Feature:     bestFeature,
+		Threshold:   bestThreshold,
+		DefaultLeft: defaultLeft,
+		Left:        trainDecisionTree(leftData, leftLabels, depth-1),
+		Right:       trainDecisionTree(rightData, rightLabels, depth-1),
 	}
 }
 
-// Función para encontrar la mejor división
-func findBestSplit(data [][]float64, labels []float64) (int, float64) {
+// Función para encontrar la mejor división y la rama por defecto de los valores
+// faltantes. Si el feature tiene faltantes se prueban ambas ramas; si no, los
+// faltantes futuros siguen la rama con más filas
+func findBestSplit(data [][]float64, labels []float64) (int, float64, bool) {
 	bestFeature := -1
 	bestThreshold := 0.0
+	bestDefaultLeft := false
 	bestImpurity := math.Inf(1)
 	numFeatures := len(data[0])
 
 	for feature := 0; feature < numFeatures; feature++ {
-		uniqueValues := make(map[float64]bool)
-		for _, point := range data {
+		threshold, defaultLeft, impurity := bestThresholdForFeature(data, labels, feature)
+		if impurity < bestImpurity {
+			bestImpurity = impurity
+			bestFeature = feature
+			bestThreshold = threshold
+			bestDefaultLeft = defaultLeft
+		}
+	}
+
+	return bestFeature, bestThreshold, bestDefaultLeft
+}
+
+// Función para encontrar el mejor umbral y rama por defecto de un feature
+func bestThresholdForFeature(data [][]float64, labels []float64, feature int) (float64, bool, float64) {
+	bestThreshold := 0.0
+	bestDefaultLeft := false
+	bestImpurity := math.Inf(1)
+
+	missing := hasMissing(data, feature)
+	uniqueValues := make(map[float64]bool)
+	for _, point := range data {
+		if !math.IsNaN(point[feature]) {
 			uniqueValues[point[feature]] = true
 		}
+	}
 
-		for threshold := range uniqueValues {
-			_, _, leftLabels, rightLabels := splitData(data, labels, feature, threshold)
+	for _, threshold := range slices.Sorted(maps.Keys(uniqueValues)) {
+		for _, defaultLeft := range []bool{false, true} {
+			if defaultLeft && !missing {
+				break
+			}
+			_, _, leftLabels, rightLabels := splitData(data, labels, feature, threshold, defaultLeft)
 			if len(leftLabels) == 0 || len(rightLabels) == 0 {
 				continue
 			}
@@ -85,13 +222,13 @@ func findBestSplit(data [][]float64, labels []float64) (int, float64) {
 
 			if totalImpurity < bestImpurity {
 				bestImpurity = totalImpurity
-				bestFeature = feature
 				bestThreshold = threshold
+				bestDefaultLeft = defaultLeft || (!missing && len(leftLabels) > len(rightLabels))
 			}
 		}
 	}
 
-	return bestFeature, bestThreshold
+	return bestThreshold, bestDefaultLeft, bestImpurity
 }
 
 // Función para calcular la impureza Gini
@@ -103,22 +240,14 @@ func giniImpurity(labels []float64) float64 {
 
 	impurity := 1.0
 	total := float64(len(labels))
-	for _, count := range labelCounts {
-		probability := float64(count) / total
+	for _, label := range slices.Sorted(maps.Keys(labelCounts)) {
+		probability := float64(labelCounts[label]) / total
 		impurity -= probability * probability
 	}
 
 	return impurity
 }
 
-// Function to calculate precision
-func precision(tp, fp int) float64 {
-	if tp+fp == 0 {
-		return 0
-	}
-	return float64(tp) / float64(tp+fp)
-}
-
 // Función para calcular la media
 func mean(labels []float64) float64 {
 	if len(labels) == 0 {
@@ -137,57 +266,18 @@ func predict(node *Node, point []float64) float64 {
 		return node.Prediction
 	}
 
-	if point[node.Feature] <= node.Threshold {
+	if goesLeft(point[node.Feature], node.Threshold, node.DefaultLeft) {
 		return predict(node.Left, point)
 	} else {
 		return predict(node.Right, point)
 	}
 }
 
-// Función para evaluar el rendimiento del árbol con las métricas: Precisión, Recall, F1-Score, Accuracy
-func evaluate(data [][]float64, labels []float64, tree *Node) {
-	var tp, fp, tn, fn int // Verdaderos positivos, falsos positivos, verdaderos negativos, falsos negativos
-
-	for i, point := range data {
-		predLabel := math.Round(predict(tree, point))
-
-		if predLabel == 1.0 && labels[i] == 1.0 {
-			tp++
-		} else if predLabel == 1.0 && labels[i] == 0.0 {
-			fp++
-		} else if predLabel == 0.0 && labels[i] == 0.0 {
-			tn++
-		} else if predLabel == 0.0 && labels[i] == 1.0 {
-			fn++
-		}
+// DecisionTreeSec entrena un árbol de decisión con ds y devuelve sus métricas sobre el mismo dataset
+func DecisionTreeSec(ds *data.Dataset) (classification.Report, error) {
+	tree := NewDecisionTree()
+	if err := models.FitDataset(tree, ds); err != nil {
+		return classification.Report{}, err
 	}
-
-	fmt.Printf("TP: %d, FP: %d, TN: %d, FN: %d\n", tp, fp, tn, fn)
-
-	// Calcular precisión, recall, F1-score y exactitud
-	precision := precision(tp, fp)
-	// recall := recall(tp, fn)
-	// f1Score := f1Score(precision, recall)
-	// accuracy := accuracy(tp, tn, fp, fn)
-
-	fmt.Printf("Precisión: %.2f\n", precision)
-}
-
-func DecisionTreeSec(data [][]float64, labels []float64) {
-	// Tiempo inicial
-	start := time.Now()
-
-	// Datos de ejemplo
-	// data := [][]float64{{2.3, 4.5}, {1.3, 3.5}, {3.3, 2.5}, {2.5, 3.8}, {1.9, 2.8}}
-	// labels := []float64{1.0, 0.0, 1.0, 0.0, 1.0}
-
-	// Entrenar el árbol de decisión
-	tree := trainDecisionTree(data, labels, 3)
-
-	// Evaluar el rendimiento del árbol
-	evaluate(data, labels, tree)
-
-	// Tiempo transcurrido
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+	return classification.EvaluateDataset(tree, ds)
 }
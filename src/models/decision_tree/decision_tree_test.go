@@ -0,0 +1,81 @@
+package decisiontree
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// La clase es 1 cuando el feature 1 vale 5 o más; las filas con el feature 1
+// faltante tienen la clase missingLabel. Los features 0 y 2 no informan
+func withMissing(missingLabel float64) ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 40 {
+		x := []float64{float64(i % 3), float64(i % 10), 1}
+		label := 0.0
+		if x[1] >= 5 {
+			label = 1
+		}
+		if i%8 == 0 {
+			x[1], label = math.NaN(), missingLabel
+		}
+		X, y = append(X, x), append(y, label)
+	}
+	return X, y
+}
+
+func TestMissingValuesFollowLearnedBranch(t *testing.T) {
+	for _, missingLabel := range []float64{0, 1} {
+		X, y := withMissing(missingLabel)
+		tree := NewDecisionTree()
+		if err := tree.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if tree.Root.Feature != 1 {
+			t.Fatalf("la raíz divide por el feature %d, se esperaba el 1", tree.Root.Feature)
+		}
+		// Los faltantes van con la clase que tenían al entrenar
+		if got := tree.Predict([][]float64{{0, math.NaN(), 1}}); got[0] != missingLabel {
+			t.Errorf("faltantes de la clase %v predichos como %v", missingLabel, got[0])
+		}
+		for i, p := range tree.Predict(X) {
+			if p != y[i] {
+				t.Errorf("fila %d: predicción %v, se esperaba %v", i, p, y[i])
+			}
+		}
+	}
+}
+
+func TestSequentialAndConcurrentGrowSameTree(t *testing.T) {
+	X, y := withMissing(1)
+	seq, con := NewDecisionTree(), NewDecisionTreeConcurrent()
+	if err := seq.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seq.Root, con.Root) {
+		t.Error("los árboles secuencial y concurrente difieren")
+	}
+	if !reflect.DeepEqual(seq.PredictProba(X), con.PredictProba(X)) {
+		t.Error("las probabilidades secuenciales y concurrentes difieren")
+	}
+}
+
+func TestGiniImpurity(t *testing.T) {
+	tests := []struct {
+		labels []float64
+		want   float64
+	}{
+		{[]float64{1, 1, 1}, 0},
+		{[]float64{0, 1}, 0.5},
+		{[]float64{0, 1, 2}, 2.0 / 3},
+	}
+	for _, tt := range tests {
+		if got := giniImpurity(tt.labels); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("giniImpurity(%v) = %v, se esperaba %v", tt.labels, got, tt.want)
+		}
+	}
+}
@@ -1,142 +1,213 @@
 package dnn
 
 import (
-	"fmt"
-	"math"
-	"math/rand"
+	"io"
+	"src/classification"
+	"src/data"
+	"src/models"
 	"sync"
 )
 
-// Retropropagación
-func (dnn *DNN) backpropagateConcurrent(activations, zs [][]float64, label float64, learningRate float64) {
-	// Inicializar los gradientes
-	weightGradients := make([][][]float64, len(dnn.weights))
-	biasGradients := make([][]float64, len(dnn.biases))
+var (
+	_ models.Classifier = (*DNNC)(nil)
+	_ models.Persistent = (*DNNC)(nil)
+)
+
+// Red Neuronal Profunda entrenada concurrentemente por mini-lotes: cada paso
+// suma los gradientes de BatchSize filas, que se reparten entre Workers
+// goroutines con los pesos fijos, y los aplica una sola vez. Los bloques de
+// models.BatchChunk filas se combinan en orden, así que la misma semilla da
+// la misma red con cualquier número de Workers
+type DNNC struct {
+	DNN
+	BatchSize int // Filas por paso; 0 usa 64
+	Workers   int // Goroutines por lote; 0 usa GOMAXPROCS
+}
 
-	// Derivada del costo respecto a la última capa
-	delta := make([]float64, dnn.layerSizes[len(dnn.layerSizes)-1])
-	for j := range delta {
-		delta[j] = costDerivative(activations[len(activations)-1][j], label) * sigmoidDerivative(zs[len(zs)-1][j])
+// NewDNNConcurrent crea una red neuronal profunda concurrente con los hiperparámetros por defecto
+func NewDNNConcurrent() *DNNC {
+	return &DNNC{DNN: *NewDNN(), BatchSize: 64}
+}
+
+// Fit entrena la red concurrentemente con X e y
+func (dnn *DNNC) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
 	}
+	dnn.init(len(X[0]))
+	dnn.trainConcurrent(X, nil, y, nil, dnn.Epochs, dnn.LearningRate)
+	return nil
+}
+
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (dnn *DNNC) Predict(X [][]float64) []float64 {
+	return roundAll(dnn.PredictProba(X))
+}
+
+// PredictProba devuelve la salida de la red para cada fila de X, una goroutine por fila
+func (dnn *DNNC) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	var wg sync.WaitGroup
+	wg.Add(len(X))
+	for i := range X {
+		go func(i int) {
+			defer wg.Done()
+			probas[i] = dnn.output(X[i])
+		}(i)
+	}
+	wg.Wait()
+	return probas
+}
+
+// Estado serializable de la red concurrente
+type dnnCState struct {
+	Net       dnnState
+	BatchSize int
+	Workers   int
+}
+
+// Save guarda la red en JSON
+func (dnn *DNNC) Save(w io.Writer) error {
+	return models.Save(w, kindDNNC, dnn.state())
+}
+
+// SaveBinary guarda la red en formato binario
+func (dnn *DNNC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindDNNC, dnn.state())
+}
+
+// Load carga una red guardada con Save o SaveBinary
+func (dnn *DNNC) Load(r io.Reader) error {
+	var st dnnCState
+	if err := models.Load(r, kindDNNC, &st); err != nil {
+		return err
+	}
+	dnn.restore(st.Net)
+	dnn.BatchSize, dnn.Workers = st.BatchSize, st.Workers
+	return nil
+}
+
+func (dnn *DNNC) state() dnnCState {
+	return dnnCState{dnn.DNN.state(), dnn.BatchSize, dnn.Workers}
+}
+
+// Gradientes acumulados de un bloque de filas y su costo total
+type gradient struct {
+	weights [][][]float64
+	biases  [][]float64
+	cost    float64
+}
 
-	// Actualizar los gradientes para la última capa
-	biasGradients[len(biasGradients)-1] = delta
-	weightGradients[len(weightGradients)-1] = make([][]float64, dnn.layerSizes[len(dnn.layerSizes)-2])
-	for j := range weightGradients[len(weightGradients)-1] {
-		weightGradients[len(weightGradients)-1][j] = make([]float64, dnn.layerSizes[len(dnn.layerSizes)-1])
-		for k := range weightGradients[len(weightGradients)-1][j] {
-			weightGradients[len(weightGradients)-1][j][k] = activations[len(activations)-2][j] * delta[k]
+// Gradiente nulo con la forma de la red
+func (dnn *DNN) zeroGradient() gradient {
+	g := gradient{weights: make([][][]float64, len(dnn.weights)), biases: make([][]float64, len(dnn.biases))}
+	for i := range dnn.weights {
+		g.weights[i] = make([][]float64, len(dnn.weights[i]))
+		for j := range dnn.weights[i] {
+			g.weights[i][j] = make([]float64, len(dnn.weights[i][j]))
 		}
+		g.biases[i] = make([]float64, len(dnn.biases[i]))
 	}
+	return g
+}
 
-	// Retropropagación para capas anteriores
-	for l := len(zs) - 2; l >= 0; l-- {
-		nextDelta := make([]float64, dnn.layerSizes[l+1])
-		for j := range nextDelta {
-			sum := 0.0
-			for k := range delta {
-				sum += dnn.weights[l+1][j][k] * delta[k]
+// Suma b a a en el lugar y devuelve a
+func addGradients(a, b gradient) gradient {
+	for i := range a.weights {
+		for j := range a.weights[i] {
+			for k := range a.weights[i][j] {
+				a.weights[i][j][k] += b.weights[i][j][k]
 			}
-			nextDelta[j] = sum * sigmoidDerivative(zs[l][j])
 		}
-		delta = nextDelta
-
-		biasGradients[l] = delta
-		weightGradients[l] = make([][]float64, dnn.layerSizes[l])
-		for j := range weightGradients[l] {
-			weightGradients[l][j] = make([]float64, dnn.layerSizes[l+1])
-			for k := range weightGradients[l][j] {
-				weightGradients[l][j][k] = activations[l][j] * delta[k]
-			}
+		for j := range a.biases[i] {
+			a.biases[i][j] += b.biases[i][j]
 		}
 	}
+	a.cost += b.cost
+	return a
+}
 
-	// Bloquear actualizaciones de pesos y sesgos
-	var mu sync.Mutex
-	mu.Lock()
-	defer mu.Unlock()
-
-	for i := range dnn.weights {
-		for j := range dnn.weights[i] {
-			for k := range dnn.weights[i][j] {
-				dnn.weights[i][j][k] -= learningRate * weightGradients[i][j][k]
+// Entrenamiento de la red neuronal profunda por mini-lotes: las filas se
+// recorren en orden, como en train, y los gradientes de cada lote se suman
+// sin promediar, así que una época mueve los pesos lo mismo que en la red
+// secuencial. La propagación de cada fila va capa por capa y solo lee la red,
+// que cambia únicamente entre lotes
+func (dnn *DNNC) trainConcurrent(trainData, testData [][]float64, trainLabels, testLabels []float64, epochs int, learningRate float64) {
+	batchSize := dnn.BatchSize
+	if batchSize <= 0 {
+		batchSize = 64
+	}
+	for epoch := 0; epoch < epochs; epoch++ {
+		totalCost := 0.0
+		for start := 0; start < len(trainData); start += batchSize {
+			end := min(start+batchSize, len(trainData))
+			g := classification.MapReduce(end-start, models.BatchChunk, dnn.Workers, func(lo, hi int) gradient {
+				g := dnn.zeroGradient()
+				for i := start + lo; i < start+hi; i++ {
+					activations, zs := dnn.forward(trainData[i])
+					g.cost += costFunction(activations[len(activations)-1][0], trainLabels[i])
+					weightGradients, biasGradients := dnn.gradients(activations, zs, trainLabels[i])
+					addGradients(g, gradient{weights: weightGradients, biases: biasGradients})
+				}
+				return g
+			}, addGradients)
+
+			totalCost += g.cost
+			for i := range dnn.weights {
+				for j := range dnn.weights[i] {
+					for k := range dnn.weights[i][j] {
+						dnn.weights[i][j][k] -= learningRate * g.weights[i][j][k]
+					}
+				}
+				for j := range dnn.biases[i] {
+					dnn.biases[i][j] -= learningRate * g.biases[i][j]
+				}
 			}
 		}
-		for j := range dnn.biases[i] {
-			dnn.biases[i][j] -= learningRate * biasGradients[i][j]
+
+		if !dnn.Verbose {
+			continue
 		}
+		logEpoch(epoch, totalCost, evaluateConcurrent, &dnn.DNN, trainData, testData, trainLabels, testLabels)
 	}
 }
 
-// Métricas de evaluación: precisión y MSE
-func evaluateConcurrent(dnn *DNN, data [][]float64, labels []float64) (float64, float64) {
-	var correctPredictions int
-	var totalMSE float64
+// Métricas de evaluación de las probabilidades, calculando cada fila en su goroutine
+func evaluateConcurrent(dnn *DNN, data [][]float64, labels []float64) (float64, float64, float64) {
+	probas := make([]float64, len(data))
 	var wg sync.WaitGroup
-	var mu sync.Mutex
-
 	for i := range data {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			outputs, _ := dnn.forward(data[i])
-			prediction := math.Round(outputs[len(outputs)-1][0]) // Redondear la salida para obtener 0 o 1
-			mu.Lock()
-			if prediction == labels[i] {
-				correctPredictions++
-			}
-			totalMSE += costFunction(outputs[len(outputs)-1][0], labels[i])
-			mu.Unlock()
+			probas[i] = dnn.output(data[i])
 		}(i)
 	}
 	wg.Wait()
-
-	accuracy := float64(correctPredictions) / float64(len(data))
-	return accuracy, totalMSE / float64(len(data))
+	return probaMetrics(probas, labels)
 }
 
-// Entrenamiento de la red neuronal profunda concurrente
-func (dnn *DNN) trainConcurrent(trainData, testData [][]float64, trainLabels, testLabels []float64, epochs int, learningRate float64) {
-	for epoch := 0; epoch < epochs; epoch++ {
-		totalCost := 0.0
-		var wg sync.WaitGroup
-		var mu sync.Mutex
-
-		for i := range trainData {
-			wg.Add(1)
-			go func(i int) {
-				defer wg.Done()
-				activations, zs := dnn.forward(trainData[i])
-				mu.Lock()
-				totalCost += costFunction(activations[len(activations)-1][0], trainLabels[i])
-				mu.Unlock()
-				dnn.backpropagateConcurrent(activations, zs, trainLabels[i], learningRate)
-			}(i)
-		}
-		wg.Wait()
-
-		trainAccuracy, trainMSE := evaluateConcurrent(dnn, trainData, trainLabels)
-		testAccuracy, testMSE := evaluateConcurrent(dnn, testData, testLabels)
-		fmt.Printf("Epoch %d: Costo: %f, Precisión entrenamiento: %f, MSE entrenamiento: %f, Precisión prueba: %f, MSE prueba: %f\n",
-			epoch, totalCost, trainAccuracy, trainMSE, testAccuracy, testMSE)
+// DNNConcurrent entrena la red con trainSet, mostrando las métricas de cada epoch,
+// y devuelve sus métricas en testSet
+func DNNConcurrent(trainSet, testSet *data.Dataset) (classification.Report, error) {
+	train, err := trainSet.Matrix()
+	if err != nil {
+		return classification.Report{}, err
+	}
+	test, err := testSet.Matrix()
+	if err != nil {
+		return classification.Report{}, err
 	}
-}
-
-func DNNConcurrent(train [][]float64, trainLabel []float64, test [][]float64, testLabel []float64) {
-	rand.Seed(42)
-
-	// Definir la arquitectura de la red neuronal profunda
-	layerSizes := []int{3, 5, 5, 1} // Última capa con 1 neurona para etiquetas como valor único
 
-	// Crear red neuronal profunda
-	dnn := newDNN(layerSizes)
+	// Crear red neuronal profunda con dos capas ocultas de 5 neuronas
+	dnn := NewDNNConcurrent()
+	dnn.Verbose = true
+	dnn.init(len(train[0]))
 
 	// Entrenar red neuronal profunda
-	dnn.trainConcurrent(train, test, trainLabel, testLabel, 1000, 0.0001)
-
-	// // Hacer predicciones
-	// for _, d := range test {
-	// 	outputs, _ := dnn.forward(d)
-	// 	fmt.Println("Predicción:", outputs[len(outputs)-1])
-	// }
+	dnn.trainConcurrent(train, test, trainSet.Labels(), testSet.Labels(), 1000, 0.0001)
+	return classification.EvaluateDataset(dnn, testSet)
 }
@@ -2,19 +2,146 @@ package dnn
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
+	"src/classification"
+	"src/data"
+	"src/models"
 )
 
+var (
+	_ models.Classifier = (*DNN)(nil)
+	_ models.Persistent = (*DNN)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindDNN  = "dnn"
+	kindDNNC = "dnn_concurrent"
+)
+
+func init() {
+	models.Register(kindDNN, func() models.Regressor { return NewDNN() })
+	models.Register(kindDNNC, func() models.Regressor { return NewDNNConcurrent() })
+}
+
 // Red Neuronal Profunda (DNN)
 type DNN struct {
+	// Hiperparámetros usados por Fit
+	HiddenLayers []int
+	Epochs       int
+	LearningRate float64
+	Verbose      bool  // Imprime el costo y la precisión de cada época
+	Seed         int64 // Semilla de la inicialización de los pesos
+
 	weights    [][][]float64
 	biases     [][]float64
 	layerSizes []int
 }
 
-// Inicializa la red neuronal profunda
-func newDNN(layerSizes []int) *DNN {
+// NewDNN crea una red neuronal profunda secuencial con los hiperparámetros por defecto
+func NewDNN() *DNN {
+	return &DNN{HiddenLayers: []int{5, 5}, Epochs: 1000, LearningRate: 0.0001, Seed: data.DefaultSeed}
+}
+
+// Inicializa los pesos para inputSize entradas conservando los hiperparámetros
+func (dnn *DNN) init(inputSize int) {
+	layerSizes := append([]int{inputSize}, dnn.HiddenLayers...)
+	layerSizes = append(layerSizes, 1) // Última capa con 1 neurona para etiquetas como valor único
+
+	net := newDNN(layerSizes, data.NewRand(dnn.Seed))
+	dnn.weights, dnn.biases, dnn.layerSizes = net.weights, net.biases, net.layerSizes
+}
+
+// Fit entrena la red secuencialmente con X e y
+func (dnn *DNN) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	dnn.init(len(X[0]))
+	dnn.train(X, nil, y, nil, dnn.Epochs, dnn.LearningRate)
+	return nil
+}
+
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (dnn *DNN) Predict(X [][]float64) []float64 {
+	return roundAll(dnn.PredictProba(X))
+}
+
+// PredictProba devuelve la salida sigmoide de la red para cada fila de X
+func (dnn *DNN) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	for i, x := range X {
+		probas[i] = dnn.output(x)
+	}
+	return probas
+}
+
+// Salida de la última neurona de la red
+func (dnn *DNN) output(inputs []float64) float64 {
+	activations, _ := dnn.forward(inputs)
+	return activations[len(activations)-1][0]
+}
+
+// Estado serializable de la red
+type dnnState struct {
+	HiddenLayers []int
+	Epochs       int
+	LearningRate float64
+	Seed         int64
+	Weights      [][][]float64
+	Biases       [][]float64
+	LayerSizes   []int
+}
+
+// Save guarda la red en JSON
+func (dnn *DNN) Save(w io.Writer) error {
+	return models.Save(w, kindDNN, dnn.state())
+}
+
+// SaveBinary guarda la red en formato binario
+func (dnn *DNN) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindDNN, dnn.state())
+}
+
+// Load carga una red guardada con Save o SaveBinary
+func (dnn *DNN) Load(r io.Reader) error {
+	return dnn.load(r, kindDNN)
+}
+
+func (dnn *DNN) load(r io.Reader, kind string) error {
+	var st dnnState
+	if err := models.Load(r, kind, &st); err != nil {
+		return err
+	}
+	dnn.restore(st)
+	return nil
+}
+
+func (dnn *DNN) state() dnnState {
+	return dnnState{dnn.HiddenLayers, dnn.Epochs, dnn.LearningRate, dnn.Seed, dnn.weights, dnn.biases, dnn.layerSizes}
+}
+
+func (dnn *DNN) restore(st dnnState) {
+	dnn.HiddenLayers, dnn.Epochs, dnn.LearningRate, dnn.Seed = st.HiddenLayers, st.Epochs, st.LearningRate, st.Seed
+	dnn.weights, dnn.biases, dnn.layerSizes = st.Weights, st.Biases, st.LayerSizes
+}
+
+// Redondea las salidas para obtener 0 o 1
+func roundAll(probas []float64) []float64 {
+	labels := make([]float64, len(probas))
+	for i, p := range probas {
+		labels[i] = math.Round(p)
+	}
+	return labels
+}
+
+// Inicializa la red neuronal profunda con pesos aleatorios generados por rng
+func newDNN(layerSizes []int, rng *rand.Rand) *DNN {
 	numLayers := len(layerSizes)
 	weights := make([][][]float64, numLayers-1)
 	biases := make([][]float64, numLayers-1)
@@ -25,11 +152,11 @@ func newDNN(layerSizes []int) *DNN {
 		for j := 0; j < layerSizes[i]; j++ {
 			weights[i][j] = make([]float64, layerSizes[i+1])
 			for k := 0; k < layerSizes[i+1]; k++ {
-				weights[i][j][k] = rand.Float64()*2 - 1
+				weights[i][j][k] = rng.Float64()*2 - 1
 			}
 		}
 		for k := 0; k < layerSizes[i+1]; k++ {
-			biases[i][k] = rand.Float64()*2 - 1
+			biases[i][k] = rng.Float64()*2 - 1
 		}
 	}
 	return &DNN{
@@ -83,6 +210,24 @@ func costDerivative(output float64, label float64) float64 {
 
 // Retropropagación
 func (dnn *DNN) backpropagate(activations, zs [][]float64, label float64, learningRate float64) {
+	weightGradients, biasGradients := dnn.gradients(activations, zs, label)
+
+	// Actualizar pesos y sesgos
+	for i := range dnn.weights {
+		for j := range dnn.weights[i] {
+			for k := range dnn.weights[i][j] {
+				dnn.weights[i][j][k] -= learningRate * weightGradients[i][j][k]
+			}
+		}
+		for j := range dnn.biases[i] {
+			dnn.biases[i][j] -= learningRate * biasGradients[i][j]
+		}
+	}
+}
+
+// Gradientes del costo de una fila respecto a los pesos y los sesgos, a
+// partir de las activaciones de forward; no modifica la red
+func (dnn *DNN) gradients(activations, zs [][]float64, label float64) ([][][]float64, [][]float64) {
 	// Inicializar los gradientes
 	weightGradients := make([][][]float64, len(dnn.weights))
 	biasGradients := make([][]float64, len(dnn.biases))
@@ -124,36 +269,24 @@ func (dnn *DNN) backpropagate(activations, zs [][]float64, label float64, learni
 			}
 		}
 	}
-
-	// Actualizar pesos y sesgos
-	for i := range dnn.weights {
-		for j := range dnn.weights[i] {
-			for k := range dnn.weights[i][j] {
-				dnn.weights[i][j][k] -= learningRate * weightGradients[i][j][k]
-			}
-		}
-		for j := range dnn.biases[i] {
-			dnn.biases[i][j] -= learningRate * biasGradients[i][j]
-		}
-	}
+	return weightGradients, biasGradients
 }
 
-// Métricas de evaluación: precisión y MSE
-func evaluate(dnn *DNN, data [][]float64, labels []float64) (float64, float64) {
-	var correctPredictions int
-	var totalMSE float64
-
+// Métricas de evaluación de las probabilidades: accuracy con el umbral 0.5,
+// log loss y Brier
+func evaluate(dnn *DNN, data [][]float64, labels []float64) (float64, float64, float64) {
+	probas := make([]float64, len(data))
 	for i := range data {
-		outputs, _ := dnn.forward(data[i])
-		prediction := math.Round(outputs[len(outputs)-1][0]) // Redondear la salida para obtener 0 o 1
-		if prediction == labels[i] {
-			correctPredictions++
-		}
-		totalMSE += costFunction(outputs[len(outputs)-1][0], labels[i])
+		probas[i] = dnn.output(data[i])
 	}
+	return probaMetrics(probas, labels)
+}
 
-	accuracy := float64(correctPredictions) / float64(len(data))
-	return accuracy, totalMSE / float64(len(data))
+func probaMetrics(probas, labels []float64) (float64, float64, float64) {
+	accuracy := classification.ClassificationReport(roundAll(probas), labels).Accuracy
+	logLoss, _ := classification.LogLoss(probas, labels)
+	brier, _ := classification.BrierScore(probas, labels)
+	return accuracy, logLoss, brier
 }
 
 // Entrenamiento de la red neuronal profunda
@@ -165,38 +298,44 @@ func (dnn *DNN) train(trainData, testData [][]float64, trainLabels, testLabels [
 			totalCost += costFunction(activations[len(activations)-1][0], trainLabels[i])
 			dnn.backpropagate(activations, zs, trainLabels[i], learningRate)
 		}
-		trainAccuracy, trainMSE := evaluate(dnn, trainData, trainLabels)
-		testAccuracy, testMSE := evaluate(dnn, testData, testLabels)
-		fmt.Printf("Epoch %d: Costo: %f, Precisión entrenamiento: %f, MSE entrenamiento: %f, Precisión prueba: %f, MSE prueba: %f\n",
-			epoch, totalCost, trainAccuracy, trainMSE, testAccuracy, testMSE)
+		if !dnn.Verbose {
+			continue
+		}
+		logEpoch(epoch, totalCost, evaluate, dnn, trainData, testData, trainLabels, testLabels)
 	}
 }
 
-func DNNSecuential(train [][]float64, trainLabel []float64, test [][]float64, testLabel []float64) {
-	rand.Seed(42)
-
-	// Definir la arquitectura de la red neuronal profunda
-	layerSizes := []int{3, 5, 5, 1} // Última capa con 1 neurona para etiquetas como valor único
-
-	// Crear red neuronal profunda
-	dnn := newDNN(layerSizes)
+// Escribe el costo del epoch y las métricas de entrenamiento y, si hay, de prueba
+func logEpoch(epoch int, cost float64, eval func(*DNN, [][]float64, []float64) (float64, float64, float64), dnn *DNN, trainData, testData [][]float64, trainLabels, testLabels []float64) {
+	trainAccuracy, trainLogLoss, trainBrier := eval(dnn, trainData, trainLabels)
+	if len(testData) == 0 {
+		fmt.Printf("Epoch %d: Costo: %f, Accuracy entrenamiento: %f, Log loss entrenamiento: %f, Brier entrenamiento: %f\n",
+			epoch, cost, trainAccuracy, trainLogLoss, trainBrier)
+		return
+	}
+	testAccuracy, testLogLoss, testBrier := eval(dnn, testData, testLabels)
+	fmt.Printf("Epoch %d: Costo: %f, Accuracy entrenamiento: %f, Log loss entrenamiento: %f, Brier entrenamiento: %f, Accuracy prueba: %f, Log loss prueba: %f, Brier prueba: %f\n",
+		epoch, cost, trainAccuracy, trainLogLoss, trainBrier, testAccuracy, testLogLoss, testBrier)
+}
 
-	// Datos de ejemplo (cada fila tiene 3 características y las etiquetas son una lista unidimensional)
-	// data := [][]float64{{0.1, 0.2, 0.3}, {0.4, 0.5, 0.6}, {0.7, 0.8, 0.9}, {0.9, 0.7, 0.5}}
-	// labels := []float64{0.0, 1.0, 1.0, 0.0} // Etiquetas como una lista unidimensional
+// DNNSecuential entrena la red con trainSet, mostrando las métricas de cada epoch,
+// y devuelve sus métricas en testSet
+func DNNSecuential(trainSet, testSet *data.Dataset) (classification.Report, error) {
+	train, err := trainSet.Matrix()
+	if err != nil {
+		return classification.Report{}, err
+	}
+	test, err := testSet.Matrix()
+	if err != nil {
+		return classification.Report{}, err
+	}
 
-	// Dividir datos en entrenamiento y prueba (50% entrenamiento, 50% prueba)
-	// trainData := data[:2]
-	// testData := data[2:]
-	// trainLabels := labels[:2]
-	// testLabels := labels[2:]
+	// Crear red neuronal profunda con dos capas ocultas de 5 neuronas
+	dnn := NewDNN()
+	dnn.Verbose = true
+	dnn.init(len(train[0]))
 
 	// Entrenar red neuronal profunda
-	dnn.train(train, test, trainLabel, testLabel, 1000, 0.0001)
-
-	// // Hacer predicciones
-	// for _, d := range test {
-	// 	outputs, _ := dnn.forward(d)
-	// 	fmt.Println("Predicción:", outputs[len(outputs)-1])
-	// }
+	dnn.train(train, test, trainSet.Labels(), testSet.Labels(), 1000, 0.0001)
+	return classification.EvaluateDataset(dnn, testSet)
 }
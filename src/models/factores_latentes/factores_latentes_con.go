@@ -1,11 +1,51 @@
 package factoreslatentes
 
 import (
-	"fmt"
+	"io"
+	"src/models"
 	"sync"
 	"time"
 )
 
+var (
+	_ models.Regressor  = (*RecommenderC)(nil)
+	_ models.Persistent = (*RecommenderC)(nil)
+)
+
+// Recomendador que calcula las similitudes entre usuarios concurrentemente
+type RecommenderC struct {
+	Recommender
+}
+
+// NewRecommenderConcurrent crea un recomendador concurrente con similitud de Pearson
+func NewRecommenderConcurrent() *RecommenderC {
+	return &RecommenderC{Recommender: *NewRecommender()}
+}
+
+// Predict devuelve la puntuación de cada par (usuario, ítem) de X; si el usuario
+// ya calificó el ítem se devuelve su calificación
+func (r *RecommenderC) Predict(X [][]float64) []float64 {
+	similarity, _ := similarityByName(r.Similarity)
+	return r.predict(X, func(user string) map[string]float64 {
+		return getRecommendationsConcurrent(user, r.ratings, similarity)
+	})
+}
+
+// Save guarda el recomendador en JSON
+func (r *RecommenderC) Save(w io.Writer) error {
+	return models.Save(w, kindRecommenderC, recommenderState{r.Similarity, r.ratings})
+}
+
+// SaveBinary guarda el recomendador en formato binario
+func (r *RecommenderC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindRecommenderC, recommenderState{r.Similarity, r.ratings})
+}
+
+// Load carga un recomendador guardado con Save o SaveBinary
+func (r *RecommenderC) Load(rd io.Reader) error {
+	return r.load(rd, kindRecommenderC)
+}
+
 // Función para obtener recomendaciones concurrentemente
 func getRecommendationsConcurrent(user string, ratings map[string]map[string]float64, similarityFunc func(map[string]float64, map[string]float64) float64) map[string]float64 {
 	scores := make(map[string]float64)
@@ -63,7 +103,8 @@ func getRecommendationsConcurrent(user string, ratings map[string]map[string]flo
 	return scores
 }
 
-func UnderlyingFactorsConcurrent(ratings map[string]map[string]float64) {
+// UnderlyingFactorsConcurrent calcula concurrentemente las recomendaciones de User100 con ambas similitudes
+func UnderlyingFactorsConcurrent(ratings map[string]map[string]float64) Recommendations {
 
 	start := time.Now()
 
@@ -71,12 +112,9 @@ func UnderlyingFactorsConcurrent(ratings map[string]map[string]float64) {
 
 	// Escoge entre pearsonSimilarity o cosineSimilarity
 	recommendations := getRecommendationsConcurrent(user, ratings, pearsonSimilarity)
-	fmt.Println("Recomendaciones para", user, ":", recommendations)
 
 	// También puedes probar con la similitud coseno:
 	cosineRecommendations := getRecommendationsConcurrent(user, ratings, cosineSimilarity)
-	fmt.Println("Recomendaciones con Coseno para", user, ":", cosineRecommendations)
 
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+	return Recommendations{user, recommendations, cosineRecommendations, time.Since(start)}
 }
@@ -1,11 +1,152 @@
 package factoreslatentes
 
 import (
-	"fmt"
+	"errors"
+	"io"
 	"math"
+	"src/models"
+	"strconv"
 	"time"
 )
 
+var (
+	_ models.Regressor  = (*Recommender)(nil)
+	_ models.Persistent = (*Recommender)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindRecommender  = "recommender"
+	kindRecommenderC = "recommender_concurrent"
+)
+
+func init() {
+	models.Register(kindRecommender, func() models.Regressor { return NewRecommender() })
+	models.Register(kindRecommenderC, func() models.Regressor { return NewRecommenderConcurrent() })
+}
+
+// Errores devueltos por Fit
+var (
+	ErrInvalidPair       = errors.New("cada fila de X debe ser un par (usuario, ítem) de índices no negativos")
+	ErrUnknownSimilarity = errors.New("similitud desconocida, use \"pearson\" o \"cosine\"")
+)
+
+// Recomendador basado en la similitud entre usuarios. Cada fila de X es un par
+// (usuario, ítem) y cada valor de y la calificación que el usuario dio al ítem
+type Recommender struct {
+	Similarity string // "pearson" o "cosine"
+
+	ratings map[string]map[string]float64
+}
+
+// NewRecommender crea un recomendador secuencial con similitud de Pearson
+func NewRecommender() *Recommender {
+	return &Recommender{Similarity: "pearson"}
+}
+
+// Fit guarda las calificaciones agrupadas por usuario
+func (r *Recommender) Fit(X [][]float64, y []float64) error {
+	if _, err := similarityByName(r.Similarity); err != nil {
+		return err
+	}
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+
+	ratings := make(map[string]map[string]float64)
+	for i, pair := range X {
+		if len(pair) < 2 || pair[0] < 0 || pair[1] < 0 {
+			return ErrInvalidPair
+		}
+		user, item := pairKeys(pair)
+		if ratings[user] == nil {
+			ratings[user] = make(map[string]float64)
+		}
+		ratings[user][item] = y[i]
+	}
+	r.ratings = ratings
+	return nil
+}
+
+// Predict devuelve la puntuación de cada par (usuario, ítem) de X; si el usuario
+// ya calificó el ítem se devuelve su calificación
+func (r *Recommender) Predict(X [][]float64) []float64 {
+	similarity, _ := similarityByName(r.Similarity)
+	return r.predict(X, func(user string) map[string]float64 {
+		return getRecommendations(user, r.ratings, similarity)
+	})
+}
+
+// Estado serializable del recomendador
+type recommenderState struct {
+	Similarity string
+	Ratings    map[string]map[string]float64
+}
+
+// Save guarda el recomendador en JSON
+func (r *Recommender) Save(w io.Writer) error {
+	return models.Save(w, kindRecommender, recommenderState{r.Similarity, r.ratings})
+}
+
+// SaveBinary guarda el recomendador en formato binario
+func (r *Recommender) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindRecommender, recommenderState{r.Similarity, r.ratings})
+}
+
+// Load carga un recomendador guardado con Save o SaveBinary
+func (r *Recommender) Load(rd io.Reader) error {
+	return r.load(rd, kindRecommender)
+}
+
+func (r *Recommender) load(rd io.Reader, kind string) error {
+	var st recommenderState
+	if err := models.Load(rd, kind, &st); err != nil {
+		return err
+	}
+	r.Similarity, r.ratings = st.Similarity, st.Ratings
+	return nil
+}
+
+// Calcula las predicciones pidiendo las recomendaciones una sola vez por usuario
+func (r *Recommender) predict(X [][]float64, recommend func(user string) map[string]float64) []float64 {
+	predictions := make([]float64, len(X))
+	cache := make(map[string]map[string]float64)
+	for i, pair := range X {
+		if len(pair) < 2 {
+			continue
+		}
+		user, item := pairKeys(pair)
+		if rating, ok := r.ratings[user][item]; ok {
+			predictions[i] = rating
+			continue
+		}
+		if _, ok := r.ratings[user]; !ok {
+			continue
+		}
+		if _, ok := cache[user]; !ok {
+			cache[user] = recommend(user)
+		}
+		predictions[i] = cache[user][item]
+	}
+	return predictions
+}
+
+// Convierte un par (usuario, ítem) en las claves del mapa de calificaciones
+func pairKeys(pair []float64) (string, string) {
+	return strconv.Itoa(int(pair[0])), strconv.Itoa(int(pair[1]))
+}
+
+// Devuelve la función de similitud con el nombre dado
+func similarityByName(name string) (func(map[string]float64, map[string]float64) float64, error) {
+	switch name {
+	case "pearson":
+		return pearsonSimilarity, nil
+	case "cosine":
+		return cosineSimilarity, nil
+	}
+	return nil, ErrUnknownSimilarity
+}
+
 // Datos de ejemplo: usuarios y sus calificaciones de películas
 // var ratings = map[string]map[string]float64{
 // 	"User1": {"Movie1": 5, "Movie2": 3, "Movie3": 4},
@@ -127,7 +268,17 @@ func getRecommendations(user string, ratings map[string]map[string]float64, simi
 	return scores
 }
 
-func UnderlyingFactors(ratings map[string]map[string]float64) {
+// Recomendaciones de un usuario con la similitud de Pearson y la de coseno,
+// junto con el tiempo que tomó calcularlas
+type Recommendations struct {
+	User    string
+	Pearson map[string]float64
+	Cosine  map[string]float64
+	Elapsed time.Duration
+}
+
+// UnderlyingFactors calcula secuencialmente las recomendaciones de User100 con ambas similitudes
+func UnderlyingFactors(ratings map[string]map[string]float64) Recommendations {
 
 	start := time.Now()
 
@@ -135,12 +286,9 @@ func UnderlyingFactors(ratings map[string]map[string]float64) {
 
 	// Escoge entre pearsonSimilarity o cosineSimilarity
 	recommendations := getRecommendations(user, ratings, pearsonSimilarity)
-	fmt.Println("Recomendaciones para", user, ":", recommendations)
 
 	// También puedes probar con la similitud coseno:
 	cosineRecommendations := getRecommendations(user, ratings, cosineSimilarity)
-	fmt.Println("Recomendaciones con Coseno para", user, ":", cosineRecommendations)
 
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s", elapsed)
+	return Recommendations{user, recommendations, cosineRecommendations, time.Since(start)}
 }
@@ -0,0 +1,33 @@
+package factoreslatentes
+
+import (
+	"maps"
+	"math"
+	"testing"
+)
+
+func TestUnderlyingFactorsReturnsRecommendations(t *testing.T) {
+	ratings := map[string]map[string]float64{
+		"User100": {"Item1": 5, "Item2": 3, "Item3": 1},
+		"User1":   {"Item1": 4, "Item2": 3, "Item3": 2, "Item4": 5},
+		"User2":   {"Item1": 1, "Item2": 3, "Item3": 5, "Item5": 4},
+	}
+	equal := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+	sequential := UnderlyingFactors(ratings)
+	concurrent := UnderlyingFactorsConcurrent(ratings)
+	if sequential.User != "User100" || concurrent.User != "User100" {
+		t.Fatalf("usuarios = %q, %q", sequential.User, concurrent.User)
+	}
+	// User1 califica como User100 y User2 al revés: con similitud negativa no
+	// aporta, así que solo queda Item4 con la calificación de User1
+	for _, r := range []Recommendations{sequential, concurrent} {
+		for _, scores := range []map[string]float64{r.Pearson, r.Cosine} {
+			if len(scores) != 1 || !equal(scores["Item4"], 5) {
+				t.Errorf("recomendaciones = %v, se esperaba map[Item4:5]", scores)
+			}
+		}
+	}
+	if !maps.EqualFunc(sequential.Pearson, concurrent.Pearson, equal) || !maps.EqualFunc(sequential.Cosine, concurrent.Cosine, equal) {
+		t.Errorf("secuencial %+v y concurrente %+v difieren", sequential, concurrent)
+	}
+}
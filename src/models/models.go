@@ -1 +1,81 @@
 package models
+
+import (
+	"errors"
+	"math"
+	"src/data"
+)
+
+// Errores comunes devueltos por Fit
+var (
+	ErrEmptyData      = errors.New("los datos de entrenamiento están vacíos")
+	ErrLengthMismatch = errors.New("la longitud de los datos y las etiquetas no coinciden")
+	ErrMissingValues  = errors.New("los datos tienen valores faltantes (NaN); impútelos antes de entrenar")
+)
+
+// BatchChunk es el número de filas de cada bloque en que los modelos
+// concurrentes reparten un lote entre sus workers. Es fijo para que las sumas
+// por bloques, y con ellas el modelo entrenado, no dependan de Workers
+const BatchChunk = 8
+
+// Regressor es el contrato común de todos los modelos: se entrena con Fit y
+// devuelve una predicción por fila de X con Predict
+type Regressor interface {
+	Fit(X [][]float64, y []float64) error
+	Predict(X [][]float64) []float64
+}
+
+// Classifier es un modelo binario que además devuelve la probabilidad de la
+// clase positiva (1) para cada fila de X
+type Classifier interface {
+	Regressor
+	PredictProba(X [][]float64) []float64
+}
+
+// MultiClassifier es un modelo que predice una de varias clases. Classes
+// devuelve las clases vistas en Fit, en orden
+type MultiClassifier interface {
+	Regressor
+	Classes() []float64
+}
+
+// CheckFit valida las dimensiones de X e y antes de entrenar
+func CheckFit(X [][]float64, y []float64) error {
+	if len(X) == 0 || len(X[0]) == 0 {
+		return ErrEmptyData
+	}
+	if len(X) != len(y) {
+		return ErrLengthMismatch
+	}
+	return nil
+}
+
+// CheckComplete rechaza X con valores faltantes, para los modelos que no los admiten
+func CheckComplete(X [][]float64) error {
+	for _, row := range X {
+		for _, v := range row {
+			if math.IsNaN(v) {
+				return ErrMissingValues
+			}
+		}
+	}
+	return nil
+}
+
+// FitDataset entrena el modelo con las características y la columna objetivo del dataset
+func FitDataset(m Regressor, ds *data.Dataset) error {
+	X, err := ds.Matrix()
+	if err != nil {
+		return err
+	}
+	return m.Fit(X, ds.Labels())
+}
+
+// PredictDataset devuelve una predicción por fila del dataset
+func PredictDataset(m Regressor, ds *data.Dataset) ([]float64, error) {
+	X, err := ds.Matrix()
+	if err != nil {
+		return nil, err
+	}
+	return m.Predict(X), nil
+}
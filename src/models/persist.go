@@ -0,0 +1,188 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// Versión actual del formato de serialización
+const FormatVersion = 1
+
+// Cabecera que identifica el formato binario (gob)
+var binaryMagic = []byte("MLGOB")
+
+// Errores devueltos al cargar un modelo
+var (
+	ErrUnsupportedVersion = errors.New("versión del formato de modelo no soportada")
+	ErrModelMismatch      = errors.New("el archivo contiene otro tipo de modelo")
+	ErrUnknownModel       = errors.New("tipo de modelo no registrado")
+)
+
+// Persistent es un modelo que puede guardarse y cargarse
+type Persistent interface {
+	Save(w io.Writer) error       // Guarda en JSON
+	SaveBinary(w io.Writer) error // Guarda en formato binario compacto
+	Load(r io.Reader) error       // Carga cualquiera de los dos formatos
+}
+
+// Cabecera común a ambos formatos
+type header struct {
+	Version int    `json:"version"`
+	Model   string `json:"model"`
+}
+
+// Documento JSON con la cabecera y el estado del modelo
+type jsonEnvelope struct {
+	header
+	Data json.RawMessage `json:"data"`
+}
+
+// Fábricas de modelos vacíos por tipo, usadas por LoadModel
+var registry = map[string]func() Regressor{}
+
+// Register asocia un tipo de modelo con una fábrica de modelos vacíos
+func Register(kind string, factory func() Regressor) {
+	registry[kind] = factory
+}
+
+// Kinds devuelve los tipos de modelo registrados, ordenados
+func Kinds() []string {
+	return slices.Sorted(maps.Keys(registry))
+}
+
+// Save escribe el estado del modelo en JSON con la cabecera versionada
+func Save(w io.Writer, kind string, state any) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(jsonEnvelope{
+		header: header{Version: FormatVersion, Model: kind},
+		Data:   data,
+	})
+}
+
+// SaveBinary escribe el estado del modelo con gob detrás de la cabecera binaria
+func SaveBinary(w io.Writer, kind string, state any) error {
+	if _, err := w.Write(binaryMagic); err != nil {
+		return err
+	}
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(header{Version: FormatVersion, Model: kind}); err != nil {
+		return err
+	}
+	return enc.Encode(state)
+}
+
+// Load lee un modelo guardado con Save o SaveBinary y decodifica su estado en state
+func Load(r io.Reader, kind string, state any) error {
+	br := bufio.NewReader(r)
+	h, decode, err := readHeader(br)
+	if err != nil {
+		return err
+	}
+	if h.Model != kind {
+		return fmt.Errorf("%w: se esperaba %q y se encontró %q", ErrModelMismatch, kind, h.Model)
+	}
+	return decode(state)
+}
+
+// LoadModel lee un modelo de cualquier tipo registrado y lo devuelve listo para predecir
+func LoadModel(r io.Reader) (Regressor, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := readHeader(bufio.NewReader(bytes.NewReader(raw)))
+	if err != nil {
+		return nil, err
+	}
+	factory, ok := registry[h.Model]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownModel, h.Model)
+	}
+	model := factory()
+	persistent, ok := model.(Persistent)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q no implementa Persistent", ErrUnknownModel, h.Model)
+	}
+	if err := persistent.Load(bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Detecta el formato, lee la cabecera y devuelve una función que decodifica el estado
+func readHeader(br *bufio.Reader) (header, func(state any) error, error) {
+	var h header
+	var decode func(state any) error
+
+	prefix, _ := br.Peek(len(binaryMagic))
+	if bytes.Equal(prefix, binaryMagic) {
+		br.Discard(len(binaryMagic))
+		dec := gob.NewDecoder(br)
+		if err := dec.Decode(&h); err != nil {
+			return h, nil, err
+		}
+		decode = dec.Decode
+	} else {
+		var env jsonEnvelope
+		if err := json.NewDecoder(br).Decode(&env); err != nil {
+			return h, nil, err
+		}
+		h = env.header
+		decode = func(state any) error { return json.Unmarshal(env.Data, state) }
+	}
+
+	if h.Version < 1 || h.Version > FormatVersion {
+		return h, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, h.Version)
+	}
+	return h, decode, nil
+}
+
+// Clone devuelve una copia independiente del modelo (hiperparámetros y, si ya
+// se entrenó, su estado), obtenida guardándolo y volviéndolo a cargar
+func Clone(m Regressor) (Regressor, error) {
+	persistent, ok := m.(Persistent)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T no implementa Persistent", ErrUnknownModel, m)
+	}
+	var buf bytes.Buffer
+	if err := persistent.SaveBinary(&buf); err != nil {
+		return nil, err
+	}
+	return LoadModel(&buf)
+}
+
+// SaveNested guarda un modelo para incluirlo en el estado de otro: en JSON si
+// binary es false, como un objeto anidado, o en formato binario. LoadNested
+// lo recupera en ambos casos
+func SaveNested(m Regressor, binary bool) (json.RawMessage, error) {
+	persistent, ok := m.(Persistent)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T no implementa Persistent", ErrUnknownModel, m)
+	}
+	var buf bytes.Buffer
+	if binary {
+		if err := persistent.SaveBinary(&buf); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	if err := persistent.Save(&buf); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// LoadNested carga un modelo guardado con SaveNested
+func LoadNested(raw json.RawMessage) (Regressor, error) {
+	return LoadModel(bytes.NewReader(raw))
+}
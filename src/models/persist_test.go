@@ -0,0 +1,221 @@
+package models_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"src/models"
+	ann "src/models/ann"
+	recommendation "src/models/colaborative_filter"
+	decisiontree "src/models/decision_tree"
+	dnn "src/models/dnn"
+	underFactors "src/models/factores_latentes"
+	randomforest "src/models/random_forest"
+	svmachine "src/models/svm"
+	"strings"
+	"testing"
+)
+
+// Dos clases separadas por la suma de las características
+func binary() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 60 {
+		a, b := float64(i%6)/6, float64(i%5)/5
+		label := float64(i % 2)
+		X = append(X, []float64{a + 2*label, b + 2*label})
+		y = append(y, label)
+	}
+	return X, y
+}
+
+// Tres clases en intervalos de la primera característica
+func multiclass() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 60 {
+		label := float64(i % 3)
+		X = append(X, []float64{3*label + float64(i%4)/4, float64(i%5) / 5})
+		y = append(y, label)
+	}
+	return X, y
+}
+
+// Pares (usuario, ítem) con su calificación
+func ratings() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for user := range 6 {
+		for item := range 5 {
+			if (user+item)%4 == 0 {
+				continue
+			}
+			X = append(X, []float64{float64(user), float64(item)})
+			y = append(y, float64(1+(user*item+item)%5))
+		}
+	}
+	return X, y
+}
+
+// Un modelo de cada tipo registrado con los datos con los que se entrena. Las
+// opciones de concurrencia no son las por defecto, para comprobar que se guardan
+var fixtures = []struct {
+	model func() models.Regressor
+	data  func() ([][]float64, []float64)
+}{
+	{func() models.Regressor { return ann.NewANN() }, binary},
+	{func() models.Regressor {
+		m := ann.NewANNConcurrent()
+		m.BatchSize, m.Workers = 16, 3
+		return m
+	}, binary},
+	{func() models.Regressor { return dnn.NewDNN() }, binary},
+	{func() models.Regressor {
+		m := dnn.NewDNNConcurrent()
+		m.BatchSize, m.Workers = 16, 3
+		return m
+	}, binary},
+	{func() models.Regressor { return decisiontree.NewDecisionTree() }, binary},
+	{func() models.Regressor { return decisiontree.NewDecisionTreeConcurrent() }, binary},
+	{func() models.Regressor { return randomforest.NewRandomForest() }, binary},
+	{func() models.Regressor { return randomforest.NewRandomForestConcurrent() }, binary},
+	{func() models.Regressor { return svmachine.NewSVM() }, binary},
+	{func() models.Regressor {
+		m := svmachine.NewSVMConcurrent()
+		m.BatchSize, m.Workers = 16, 3
+		return m
+	}, binary},
+	{func() models.Regressor { return svmachine.NewKernelSVM() }, binary},
+	{func() models.Regressor {
+		m := svmachine.NewKernelSVMConcurrent()
+		m.Workers, m.CacheMB = 3, 8
+		return m
+	}, binary},
+	{func() models.Regressor { return svmachine.NewSVR() }, binary},
+	{func() models.Regressor {
+		m := svmachine.NewSVRConcurrent()
+		m.BatchSize, m.Workers = 16, 3
+		return m
+	}, binary},
+	{func() models.Regressor { return svmachine.NewKernelSVR() }, binary},
+	{func() models.Regressor {
+		m := svmachine.NewKernelSVRConcurrent()
+		m.Workers, m.CacheMB = 3, 8
+		return m
+	}, binary},
+	{func() models.Regressor { return svmachine.NewMulticlass(svmachine.NewSVM(), svmachine.OneVsRest) }, multiclass},
+	{func() models.Regressor {
+		return svmachine.NewMulticlassConcurrent(svmachine.NewKernelSVM(), svmachine.OneVsOne)
+	}, multiclass},
+	{func() models.Regressor { return recommendation.NewCollaborativeFilter() }, ratings},
+	{func() models.Regressor { return recommendation.NewCollaborativeFilterConcurrent() }, ratings},
+	{func() models.Regressor { return underFactors.NewRecommender() }, ratings},
+	{func() models.Regressor { return underFactors.NewRecommenderConcurrent() }, ratings},
+	{func() models.Regressor {
+		return &models.Thresholded{Model: decisiontree.NewDecisionTree(), Threshold: 0.3}
+	}, binary},
+}
+
+// Tipo guardado en la cabecera JSON del modelo
+func kindOf(t *testing.T, m models.Regressor) string {
+	var buf bytes.Buffer
+	if err := m.(models.Persistent).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var h struct{ Model string }
+	if err := json.NewDecoder(&buf).Decode(&h); err != nil {
+		t.Fatal(err)
+	}
+	return h.Model
+}
+
+func TestFixturesCoverEveryKind(t *testing.T) {
+	var kinds []string
+	for _, f := range fixtures {
+		kinds = append(kinds, kindOf(t, f.model()))
+	}
+	slices.Sort(kinds)
+	if !slices.Equal(kinds, models.Kinds()) {
+		t.Errorf("tipos de los fixtures = %v\ntipos registrados = %v", kinds, models.Kinds())
+	}
+}
+
+func TestRoundTripEveryKind(t *testing.T) {
+	for _, f := range fixtures {
+		m := f.model()
+		t.Run(kindOf(t, m), func(t *testing.T) {
+			X, y := f.data()
+			if err := m.Fit(X, y); err != nil {
+				t.Fatal(err)
+			}
+			want := m.Predict(X)
+
+			var buf bytes.Buffer
+			if err := m.(models.Persistent).Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			saved := buf.String()
+
+			// El modelo recuperado predice igual y, al guardarlo de nuevo, da el
+			// mismo JSON: no se pierde ningún hiperparámetro ni opción
+			check := func(name string, loaded models.Regressor) {
+				t.Helper()
+				if reflect.TypeOf(loaded) != reflect.TypeOf(m) {
+					t.Fatalf("%s: tipo %T, se esperaba %T", name, loaded, m)
+				}
+				if got := loaded.Predict(X); !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: las predicciones cambiaron", name)
+				}
+				var again bytes.Buffer
+				if err := loaded.(models.Persistent).Save(&again); err != nil {
+					t.Fatal(err)
+				}
+				if again.String() != saved {
+					t.Errorf("%s: el modelo guardado de nuevo difiere:\n%s\n%s", name, again.String(), saved)
+				}
+			}
+
+			loaded, err := models.LoadModel(&buf)
+			if err != nil {
+				t.Fatalf("JSON: %v", err)
+			}
+			check("JSON", loaded)
+
+			buf.Reset()
+			if err := m.(models.Persistent).SaveBinary(&buf); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err = models.LoadModel(&buf)
+			if err != nil {
+				t.Fatalf("binario: %v", err)
+			}
+			check("binario", loaded)
+
+			clone, err := models.Clone(m)
+			if err != nil {
+				t.Fatalf("Clone: %v", err)
+			}
+			check("Clone", clone)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := svmachine.NewSVM().Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := ann.NewANN().Load(bytes.NewReader(buf.Bytes())); !errors.Is(err, models.ErrModelMismatch) {
+		t.Errorf("otro tipo: error = %v, se esperaba ErrModelMismatch", err)
+	}
+	unknown := strings.Replace(buf.String(), `"model":"svm"`, `"model":"perceptron"`, 1)
+	if _, err := models.LoadModel(strings.NewReader(unknown)); !errors.Is(err, models.ErrUnknownModel) {
+		t.Errorf("tipo no registrado: error = %v, se esperaba ErrUnknownModel", err)
+	}
+	future := strings.Replace(buf.String(), `"version":1`, `"version":99`, 1)
+	if _, err := models.LoadModel(strings.NewReader(future)); !errors.Is(err, models.ErrUnsupportedVersion) {
+		t.Errorf("versión futura: error = %v, se esperaba ErrUnsupportedVersion", err)
+	}
+}
@@ -1,37 +1,110 @@
 package randomforest
 
 import (
-	"fmt"
+	"io"
+	"math/rand"
+	"src/classification"
+	"src/data"
+	"src/models"
 	"sync"
-	"time"
+)
+
+var (
+	_ models.Classifier = (*RandomForestConc)(nil)
+	_ models.Persistent = (*RandomForestConc)(nil)
 )
 
 type RandomForestConc struct {
-	Trees []*TreeNode
-	mu    sync.Mutex
+	NumTrees int   // Number of trees grown by Fit
+	Seed     int64 // Master seed; each goroutine draws its bootstrap sample from its own derived stream
+	Trees    []*TreeNode
+}
+
+// NewRandomForestConcurrent creates a concurrent Random Forest with the default number of trees
+func NewRandomForestConcurrent() *RandomForestConc {
+	return &RandomForestConc{NumTrees: 5, Seed: data.DefaultSeed}
+}
+
+// Fit trains NumTrees trees concurrently on X and the integer labels in y
+func (rf *RandomForestConc) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	rf.Trees = nil
+	rf.Train(X, toIntLabels(y), rf.NumTrees)
+	return nil
+}
+
+// Predict returns the majority vote for each row of X, one goroutine per row
+func (rf *RandomForestConc) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	var wg sync.WaitGroup
+	wg.Add(len(X))
+	for i := range X {
+		go func(i int) {
+			defer wg.Done()
+			predictions[i] = float64(rf.PredictSample(X[i]))
+		}(i)
+	}
+	wg.Wait()
+	return predictions
 }
 
-// Train the Random Forest concurrently
+// PredictProba returns the fraction of trees voting for class 1 for each row of X, one goroutine per row
+func (rf *RandomForestConc) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	var wg sync.WaitGroup
+	wg.Add(len(X))
+	for i := range X {
+		go func(i int) {
+			defer wg.Done()
+			probas[i] = positiveVotes(rf.Trees, X[i])
+		}(i)
+	}
+	wg.Wait()
+	return probas
+}
+
+// Save writes the forest as JSON
+func (rf *RandomForestConc) Save(w io.Writer) error {
+	return models.Save(w, kindRandomForestConc, forestState{rf.NumTrees, rf.Seed, rf.Trees})
+}
+
+// SaveBinary writes the forest in the compact binary format
+func (rf *RandomForestConc) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindRandomForestConc, forestState{rf.NumTrees, rf.Seed, rf.Trees})
+}
+
+// Load reads a forest written by Save or SaveBinary
+func (rf *RandomForestConc) Load(r io.Reader) error {
+	var st forestState
+	if err := models.Load(r, kindRandomForestConc, &st); err != nil {
+		return err
+	}
+	rf.NumTrees, rf.Seed, rf.Trees = st.NumTrees, st.Seed, st.Trees
+	return nil
+}
+
+// Train the Random Forest concurrently, one goroutine per tree. Each tree is
+// stored at its own index, so the forest does not depend on scheduling
 func (rf *RandomForestConc) Train(data [][]float64, labels []int, numTrees int) {
 	var wg sync.WaitGroup
-	rf.mu = sync.Mutex{}
+	trees := make([]*TreeNode, numTrees)
 
-	for i := 0; i < numTrees; i++ {
+	for i, seed := range treeSeeds(rf.Seed, numTrees) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			sampledData, sampledLabels := bootstrapSample(data, labels)
-			tree := createTree(sampledData, sampledLabels)
-			rf.mu.Lock()
-			rf.Trees = append(rf.Trees, tree)
-			rf.mu.Unlock()
+			sampledData, sampledLabels := bootstrapSample(data, labels, rand.New(rand.NewSource(seed)))
+			trees[i] = createTree(sampledData, sampledLabels)
 		}()
 	}
 	wg.Wait()
+	rf.Trees = append(rf.Trees, trees...)
 }
 
-// Predict using the Random Forest
-func (rf *RandomForestConc) Predict(sample []float64) int {
+// PredictSample returns the majority vote of the forest for a single sample
+func (rf *RandomForestConc) PredictSample(sample []float64) int {
 	votes := make(map[int]int)
 
 	for _, tree := range rf.Trees {
@@ -39,42 +112,14 @@ func (rf *RandomForestConc) Predict(sample []float64) int {
 		votes[label]++
 	}
 
-	var maxVotes int
-	var prediction int
-	for label, count := range votes {
-		if count > maxVotes {
-			maxVotes = count
-			prediction = label
-		}
-	}
-
-	return prediction
+	return mostVoted(votes)
 }
 
-func RandomForestConcurrent(data [][]float64, labels []int, test [][]float64, testLabel []int) {
-
-	start := time.Now()
-
-	rf := &RandomForestConc{}
-	rf.Train(data, labels, 5)
-
-	predictions := make([]int, len(test))
-
-	for i, sample := range test {
-		predictions[i] = rf.Predict(sample)
+// RandomForestConcurrent trains a concurrent forest on trainSet and returns its metrics on testSet
+func RandomForestConcurrent(trainSet, testSet *data.Dataset) (classification.Report, error) {
+	rf := NewRandomForestConcurrent()
+	if err := models.FitDataset(rf, trainSet); err != nil {
+		return classification.Report{}, err
 	}
-
-	// Calculate metrics
-	acc := accuracy(predictions, testLabel)
-	prec := precision(predictions, testLabel, 1)
-	rec := recall(predictions, testLabel, 1)
-	f1 := f1Score(prec, rec)
-
-	fmt.Printf("Accuracy: %.2f\n", acc)
-	fmt.Printf("Precision: %.2f\n", prec)
-	fmt.Printf("Recall: %.2f\n", rec)
-	fmt.Printf("F1 Score: %.2f\n", f1)
-
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+	return classification.EvaluateDataset(rf, testSet)
 }
@@ -1,22 +1,56 @@
 package randomforest
 
 import (
-	"fmt"
+	"io"
+	"maps"
 	"math"
 	"math/rand"
-	"time"
+	"slices"
+	"src/classification"
+	"src/data"
+	"src/models"
 )
 
+var (
+	_ models.Classifier = (*RandomForest)(nil)
+	_ models.Persistent = (*RandomForest)(nil)
+)
+
+// Model kinds used by the serialization format
+const (
+	kindRandomForest     = "random_forest"
+	kindRandomForestConc = "random_forest_concurrent"
+)
+
+func init() {
+	models.Register(kindRandomForest, func() models.Regressor { return NewRandomForest() })
+	models.Register(kindRandomForestConc, func() models.Regressor { return NewRandomForestConcurrent() })
+}
+
 type TreeNode struct {
 	FeatureIndex int
 	Threshold    float64
+	DefaultLeft  bool // Branch taken by missing (NaN) values, learned during training
 	Left, Right  *TreeNode
 	Label        int
 	IsLeaf       bool
 }
 
 type RandomForest struct {
-	Trees []*TreeNode
+	NumTrees int   // Number of trees grown by Fit
+	Seed     int64 // Master seed; each tree draws its bootstrap sample from its own derived stream
+	Trees    []*TreeNode
+}
+
+// NewRandomForest creates a sequential Random Forest with the default number of trees
+func NewRandomForest() *RandomForest {
+	return &RandomForest{NumTrees: 5, Seed: data.DefaultSeed}
+}
+
+// treeSeeds derives one seed per tree from the master seed, so the sequential
+// and concurrent forests grow the same trees regardless of scheduling
+func treeSeeds(seed int64, numTrees int) []int64 {
+	return data.DeriveSeeds(data.NewRand(seed), numTrees)
 }
 
 // Helper function to create a decision tree
@@ -31,13 +65,13 @@ func createTree(data [][]float64, labels []int) *TreeNode {
 	}
 
 	// Find the best split
-	featureIndex, threshold := findBestSplit(data, labels)
+	featureIndex, threshold, defaultLeft := findBestSplit(data, labels)
 	if featureIndex == -1 {
 		return &TreeNode{Label: majorityLabel(labels), IsLeaf: true}
 	}
 
 	// Split data
-	leftData, leftLabels, rightData, rightLabels := splitData(data, labels, featureIndex, threshold)
+	leftData, leftLabels, rightData, rightLabels := splitData(data, labels, featureIndex, threshold, defaultLeft)
 
 	// Create the subtree
 	left := createTree(leftData, leftLabels)
@@ -46,6 +80,7 @@ func createTree(data [][]float64, labels []int) *TreeNode {
 	return &TreeNode{
 		FeatureIndex: featureIndex,
 		Threshold:    threshold,
+		DefaultLeft:  defaultLeft,
 		Left:         left,
 		Right:        right,
 	}
@@ -60,54 +95,83 @@ func allSame(labels []int) bool {
 	return true
 }
 
-func findBestSplit(data [][]float64, labels []int) (int, float64) {
+// findBestSplit returns the best feature, threshold and missing-value branch,
+// or -1 when no threshold separates the rows
+func findBestSplit(data [][]float64, labels []int) (int, float64, bool) {
 	numFeatures := len(data[0])
 	bestFeatureIndex := -1
 	bestThreshold := 0.0
+	bestDefaultLeft := false
 	bestScore := math.Inf(-1)
 
 	for featureIndex := 0; featureIndex < numFeatures; featureIndex++ {
-		threshold, score := bestThresholdForFeature(data, labels, featureIndex)
+		threshold, defaultLeft, score := bestThresholdForFeature(data, labels, featureIndex)
 		if score > bestScore {
 			bestScore = score
 			bestFeatureIndex = featureIndex
 			bestThreshold = threshold
+			bestDefaultLeft = defaultLeft
 		}
 	}
 
-	return bestFeatureIndex, bestThreshold
+	return bestFeatureIndex, bestThreshold, bestDefaultLeft
 }
 
-func bestThresholdForFeature(data [][]float64, labels []int, featureIndex int) (float64, float64) {
+// bestThresholdForFeature tries both branches for the missing values of the
+// feature; when it has none, future missing values follow the larger branch
+func bestThresholdForFeature(data [][]float64, labels []int, featureIndex int) (float64, bool, float64) {
 	bestThreshold := 0.0
+	bestDefaultLeft := false
 	bestScore := math.Inf(-1)
 
+	missing := false
 	values := make(map[float64]bool)
 	for _, row := range data {
+		if math.IsNaN(row[featureIndex]) {
+			missing = true
+			continue
+		}
 		values[row[featureIndex]] = true
 	}
 
-	for value := range values {
+	for _, value := range slices.Sorted(maps.Keys(values)) {
 		threshold := value
-		leftLabels, rightLabels := splitLabels(data, labels, featureIndex, threshold)
-		score := giniIndex(leftLabels, rightLabels)
-		if score > bestScore {
-			bestScore = score
-			bestThreshold = threshold
+		for _, defaultLeft := range []bool{false, true} {
+			if defaultLeft && !missing {
+				break
+			}
+			leftLabels, rightLabels := splitLabels(data, labels, featureIndex, threshold, defaultLeft)
+			if len(leftLabels) == 0 || len(rightLabels) == 0 {
+				continue
+			}
+			score := giniIndex(leftLabels, rightLabels)
+			if score > bestScore {
+				bestScore = score
+				bestThreshold = threshold
+				bestDefaultLeft = defaultLeft || (!missing && len(leftLabels) > len(rightLabels))
+			}
 		}
 	}
 
-	return bestThreshold, bestScore
+	return bestThreshold, bestDefaultLeft, bestScore
 }
 
-func splitData(data [][]float64, labels []int, featureIndex int, threshold float64) ([][]float64, []int, [][]float64, []int) {
+// goesLeft reports whether a value follows the left branch; missing values take the default branch
+func goesLeft(value, threshold float64, defaultLeft bool) bool {
+	if math.IsNaN(value) {
+		return defaultLeft
+	}
+	return value <= threshold
+}
+
+func splitData(data [][]float64, labels []int, featureIndex int, threshold float64, defaultLeft bool) ([][]float64, []int, [][]float64, []int) {
 	var leftData [][]float64
 	var leftLabels []int
 	var rightData [][]float64
 	var rightLabels []int
 
 	for i, row := range data {
-		if row[featureIndex] <= threshold {
+		if goesLeft(row[featureIndex], threshold, defaultLeft) {
 			leftData = append(leftData, row)
 			leftLabels = append(leftLabels, labels[i])
 		} else {
@@ -119,12 +183,12 @@ func splitData(data [][]float64, labels []int, featureIndex int, threshold float
 	return leftData, leftLabels, rightData, rightLabels
 }
 
-func splitLabels(data [][]float64, labels []int, featureIndex int, threshold float64) ([]int, []int) {
+func splitLabels(data [][]float64, labels []int, featureIndex int, threshold float64, defaultLeft bool) ([]int, []int) {
 	var leftLabels []int
 	var rightLabels []int
 
 	for i, row := range data {
-		if row[featureIndex] <= threshold {
+		if goesLeft(row[featureIndex], threshold, defaultLeft) {
 			leftLabels = append(leftLabels, labels[i])
 		} else {
 			rightLabels = append(rightLabels, labels[i])
@@ -157,8 +221,8 @@ func calculateGini(labels []int) float64 {
 
 	size := float64(len(labels))
 	gini := 1.0
-	for _, count := range labelCounts {
-		proportion := float64(count) / size
+	for _, label := range slices.Sorted(maps.Keys(labelCounts)) {
+		proportion := float64(labelCounts[label]) / size
 		gini -= proportion * proportion
 	}
 
@@ -170,156 +234,150 @@ func majorityLabel(labels []int) int {
 	for _, label := range labels {
 		labelCounts[label]++
 	}
+	return mostVoted(labelCounts)
+}
 
+// mostVoted returns the label with the most votes, the smallest one on ties
+func mostVoted(votes map[int]int) int {
 	maxCount := 0
-	var majorityLabel int
-	for label, count := range labelCounts {
-		if count > maxCount {
-			maxCount = count
-			majorityLabel = label
+	var winner int
+	for _, label := range slices.Sorted(maps.Keys(votes)) {
+		if votes[label] > maxCount {
+			maxCount = votes[label]
+			winner = label
 		}
 	}
-
-	return majorityLabel
+	return winner
 }
 
 // Train the Random Forest sequentially
 func (rf *RandomForest) Train(data [][]float64, labels []int, numTrees int) {
-	for i := 0; i < numTrees; i++ {
-		sampledData, sampledLabels := bootstrapSample(data, labels)
+	for _, seed := range treeSeeds(rf.Seed, numTrees) {
+		sampledData, sampledLabels := bootstrapSample(data, labels, rand.New(rand.NewSource(seed)))
 		tree := createTree(sampledData, sampledLabels)
 		rf.Trees = append(rf.Trees, tree)
 	}
 }
 
-// Predict using the Random Forest
-func (rf *RandomForest) Predict(sample []float64) int {
-	votes := make(map[int]int)
-
-	for _, tree := range rf.Trees {
-		label := predictTree(tree, sample)
-		votes[label]++
+// Fit trains NumTrees trees sequentially on X and the integer labels in y
+func (rf *RandomForest) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
 	}
-
-	var maxVotes int
-	var prediction int
-	for label, count := range votes {
-		if count > maxVotes {
-			maxVotes = count
-			prediction = label
-		}
-	}
-
-	return prediction
+	rf.Trees = nil
+	rf.Train(X, toIntLabels(y), rf.NumTrees)
+	return nil
 }
 
-func predictTree(node *TreeNode, sample []float64) int {
-	if node.IsLeaf {
-		return node.Label
+// Predict returns the majority vote for each row of X
+func (rf *RandomForest) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	for i, x := range X {
+		predictions[i] = float64(rf.PredictSample(x))
 	}
+	return predictions
+}
 
-	if sample[node.FeatureIndex] <= node.Threshold {
-		return predictTree(node.Left, sample)
+// PredictProba returns the fraction of trees voting for class 1 for each row of X
+func (rf *RandomForest) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	for i, x := range X {
+		probas[i] = positiveVotes(rf.Trees, x)
 	}
-	return predictTree(node.Right, sample)
+	return probas
 }
 
-// Helper function for bootstrap sampling
-func bootstrapSample(data [][]float64, labels []int) ([][]float64, []int) {
-	n := len(data)
-	sampledData := make([][]float64, n)
-	sampledLabels := make([]int, n)
-	for i := 0; i < n; i++ {
-		index := rand.Intn(n)
-		sampledData[i] = data[index]
-		sampledLabels[i] = labels[index]
-	}
-	return sampledData, sampledLabels
+// Serializable state of a forest
+type forestState struct {
+	NumTrees int
+	Seed     int64
+	Trees    []*TreeNode
 }
 
-// Metrics calculation functions
+// Save writes the forest as JSON
+func (rf *RandomForest) Save(w io.Writer) error {
+	return models.Save(w, kindRandomForest, forestState{rf.NumTrees, rf.Seed, rf.Trees})
+}
 
-func accuracy(predictions, trueLabels []int) float64 {
-	if len(predictions) != len(trueLabels) {
-		return 0
-	}
+// SaveBinary writes the forest in the compact binary format
+func (rf *RandomForest) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindRandomForest, forestState{rf.NumTrees, rf.Seed, rf.Trees})
+}
 
-	correct := 0
-	for i := range predictions {
-		if predictions[i] == trueLabels[i] {
-			correct++
-		}
+// Load reads a forest written by Save or SaveBinary
+func (rf *RandomForest) Load(r io.Reader) error {
+	var st forestState
+	if err := models.Load(r, kindRandomForest, &st); err != nil {
+		return err
 	}
-	return float64(correct) / float64(len(predictions))
+	rf.NumTrees, rf.Seed, rf.Trees = st.NumTrees, st.Seed, st.Trees
+	return nil
 }
 
-func precision(predictions, trueLabels []int, positiveClass int) float64 {
-	truePos, falsePos := 0, 0
-	for i := range predictions {
-		if predictions[i] == positiveClass {
-			if trueLabels[i] == positiveClass {
-				truePos++
-			} else {
-				falsePos++
-			}
-		}
-	}
-	if truePos+falsePos == 0 {
+// Fraction of trees that predict class 1 for the sample
+func positiveVotes(trees []*TreeNode, sample []float64) float64 {
+	if len(trees) == 0 {
 		return 0
 	}
-	return float64(truePos) / float64(truePos+falsePos)
-}
-
-func recall(predictions, trueLabels []int, positiveClass int) float64 {
-	truePos, falseNeg := 0, 0
-	for i := range predictions {
-		if predictions[i] == positiveClass {
-			if trueLabels[i] == positiveClass {
-				truePos++
-			}
-		} else {
-			if trueLabels[i] == positiveClass {
-				falseNeg++
-			}
+	votes := 0
+	for _, tree := range trees {
+		if predictTree(tree, sample) == 1 {
+			votes++
 		}
 	}
-	if truePos+falseNeg == 0 {
-		return 0
-	}
-	return float64(truePos) / float64(truePos+falseNeg)
+	return float64(votes) / float64(len(trees))
 }
 
-func f1Score(precision, recall float64) float64 {
-	if precision+recall == 0 {
-		return 0
+// Convert float labels into the integer classes used by the trees
+func toIntLabels(y []float64) []int {
+	labels := make([]int, len(y))
+	for i, v := range y {
+		labels[i] = int(v)
 	}
-	return 2 * (precision * recall) / (precision + recall)
+	return labels
 }
 
-func RandomForestSecuential(data [][]float64, labels []int, test [][]float64, testLabel []int) {
-
-	start := time.Now()
+// PredictSample returns the majority vote of the forest for a single sample
+func (rf *RandomForest) PredictSample(sample []float64) int {
+	votes := make(map[int]int)
 
-	rf := &RandomForest{}
-	rf.Train(data, labels, 5)
+	for _, tree := range rf.Trees {
+		label := predictTree(tree, sample)
+		votes[label]++
+	}
 
-	predictions := make([]int, len(test))
+	return mostVoted(votes)
+}
 
-	for i, sample := range test {
-		predictions[i] = rf.Predict(sample)
+func predictTree(node *TreeNode, sample []float64) int {
+	if node.IsLeaf {
+		return node.Label
 	}
 
-	// Calculate metrics
-	acc := accuracy(predictions, testLabel)
-	prec := precision(predictions, testLabel, 1)
-	rec := recall(predictions, testLabel, 1)
-	f1 := f1Score(prec, rec)
+	if goesLeft(sample[node.FeatureIndex], node.Threshold, node.DefaultLeft) {
+		return predictTree(node.Left, sample)
+	}
+	return predictTree(node.Right, sample)
+}
 
-	fmt.Printf("Accuracy: %.2f\n", acc)
-	fmt.Printf("Precision: %.2f\n", prec)
-	fmt.Printf("Recall: %.2f\n", rec)
-	fmt.Printf("F1 Score: %.2f\n", f1)
+// Helper function for bootstrap sampling with the given random stream
+func bootstrapSample(data [][]float64, labels []int, rng *rand.Rand) ([][]float64, []int) {
+	n := len(data)
+	sampledData := make([][]float64, n)
+	sampledLabels := make([]int, n)
+	for i := 0; i < n; i++ {
+		index := rng.Intn(n)
+		sampledData[i] = data[index]
+		sampledLabels[i] = labels[index]
+	}
+	return sampledData, sampledLabels
+}
 
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución %s\n", elapsed)
+// RandomForestSecuential trains a forest on trainSet and returns its metrics on testSet
+func RandomForestSecuential(trainSet, testSet *data.Dataset) (classification.Report, error) {
+	rf := NewRandomForest()
+	if err := models.FitDataset(rf, trainSet); err != nil {
+		return classification.Report{}, err
+	}
+	return classification.EvaluateDataset(rf, testSet)
 }
@@ -0,0 +1,77 @@
+package randomforest
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Two features; the class is 1 when their sum is at least 10
+func sumAbove() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 10 {
+		for j := range 10 {
+			label := 0.0
+			if i+j >= 10 {
+				label = 1
+			}
+			X, y = append(X, []float64{float64(i), float64(j)}), append(y, label)
+		}
+	}
+	return X, y
+}
+
+func TestSequentialAndConcurrentGrowSameForest(t *testing.T) {
+	X, y := sumAbove()
+	seq, con := NewRandomForest(), NewRandomForestConcurrent()
+	seq.NumTrees, con.NumTrees = 8, 8
+	if err := seq.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(seq.Trees, con.Trees) {
+		t.Error("the sequential and concurrent forests differ for the same seed")
+	}
+	if !reflect.DeepEqual(seq.PredictProba(X), con.PredictProba(X)) {
+		t.Error("the sequential and concurrent probabilities differ")
+	}
+}
+
+func TestSeedChangesBootstrap(t *testing.T) {
+	X, y := sumAbove()
+	fit := func(seed int64) []*TreeNode {
+		rf := NewRandomForest()
+		rf.Seed = seed
+		if err := rf.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		return rf.Trees
+	}
+	if !reflect.DeepEqual(fit(7), fit(7)) {
+		t.Error("two fits with the same seed grew different forests")
+	}
+	if reflect.DeepEqual(fit(7), fit(8)) {
+		t.Error("different seeds grew the same forest")
+	}
+}
+
+func TestRefitReplacesTrees(t *testing.T) {
+	X, y := sumAbove()
+	rf := NewRandomForestConcurrent()
+	for range 2 {
+		if err := rf.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(rf.Trees) != rf.NumTrees {
+		t.Errorf("%d trees after two fits, want %d", len(rf.Trees), rf.NumTrees)
+	}
+}
+
+func TestMostVotedBreaksTiesBySmallestLabel(t *testing.T) {
+	if got := mostVoted(map[int]int{3: 2, 1: 2, 0: 1}); got != 1 {
+		t.Errorf("mostVoted = %d, want 1", got)
+	}
+}
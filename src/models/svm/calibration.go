@@ -0,0 +1,302 @@
+package svm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"src/data"
+	"sync"
+)
+
+// Calibration es el método que convierte el margen del SVM en una probabilidad
+type Calibration int
+
+const (
+	NoCalibration Calibration = iota // Sigmoide del margen, sin calibrar
+	Platt                            // Sigmoide 1/(1 + exp(A·f + B)) ajustada por máxima verosimilitud
+	Isotonic                         // Función escalonada no decreciente ajustada con PAV
+)
+
+func (c Calibration) String() string {
+	switch c {
+	case NoCalibration:
+		return "none"
+	case Platt:
+		return "platt"
+	case Isotonic:
+		return "isotonic"
+	}
+	return fmt.Sprintf("Calibration(%d)", int(c))
+}
+
+// ParseCalibration convierte "none", "platt" o "isotonic" en su Calibration
+func ParseCalibration(name string) (Calibration, error) {
+	for _, c := range []Calibration{NoCalibration, Platt, Isotonic} {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("calibración desconocida %q (use none, platt o isotonic)", name)
+}
+
+// Calibrator convierte márgenes en probabilidades de la clase 1 con los
+// parámetros ajustados en Fit
+type Calibrator struct {
+	Method  Calibration
+	A, B    float64   // Platt: P(y=1|f) = 1/(1 + exp(A·f + B))
+	Margins []float64 // Isotonic: extremos de los escalones, en orden creciente
+	Probas  []float64 // Isotonic: fracción de positivos en cada extremo
+}
+
+// Probability devuelve la probabilidad de la clase 1 para el margen dado
+func (c Calibrator) Probability(margin float64) float64 {
+	switch c.Method {
+	case Platt:
+		return stableSigmoid(-(c.A*margin + c.B))
+	case Isotonic:
+		return c.interpolate(margin)
+	}
+	return sigmoid(margin)
+}
+
+// Sigmoide 1/(1 + exp(-x)) sin desbordar exp para |x| grande
+func stableSigmoid(x float64) float64 {
+	if x >= 0 {
+		return 1 / (1 + math.Exp(-x))
+	}
+	e := math.Exp(x)
+	return e / (1 + e)
+}
+
+// Interpola linealmente entre los escalones; fuera de ellos usa el extremo más cercano
+func (c Calibrator) interpolate(margin float64) float64 {
+	n := len(c.Margins)
+	if n == 0 {
+		return sigmoid(margin)
+	}
+	i, _ := slices.BinarySearch(c.Margins, margin)
+	switch {
+	case i == 0:
+		return c.Probas[0]
+	case i == n:
+		return c.Probas[n-1]
+	}
+	lo, hi := c.Margins[i-1], c.Margins[i]
+	w := (margin - lo) / (hi - lo)
+	return c.Probas[i-1] + w*(c.Probas[i]-c.Probas[i-1])
+}
+
+// Folds por defecto de la validación cruzada interna
+const defaultCalibrationFolds = 5
+
+// Ajusta el calibrador con los márgenes fuera de fold de X: cada fold se
+// predice con el modelo que devuelve fit entrenado en el resto de filas, así
+// la calibración no ve márgenes de filas con las que se entrenó. Si parallel,
+// cada fold se entrena en su propia goroutine
+func fitCalibrator(method Calibration, X [][]float64, y []float64, folds int, seed int64, parallel bool, fit func(X [][]float64, y []float64) (Model, error)) (Calibrator, error) {
+	if method == NoCalibration {
+		return Calibrator{}, nil
+	}
+	if method != Platt && method != Isotonic {
+		return Calibrator{}, fmt.Errorf("calibración desconocida %v", method)
+	}
+	if folds <= 0 {
+		folds = defaultCalibrationFolds
+	}
+	split, err := data.StratifiedKFold(y, folds, data.NewRand(seed))
+	if err != nil {
+		return Calibrator{}, fmt.Errorf("calibración: %w", err)
+	}
+
+	margins := make([]float64, len(y))
+	errs := make([]error, len(split))
+	predictFold := func(k int) {
+		inFold := make([]bool, len(y))
+		for _, i := range split[k] {
+			inFold[i] = true
+		}
+		var trainX [][]float64
+		var trainY []float64
+		for i := range y {
+			if !inFold[i] {
+				trainX, trainY = append(trainX, X[i]), append(trainY, y[i])
+			}
+		}
+		model, err := fit(trainX, trainY)
+		if err != nil {
+			errs[k] = err
+			return
+		}
+		testX := make([][]float64, len(split[k]))
+		for j, i := range split[k] {
+			testX[j] = X[i]
+		}
+		for j, m := range model.DecisionFunction(testX) {
+			margins[split[k][j]] = m
+		}
+	}
+	if parallel {
+		var wg sync.WaitGroup
+		wg.Add(len(split))
+		for k := range split {
+			go func() {
+				defer wg.Done()
+				predictFold(k)
+			}()
+		}
+		wg.Wait()
+	} else {
+		for k := range split {
+			predictFold(k)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Calibrator{}, fmt.Errorf("calibración: %w", err)
+	}
+
+	positive := make([]bool, len(y))
+	for i, label := range y {
+		positive[i] = label == 1
+	}
+	if method == Platt {
+		a, b := fitPlatt(margins, positive)
+		return Calibrator{Method: Platt, A: a, B: b}, nil
+	}
+	steps, probas := fitIsotonic(margins, positive)
+	return Calibrator{Method: Isotonic, Margins: steps, Probas: probas}, nil
+}
+
+// Ajusta A y B de Platt con el método de Newton con búsqueda en línea de Lin,
+// Lin y Weng (2007). Los objetivos se suavizan a (N₊+1)/(N₊+2) y 1/(N₋+2)
+// para no sobreajustar cuando las clases están separadas
+func fitPlatt(margins []float64, positive []bool) (float64, float64) {
+	var pos, neg float64
+	for _, p := range positive {
+		if p {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	hiTarget, loTarget := (pos+1)/(pos+2), 1/(neg+2)
+	targets := make([]float64, len(margins))
+	for i, p := range positive {
+		if p {
+			targets[i] = hiTarget
+		} else {
+			targets[i] = loTarget
+		}
+	}
+
+	// Log-verosimilitud negativa, escrita para que exp no desborde
+	objective := func(a, b float64) float64 {
+		sum := 0.0
+		for i, f := range margins {
+			fApB := f*a + b
+			if fApB >= 0 {
+				sum += targets[i]*fApB + math.Log1p(math.Exp(-fApB))
+			} else {
+				sum += (targets[i]-1)*fApB + math.Log1p(math.Exp(fApB))
+			}
+		}
+		return sum
+	}
+
+	const (
+		maxIter = 100
+		minStep = 1e-10
+		sigma   = 1e-12 // Se suma a la diagonal del hessiano para que sea invertible
+	)
+	a, b := 0.0, math.Log((neg+1)/(pos+1))
+	value := objective(a, b)
+	for range maxIter {
+		h11, h22, h21, g1, g2 := sigma, sigma, 0.0, 0.0, 0.0
+		for i, f := range margins {
+			p := stableSigmoid(-(f*a + b))
+			d2 := p * (1 - p)
+			h11 += f * f * d2
+			h22 += d2
+			h21 += f * d2
+			d1 := targets[i] - p
+			g1 += f * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+
+		det := h11*h22 - h21*h21
+		dA := -(h22*g1 - h21*g2) / det
+		dB := -(-h21*g1 + h11*g2) / det
+		gd := g1*dA + g2*dB
+		step := 1.0
+		for ; step >= minStep; step /= 2 {
+			newA, newB := a+step*dA, b+step*dB
+			if newValue := objective(newA, newB); newValue < value+1e-4*step*gd {
+				a, b, value = newA, newB, newValue
+				break
+			}
+		}
+		if step < minStep {
+			break
+		}
+	}
+	return a, b
+}
+
+// Ajusta una función no decreciente de margen a probabilidad con PAV (pool
+// adjacent violators): recorre los márgenes en orden y funde los bloques
+// vecinos mientras la fracción de positivos decrezca. Cada bloque aporta su
+// margen mínimo y máximo con su fracción de positivos
+func fitIsotonic(margins []float64, positive []bool) ([]float64, []float64) {
+	type block struct {
+		lo, hi           float64
+		positives, count float64
+	}
+	var blocks []block
+	for _, i := range sortIndices(margins) {
+		b := block{lo: margins[i], hi: margins[i], count: 1}
+		if positive[i] {
+			b.positives = 1
+		}
+		for n := len(blocks); n > 0; n = len(blocks) {
+			last := blocks[n-1]
+			// Los márgenes iguales van siempre en el mismo bloque
+			if last.hi != b.lo && last.positives/last.count < b.positives/b.count {
+				break
+			}
+			b.lo, b.positives, b.count = last.lo, b.positives+last.positives, b.count+last.count
+			blocks = blocks[:n-1]
+		}
+		blocks = append(blocks, b)
+	}
+
+	var steps, probas []float64
+	for _, b := range blocks {
+		p := b.positives / b.count
+		steps, probas = append(steps, b.lo), append(probas, p)
+		if b.hi > b.lo {
+			steps, probas = append(steps, b.hi), append(probas, p)
+		}
+	}
+	return steps, probas
+}
+
+// Índices de values ordenados por valor creciente
+func sortIndices(values []float64) []int {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		switch {
+		case values[a] < values[b]:
+			return -1
+		case values[a] > values[b]:
+			return 1
+		}
+		return 0
+	})
+	return order
+}
@@ -0,0 +1,108 @@
+package svm
+
+import (
+	"math"
+	"slices"
+	"src/models"
+	"testing"
+)
+
+// Una característica en [-2, 2) con la clase 1 para x > 0 y una de cada
+// cinco etiquetas invertida, para que las clases se solapen
+func overlapping() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 200 {
+		x := float64(i)/50 - 2
+		label := 0.0
+		if x > 0 {
+			label = 1
+		}
+		if i%5 == 0 {
+			label = 1 - label
+		}
+		X, y = append(X, []float64{x}), append(y, label)
+	}
+	return X, y
+}
+
+func TestFitIsotonic(t *testing.T) {
+	// El 3 negativo viola el orden tras el 2 positivo y ambos se funden
+	steps, probas := fitIsotonic([]float64{4, 2, 3, 1}, []bool{true, true, false, false})
+	if want := []float64{1, 2, 3, 4}; !slices.Equal(steps, want) {
+		t.Errorf("escalones = %v, se esperaba %v", steps, want)
+	}
+	if want := []float64{0, 0.5, 0.5, 1}; !slices.Equal(probas, want) {
+		t.Errorf("probabilidades = %v, se esperaba %v", probas, want)
+	}
+
+	c := Calibrator{Method: Isotonic, Margins: steps, Probas: probas}
+	for _, tt := range []struct{ margin, want float64 }{{0, 0}, {1.5, 0.25}, {2.5, 0.5}, {10, 1}} {
+		if got := c.Probability(tt.margin); got != tt.want {
+			t.Errorf("Probability(%v) = %v, se esperaba %v", tt.margin, got, tt.want)
+		}
+	}
+}
+
+func TestFitPlatt(t *testing.T) {
+	X, y := overlapping()
+	margins := make([]float64, len(X))
+	positive := make([]bool, len(y))
+	for i := range X {
+		margins[i], positive[i] = X[i][0], y[i] == 1
+	}
+	a, b := fitPlatt(margins, positive)
+	// La probabilidad crece con el margen y vale 1/2 cerca de 0
+	if a >= 0 {
+		t.Errorf("A = %v, se esperaba negativo", a)
+	}
+	if p := stableSigmoid(-b); math.Abs(p-0.5) > 0.1 {
+		t.Errorf("P(margen 0) = %v, se esperaba cerca de 0.5", p)
+	}
+}
+
+func TestSVMCalibratedProbabilities(t *testing.T) {
+	X, y := overlapping()
+	for _, method := range []Calibration{Platt, Isotonic} {
+		t.Run(method.String(), func(t *testing.T) {
+			svm := NewSVM()
+			svm.Epochs, svm.Calibration = 10, method
+			if err := svm.Fit(X, y); err != nil {
+				t.Fatal(err)
+			}
+			if svm.Calibrator().Method != method {
+				t.Fatalf("calibrador %v, se esperaba %v", svm.Calibrator().Method, method)
+			}
+
+			// Las filas están ordenadas por x, así que el margen y la probabilidad no bajan
+			probas := svm.PredictProba(X)
+			for i, p := range probas {
+				if p < 0 || p > 1 || math.IsNaN(p) {
+					t.Fatalf("probabilidad %v fuera de [0, 1]", p)
+				}
+				if i > 0 && p < probas[i-1] {
+					t.Fatalf("la probabilidad baja de %v a %v al crecer el margen", probas[i-1], p)
+				}
+			}
+
+			clone, err := models.Clone(svm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(clone.(*SVM).PredictProba(X), probas) {
+				t.Error("el clon no conserva el calibrador")
+			}
+		})
+	}
+}
+
+func TestParseCalibration(t *testing.T) {
+	for _, c := range []Calibration{NoCalibration, Platt, Isotonic} {
+		if got, err := ParseCalibration(c.String()); err != nil || got != c {
+			t.Errorf("ParseCalibration(%q) = %v, %v", c, got, err)
+		}
+	}
+	if _, err := ParseCalibration("sigmoid"); err == nil {
+		t.Error("un método desconocido debe dar error")
+	}
+}
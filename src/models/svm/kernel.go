@@ -0,0 +1,144 @@
+package svm
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"src/models"
+)
+
+// Model es la API de predicción común a los SVM lineales y con kernel
+type Model interface {
+	models.Classifier
+	DecisionFunction(X [][]float64) []float64
+}
+
+var (
+	_ Model = (*SVM)(nil)
+	_ Model = (*SVMC)(nil)
+	_ Model = (*KernelSVM)(nil)
+	_ Model = (*KernelSVMC)(nil)
+)
+
+// KernelType es la función de kernel de un SVM
+type KernelType int
+
+const (
+	Linear     KernelType = iota // x·z
+	RBF                          // exp(-γ‖x - z‖²)
+	Polynomial                   // (γ x·z + coef0)^grado
+	Sigmoid                      // tanh(γ x·z + coef0)
+)
+
+func (k KernelType) String() string {
+	switch k {
+	case Linear:
+		return "linear"
+	case RBF:
+		return "rbf"
+	case Polynomial:
+		return "poly"
+	case Sigmoid:
+		return "sigmoid"
+	}
+	return fmt.Sprintf("KernelType(%d)", int(k))
+}
+
+// ParseKernel convierte "linear", "rbf", "poly" o "sigmoid" en su KernelType
+func ParseKernel(name string) (KernelType, error) {
+	for _, k := range []KernelType{Linear, RBF, Polynomial, Sigmoid} {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("kernel desconocido %q (use linear, rbf, poly o sigmoid)", name)
+}
+
+// Kernel es una función de kernel con sus parámetros
+type Kernel struct {
+	Type   KernelType
+	Gamma  float64 // γ de rbf, poly y sigmoid; 0 usa 1/número de características
+	Degree int     // Grado de poly; 0 usa 3
+	Coef0  float64 // Término independiente de poly y sigmoid
+}
+
+// Con los valores por defecto resueltos para d características
+func (k Kernel) resolve(d int) Kernel {
+	if k.Gamma == 0 {
+		k.Gamma = 1 / float64(d)
+	}
+	if k.Degree == 0 {
+		k.Degree = 3
+	}
+	return k
+}
+
+// Eval calcula K(a, b)
+func (k Kernel) Eval(a, b []float64) float64 {
+	switch k.Type {
+	case RBF:
+		dist := 0.0
+		for i := range a {
+			d := a[i] - b[i]
+			dist += d * d
+		}
+		return math.Exp(-k.Gamma * dist)
+	case Polynomial:
+		return math.Pow(k.Gamma*dot(a, b)+k.Coef0, float64(k.Degree))
+	case Sigmoid:
+		return math.Tanh(k.Gamma*dot(a, b) + k.Coef0)
+	}
+	return dot(a, b)
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// Caché LRU de filas de la matriz de kernel K(xᵢ, ·) sobre el conjunto de
+// entrenamiento. Al llenarse reutiliza la fila usada hace más tiempo
+type kernelCache struct {
+	capacity int
+	rows     map[int]*list.Element
+	lru      *list.List // Frente: la fila usada más recientemente
+	compute  func(i int, row []float64)
+	n        int
+}
+
+// Fila de la caché
+type cachedRow struct {
+	index int
+	row   []float64
+}
+
+// Caché de filas de n valores que ocupa como mucho cacheMB megabytes
+func newKernelCache(n int, cacheMB float64, compute func(i int, row []float64)) *kernelCache {
+	// SMO necesita dos filas a la vez
+	capacity := max(int(cacheMB*(1<<20)/float64(8*n)), 2)
+	return &kernelCache{capacity: capacity, rows: make(map[int]*list.Element), lru: list.New(), compute: compute, n: n}
+}
+
+// Fila i de la matriz de kernel, calculándola si no está en la caché
+func (c *kernelCache) row(i int) []float64 {
+	if e, ok := c.rows[i]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cachedRow).row
+	}
+	var entry *cachedRow
+	if c.lru.Len() < c.capacity {
+		entry = &cachedRow{row: make([]float64, c.n)}
+	} else {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		entry = oldest.Value.(*cachedRow)
+		delete(c.rows, entry.index)
+	}
+	entry.index = i
+	c.compute(i, entry.row)
+	c.rows[i] = c.lru.PushFront(entry)
+	return entry.row
+}
@@ -0,0 +1,104 @@
+package svm
+
+import (
+	"io"
+	"src/classification"
+	"src/models"
+)
+
+var (
+	_ models.Classifier = (*KernelSVMC)(nil)
+	_ models.Persistent = (*KernelSVMC)(nil)
+)
+
+// KernelSVMC es el SVM con kernel concurrente: SMO es el mismo que en
+// KernelSVM, pero cada fila de kernel que falta en la caché y cada predicción
+// se calculan repartiendo las filas entre Workers goroutines
+type KernelSVMC struct {
+	KernelSVM
+	Workers int // Goroutines por fila de kernel o predicción; 0 usa GOMAXPROCS
+}
+
+// NewKernelSVMConcurrent crea un SVM con kernel concurrente con los hiperparámetros por defecto
+func NewKernelSVMConcurrent() *KernelSVMC {
+	return &KernelSVMC{KernelSVM: *NewKernelSVM()}
+}
+
+// Fit entrena el modelo calculando las filas de kernel en paralelo
+func (svm *KernelSVMC) Fit(X [][]float64, y []float64) error {
+	return svm.fit(X, y, parallelRows(X, svm.Workers))
+}
+
+// Calcula cada fila de kernel sobre X repartiendo sus columnas entre goroutines
+func parallelRows(X [][]float64, workers int) func(kernel Kernel, i int, row []float64) {
+	return func(kernel Kernel, i int, row []float64) {
+		classification.MapReduce(len(X), rowChunk, workers, func(lo, hi int) struct{} {
+			for t := lo; t < hi; t++ {
+				row[t] = kernel.Eval(X[i], X[t])
+			}
+			return struct{}{}
+		}, func(a, _ struct{}) struct{} { return a })
+	}
+}
+
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (svm *KernelSVMC) Predict(X [][]float64) []float64 {
+	return labelsOf(svm.DecisionFunction(X))
+}
+
+// DecisionFunction devuelve el margen de cada fila de X, repartiendo las filas entre goroutines
+func (svm *KernelSVMC) DecisionFunction(X [][]float64) []float64 {
+	return parallelDecisions(X, svm.Workers, svm.decision)
+}
+
+// Aplica decision a cada fila de X repartiendo las filas entre goroutines
+func parallelDecisions(X [][]float64, workers int, decision func(x []float64) float64) []float64 {
+	values := make([]float64, len(X))
+	classification.MapReduce(len(X), rowChunk, workers, func(lo, hi int) struct{} {
+		for i := lo; i < hi; i++ {
+			values[i] = decision(X[i])
+		}
+		return struct{}{}
+	}, func(a, _ struct{}) struct{} { return a })
+	return values
+}
+
+// PredictProba devuelve la sigmoide del margen de cada fila de X (sin calibrar)
+func (svm *KernelSVMC) PredictProba(X [][]float64) []float64 {
+	probas := svm.DecisionFunction(X)
+	for i, m := range probas {
+		probas[i] = sigmoid(m)
+	}
+	return probas
+}
+
+// Estado serializable del SVM con kernel concurrente
+type kernelSVMCState struct {
+	Model   kernelSVMState
+	Workers int
+}
+
+// Save guarda el modelo en JSON
+func (svm *KernelSVMC) Save(w io.Writer) error {
+	return models.Save(w, kindKernelSVMC, svm.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svm *KernelSVMC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindKernelSVMC, svm.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svm *KernelSVMC) Load(r io.Reader) error {
+	var st kernelSVMCState
+	if err := models.Load(r, kindKernelSVMC, &st); err != nil {
+		return err
+	}
+	svm.restore(st.Model)
+	svm.Workers = st.Workers
+	return nil
+}
+
+func (svm *KernelSVMC) state() kernelSVMCState {
+	return kernelSVMCState{svm.KernelSVM.state(), svm.Workers}
+}
@@ -0,0 +1,413 @@
+package svm
+
+import (
+	"errors"
+	"io"
+	"math"
+	"src/models"
+)
+
+var (
+	_ models.Classifier = (*KernelSVM)(nil)
+	_ models.Persistent = (*KernelSVM)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindKernelSVM  = "kernel_svm"
+	kindKernelSVMC = "kernel_svm_concurrent"
+)
+
+func init() {
+	models.Register(kindKernelSVM, func() models.Regressor { return NewKernelSVM() })
+	models.Register(kindKernelSVMC, func() models.Regressor { return NewKernelSVMConcurrent() })
+}
+
+var errOneClass = errors.New("el SVM necesita filas de ambas clases")
+
+// KernelSVM es un SVM con kernel entrenado con SMO (Sequential Minimal
+// Optimization) sobre el problema dual
+//
+//	min ½ Σᵢⱼ αᵢαⱼyᵢyⱼK(xᵢ, xⱼ) - Σᵢ αᵢ   con 0 <= αᵢ <= C y Σᵢ αᵢyᵢ = 0
+//
+// Cada iteración optimiza el par de αs que más viola las condiciones KKT,
+// elegido con información de segundo orden. Las etiquetas se llevan a ±1
+// como en SVM. Tras el entrenamiento solo se guardan los vectores de soporte
+type KernelSVM struct {
+	// Hiperparámetros usados por Fit
+	Kernel  Kernel
+	C       float64 // Penalización de las violaciones del margen; 0 usa 1
+	Tol     float64 // Tolerancia de las condiciones KKT; 0 usa 1e-3
+	MaxIter int     // Máximo de iteraciones de SMO; 0 usa max(10⁷, 100n)
+	CacheMB float64 // Memoria de la caché de filas de kernel en MB (cache_size de LIBSVM); 0 usa 100
+
+	expansion  // Vectores de soporte (filas con α > 0) con coeficientes αᵢyᵢ
+	iterations int
+}
+
+// Función de decisión Σ coefᵢK(svᵢ, x) + b de los SVM con kernel
+type expansion struct {
+	kernel  Kernel      // Kernel con los valores por defecto resueltos
+	support [][]float64 // Vectores de soporte
+	coefs   []float64   // Coeficiente de cada vector de soporte
+	bias    float64
+}
+
+// NewKernelSVM crea un SVM con kernel RBF y los hiperparámetros por defecto
+func NewKernelSVM() *KernelSVM {
+	return &KernelSVM{Kernel: Kernel{Type: RBF}, C: 1, Tol: 1e-3, CacheMB: 100}
+}
+
+// Fit entrena el modelo secuencialmente con X e y
+func (svm *KernelSVM) Fit(X [][]float64, y []float64) error {
+	return svm.fit(X, y, sequentialRows(X))
+}
+
+// Calcula cada fila de kernel sobre X secuencialmente
+func sequentialRows(X [][]float64) func(kernel Kernel, i int, row []float64) {
+	return func(kernel Kernel, i int, row []float64) {
+		for t := range X {
+			row[t] = kernel.Eval(X[i], X[t])
+		}
+	}
+}
+
+// Valida los datos, resuelve el dual con las filas de kernel de computeRow y
+// conserva los vectores de soporte
+func (svm *KernelSVM) fit(X [][]float64, y []float64, computeRow func(kernel Kernel, i int, row []float64)) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	if svm.C < 0 || svm.Tol < 0 {
+		return errors.New("C y la tolerancia no pueden ser negativas")
+	}
+	labels := signedLabels(y)
+	positives := 0
+	for _, label := range labels {
+		if label > 0 {
+			positives++
+		}
+	}
+	if positives == 0 || positives == len(labels) {
+		return errOneClass
+	}
+
+	svm.kernel = svm.Kernel.resolve(len(X[0]))
+	s := newSMO(X, labels, svm.kernel, svm.C, svm.Tol, svm.MaxIter, svm.CacheMB, computeRow)
+	alpha, bias, iterations := s.solve()
+
+	svm.support, svm.coefs = nil, nil
+	for i, a := range alpha {
+		if a > 0 {
+			svm.support = append(svm.support, X[i])
+			svm.coefs = append(svm.coefs, a*labels[i])
+		}
+	}
+	svm.bias, svm.iterations = bias, iterations
+	return nil
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// Calcula el margen Σ coefᵢK(svᵢ, x) + b de una fila
+func (e *expansion) decision(x []float64) float64 {
+	sum := e.bias
+	for i, sv := range e.support {
+		sum += e.coefs[i] * e.kernel.Eval(sv, x)
+	}
+	return sum
+}
+
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (svm *KernelSVM) Predict(X [][]float64) []float64 {
+	return labelsOf(svm.DecisionFunction(X))
+}
+
+// DecisionFunction devuelve el margen de cada fila de X
+func (svm *KernelSVM) DecisionFunction(X [][]float64) []float64 {
+	margins := make([]float64, len(X))
+	for i, x := range X {
+		margins[i] = svm.decision(x)
+	}
+	return margins
+}
+
+// PredictProba devuelve la sigmoide del margen de cada fila de X (sin calibrar)
+func (svm *KernelSVM) PredictProba(X [][]float64) []float64 {
+	probas := svm.DecisionFunction(X)
+	for i, m := range probas {
+		probas[i] = sigmoid(m)
+	}
+	return probas
+}
+
+// Etiqueta 1 para los márgenes >= 0 y 0 para el resto
+func labelsOf(margins []float64) []float64 {
+	labels := make([]float64, len(margins))
+	for i, m := range margins {
+		if m >= 0 {
+			labels[i] = 1
+		}
+	}
+	return labels
+}
+
+// SupportVectors devuelve los vectores de soporte del último Fit
+func (svm *KernelSVM) SupportVectors() [][]float64 {
+	return svm.support
+}
+
+// DualCoefs devuelve αᵢyᵢ de cada vector de soporte, en el orden de SupportVectors
+func (svm *KernelSVM) DualCoefs() []float64 {
+	return svm.coefs
+}
+
+// Iterations devuelve las iteraciones de SMO del último Fit
+func (svm *KernelSVM) Iterations() int {
+	return svm.iterations
+}
+
+// Estado serializable del SVM con kernel
+type kernelSVMState struct {
+	Kernel   Kernel
+	C        float64
+	Tol      float64
+	MaxIter  int
+	CacheMB  float64
+	Resolved Kernel
+	Support  [][]float64
+	Coefs    []float64
+	Bias     float64
+}
+
+// Save guarda el modelo en JSON
+func (svm *KernelSVM) Save(w io.Writer) error {
+	return models.Save(w, kindKernelSVM, svm.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svm *KernelSVM) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindKernelSVM, svm.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svm *KernelSVM) Load(r io.Reader) error {
+	var st kernelSVMState
+	if err := models.Load(r, kindKernelSVM, &st); err != nil {
+		return err
+	}
+	svm.restore(st)
+	return nil
+}
+
+func (svm *KernelSVM) state() kernelSVMState {
+	return kernelSVMState{svm.Kernel, svm.C, svm.Tol, svm.MaxIter, svm.CacheMB, svm.kernel, svm.support, svm.coefs, svm.bias}
+}
+
+func (svm *KernelSVM) restore(st kernelSVMState) {
+	svm.Kernel, svm.C, svm.Tol, svm.MaxIter, svm.CacheMB = st.Kernel, st.C, st.Tol, st.MaxIter, st.CacheMB
+	svm.kernel, svm.support, svm.coefs, svm.bias = st.Resolved, st.Support, st.Coefs, st.Bias
+}
+
+// Estado de SMO para el dual general
+//
+//	min ½ Σᵢⱼ αᵢαⱼyᵢyⱼKᵢⱼ + Σᵢ pᵢαᵢ   con 0 <= αᵢ <= C y Σᵢ αᵢyᵢ = 0
+//
+// con pᵢ = -1 en clasificación. El gradiente, Gᵢ = yᵢ Σⱼ αⱼyⱼKᵢⱼ + pᵢ, se
+// mantiene actualizado con las dos filas de kernel del par optimizado
+type smo struct {
+	labels  []float64
+	linear  []float64 // pᵢ; nil usa -1
+	c, tol  float64
+	maxIter int
+	cache   *kernelCache
+	samples int          // Filas de X; la variable t usa la fila t mod samples
+	buffers [2][]float64 // Filas de kernel de las variables cuando hay más variables que filas
+	diag    []float64    // Kᵢᵢ
+	alpha   []float64
+	grad    []float64
+}
+
+// Prepara SMO para las variables de labels sobre las filas de X, con los
+// valores por defecto de C, la tolerancia y las iteraciones resueltos. Si hay
+// más variables que filas, la variable t corresponde a la fila t mod len(X)
+func newSMO(X [][]float64, labels []float64, kernel Kernel, c, tol float64, maxIter int, cacheMB float64, computeRow func(kernel Kernel, i int, row []float64)) *smo {
+	s := &smo{
+		labels:  labels,
+		c:       orDefault(c, 1),
+		tol:     orDefault(tol, 1e-3),
+		maxIter: maxIter,
+		samples: len(X),
+	}
+	if s.maxIter <= 0 {
+		s.maxIter = max(10_000_000, 100*len(labels))
+	}
+	s.cache = newKernelCache(len(X), orDefault(cacheMB, 100), func(i int, row []float64) {
+		computeRow(kernel, i, row)
+	})
+	if len(labels) != len(X) {
+		s.buffers = [2][]float64{make([]float64, len(labels)), make([]float64, len(labels))}
+	}
+	s.diag = make([]float64, len(labels))
+	for t := range labels {
+		x := X[t%len(X)]
+		s.diag[t] = kernel.Eval(x, x)
+	}
+	return s
+}
+
+// Fila de kernel de la variable i, K(xᵢ, xₜ) para cada variable t. Si hay más
+// variables que filas se copia en el búfer indicado, así las dos filas del
+// par siguen siendo válidas a la vez
+func (s *smo) row(i, buffer int) []float64 {
+	row := s.cache.row(i % s.samples)
+	if s.buffers[buffer] == nil {
+		return row
+	}
+	dst := s.buffers[buffer]
+	for start := 0; start < len(dst); start += s.samples {
+		copy(dst[start:], row)
+	}
+	return dst
+}
+
+// Valor mínimo de la curvatura del par, para kernels no definidos positivos
+const tau = 1e-12
+
+// Resuelve el dual y devuelve los αs, el sesgo y las iteraciones realizadas
+func (s *smo) solve() ([]float64, float64, int) {
+	n := len(s.labels)
+	s.alpha = make([]float64, n)
+	s.grad = make([]float64, n)
+	for i := range s.grad {
+		s.grad[i] = -1
+		if s.linear != nil {
+			s.grad[i] = s.linear[i]
+		}
+	}
+
+	iter := 0
+	for ; iter < s.maxIter; iter++ {
+		i, j := s.workingSet()
+		if i < 0 {
+			break
+		}
+		s.update(i, j)
+	}
+	return s.alpha, s.bias(), iter
+}
+
+// Puede αₜ moverse en la dirección que aumenta yₜαₜ
+func (s *smo) inUp(t int) bool {
+	return (s.labels[t] > 0 && s.alpha[t] < s.c) || (s.labels[t] < 0 && s.alpha[t] > 0)
+}
+
+// Puede αₜ moverse en la dirección que reduce yₜαₜ
+func (s *smo) inLow(t int) bool {
+	return (s.labels[t] > 0 && s.alpha[t] > 0) || (s.labels[t] < 0 && s.alpha[t] < s.c)
+}
+
+// Elige el par (i, j): i maximiza -yᵢGᵢ y j es el que más reduce el objetivo
+// según su aproximación de segundo orden. Devuelve -1 si se cumplen las
+// condiciones KKT con la tolerancia
+func (s *smo) workingSet() (int, int) {
+	gmax, i := math.Inf(-1), -1
+	for t := range s.labels {
+		if s.inUp(t) {
+			if v := -s.labels[t] * s.grad[t]; v >= gmax {
+				gmax, i = v, t
+			}
+		}
+	}
+	if i < 0 {
+		return -1, -1
+	}
+
+	rowI := s.row(i, 0)
+	gmin, j, best := math.Inf(1), -1, math.Inf(1)
+	for t := range s.labels {
+		if !s.inLow(t) {
+			continue
+		}
+		v := -s.labels[t] * s.grad[t]
+		gmin = math.Min(gmin, v)
+		if diff := gmax - v; diff > 0 {
+			quad := math.Max(s.diag[i]+s.diag[t]-2*rowI[t], tau)
+			if obj := -diff * diff / quad; obj <= best {
+				best, j = obj, t
+			}
+		}
+	}
+	if gmax-gmin < s.tol || j < 0 {
+		return -1, -1
+	}
+	return i, j
+}
+
+// Optimiza analíticamente αᵢ y αⱼ dentro de la caja [0, C] manteniendo Σ αy y
+// actualiza el gradiente
+func (s *smo) update(i, j int) {
+	rowI, rowJ := s.row(i, 0), s.row(j, 1)
+	yi, yj := s.labels[i], s.labels[j]
+	oldI, oldJ := s.alpha[i], s.alpha[j]
+	quad := math.Max(s.diag[i]+s.diag[j]-2*rowI[j], tau)
+
+	// En la dirección factible yᵢΔαᵢ = -yⱼΔαⱼ; el paso minimiza el dual en esa recta
+	step := (yi*(-s.grad[i]) - yj*(-s.grad[j])) / quad
+	lo, hi := s.bounds(i, j)
+	step = math.Min(math.Max(step, lo), hi)
+	s.alpha[i] = oldI + yi*step
+	s.alpha[j] = oldJ - yj*step
+
+	dI, dJ := (s.alpha[i]-oldI)*yi, (s.alpha[j]-oldJ)*yj
+	for t := range s.grad {
+		s.grad[t] += s.labels[t] * (rowI[t]*dI + rowJ[t]*dJ)
+	}
+}
+
+// Límites del paso para que αᵢ + yᵢ·paso y αⱼ - yⱼ·paso queden en [0, C]
+func (s *smo) bounds(i, j int) (float64, float64) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	clip := func(alpha, dir float64) {
+		// alpha + dir·paso en [0, C]
+		if dir > 0 {
+			lo, hi = math.Max(lo, -alpha), math.Min(hi, s.c-alpha)
+		} else {
+			lo, hi = math.Max(lo, alpha-s.c), math.Min(hi, alpha)
+		}
+	}
+	clip(s.alpha[i], s.labels[i])
+	clip(s.alpha[j], -s.labels[j])
+	return lo, hi
+}
+
+// Sesgo b: la media de -yᵢGᵢ de los αs libres (0 < α < C) o, si no hay, el
+// punto medio del intervalo que permiten los αs en los límites
+func (s *smo) bias() float64 {
+	sum, free := 0.0, 0
+	ub, lb := math.Inf(1), math.Inf(-1)
+	for t, a := range s.alpha {
+		v := -s.labels[t] * s.grad[t]
+		switch {
+		case a > 0 && a < s.c:
+			sum += v
+			free++
+		case s.inUp(t):
+			lb = math.Max(lb, v)
+		default:
+			ub = math.Min(ub, v)
+		}
+	}
+	if free > 0 {
+		return sum / float64(free)
+	}
+	return (ub + lb) / 2
+}
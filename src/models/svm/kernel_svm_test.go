@@ -0,0 +1,108 @@
+package svm
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// Dos anillos concéntricos: la clase 1 con radio 0.5 y la 0 con radio 2, que
+// ningún hiperplano separa
+func rings() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 60 {
+		angle := 2 * math.Pi * float64(i) / 60
+		X = append(X, []float64{0.5 * math.Cos(angle), 0.5 * math.Sin(angle)})
+		X = append(X, []float64{2 * math.Cos(angle), 2 * math.Sin(angle)})
+		y = append(y, 1, 0)
+	}
+	return X, y
+}
+
+func TestKernelSVMSeparatesRings(t *testing.T) {
+	X, y := rings()
+	tests := []struct {
+		name   string
+		kernel Kernel
+	}{
+		{"rbf", Kernel{Type: RBF}},
+		{"poly", Kernel{Type: Polynomial, Degree: 2, Coef0: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svm := NewKernelSVM()
+			svm.Kernel, svm.C = tt.kernel, 10
+			if err := svm.Fit(X, y); err != nil {
+				t.Fatal(err)
+			}
+			if acc := accuracy(svm.Predict(X), y); acc != 1 {
+				t.Errorf("accuracy = %v, se esperaba 1", acc)
+			}
+
+			// Condiciones del dual: Σ αᵢyᵢ = 0 y 0 < αᵢ <= C en los vectores de soporte
+			sum := 0.0
+			for _, coef := range svm.DualCoefs() {
+				sum += coef
+				if coef == 0 || math.Abs(coef) > svm.C+1e-9 {
+					t.Errorf("coeficiente %v fuera de (0, C]", coef)
+				}
+			}
+			if math.Abs(sum) > 1e-9 {
+				t.Errorf("Σ αᵢyᵢ = %v, se esperaba 0", sum)
+			}
+			if len(svm.SupportVectors()) != len(svm.DualCoefs()) || len(svm.SupportVectors()) == len(X) {
+				t.Errorf("%d vectores de soporte de %d filas", len(svm.SupportVectors()), len(X))
+			}
+		})
+	}
+}
+
+func TestKernelSVMSequentialAndConcurrentAgree(t *testing.T) {
+	X, y := rings()
+	svm := NewKernelSVM()
+	concurrent := NewKernelSVMConcurrent()
+	concurrent.Workers = 4
+	// Una caché de dos filas obliga a recalcular filas durante SMO
+	svm.CacheMB, concurrent.CacheMB = 1e-6, 1e-6
+	if err := svm.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if err := concurrent.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if svm.Iterations() != concurrent.Iterations() {
+		t.Errorf("iteraciones = %d y %d", svm.Iterations(), concurrent.Iterations())
+	}
+	if !slices.Equal(svm.DecisionFunction(X), concurrent.DecisionFunction(X)) {
+		t.Error("los márgenes secuenciales y concurrentes difieren")
+	}
+}
+
+func TestKernelSVMOneClass(t *testing.T) {
+	X, _ := rings()
+	if err := NewKernelSVM().Fit(X, make([]float64, len(X))); !errors.Is(err, errOneClass) {
+		t.Errorf("error = %v, se esperaba errOneClass", err)
+	}
+}
+
+func TestKernelEval(t *testing.T) {
+	a, b := []float64{1, 2}, []float64{3, -1}
+	tests := []struct {
+		kernel Kernel
+		want   float64
+	}{
+		{Kernel{Type: Linear}, 1},
+		{Kernel{Type: RBF, Gamma: 0.5}, math.Exp(-0.5 * 13)},
+		{Kernel{Type: Polynomial, Gamma: 1, Degree: 2, Coef0: 1}, 4},
+		{Kernel{Type: Sigmoid, Gamma: 1}, math.Tanh(1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kernel.Type.String(), func(t *testing.T) {
+			if got := tt.kernel.Eval(a, b); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("Eval = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
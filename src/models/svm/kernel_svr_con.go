@@ -0,0 +1,65 @@
+package svm
+
+import (
+	"io"
+	"src/models"
+)
+
+var (
+	_ models.Regressor  = (*KernelSVRC)(nil)
+	_ models.Persistent = (*KernelSVRC)(nil)
+)
+
+// KernelSVRC es el SVR con kernel concurrente: SMO es el mismo que en
+// KernelSVR, pero cada fila de kernel que falta en la caché y cada predicción
+// se calculan repartiendo las filas entre Workers goroutines
+type KernelSVRC struct {
+	KernelSVR
+	Workers int // Goroutines por fila de kernel o predicción; 0 usa GOMAXPROCS
+}
+
+// NewKernelSVRConcurrent crea un SVR con kernel concurrente con los hiperparámetros por defecto
+func NewKernelSVRConcurrent() *KernelSVRC {
+	return &KernelSVRC{KernelSVR: *NewKernelSVR()}
+}
+
+// Fit entrena el modelo calculando las filas de kernel en paralelo
+func (svr *KernelSVRC) Fit(X [][]float64, y []float64) error {
+	return svr.fit(X, y, parallelRows(X, svr.Workers))
+}
+
+// Predict devuelve la predicción de cada fila de X, repartiendo las filas entre goroutines
+func (svr *KernelSVRC) Predict(X [][]float64) []float64 {
+	return parallelDecisions(X, svr.Workers, svr.decision)
+}
+
+// Estado serializable del SVR con kernel concurrente
+type kernelSVRCState struct {
+	Model   kernelSVRState
+	Workers int
+}
+
+// Save guarda el modelo en JSON
+func (svr *KernelSVRC) Save(w io.Writer) error {
+	return models.Save(w, kindKernelSVRC, svr.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svr *KernelSVRC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindKernelSVRC, svr.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svr *KernelSVRC) Load(r io.Reader) error {
+	var st kernelSVRCState
+	if err := models.Load(r, kindKernelSVRC, &st); err != nil {
+		return err
+	}
+	svr.restore(st.Model)
+	svr.Workers = st.Workers
+	return nil
+}
+
+func (svr *KernelSVRC) state() kernelSVRCState {
+	return kernelSVRCState{svr.KernelSVR.state(), svr.Workers}
+}
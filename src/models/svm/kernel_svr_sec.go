@@ -0,0 +1,162 @@
+package svm
+
+import (
+	"errors"
+	"io"
+	"src/models"
+)
+
+var (
+	_ models.Regressor  = (*KernelSVR)(nil)
+	_ models.Persistent = (*KernelSVR)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindKernelSVR  = "kernel_svr"
+	kindKernelSVRC = "kernel_svr_concurrent"
+)
+
+func init() {
+	models.Register(kindKernelSVR, func() models.Regressor { return NewKernelSVR() })
+	models.Register(kindKernelSVRC, func() models.Regressor { return NewKernelSVRConcurrent() })
+}
+
+// KernelSVR es la regresión de vectores de soporte con kernel (ε-SVR),
+// entrenada con el mismo SMO que KernelSVM sobre el dual
+//
+//	min ½ Σᵢⱼ (αᵢ - αᵢ*)(αⱼ - αⱼ*)K(xᵢ, xⱼ) + ε Σᵢ (αᵢ + αᵢ*) - Σᵢ yᵢ(αᵢ - αᵢ*)
+//	con 0 <= αᵢ, αᵢ* <= C y Σᵢ (αᵢ - αᵢ*) = 0
+//
+// que SMO ve como 2n variables: αᵢ con etiqueta +1 y αᵢ* con etiqueta -1,
+// ambas sobre la fila i. Predice Σ (αᵢ - αᵢ*)K(xᵢ, x) + b
+type KernelSVR struct {
+	// Hiperparámetros usados por Fit
+	Kernel  Kernel
+	C       float64 // Penalización de los errores fuera del tubo; 0 usa 1
+	Epsilon float64 // Ancho del tubo sin pérdida alrededor de la predicción
+	Tol     float64 // Tolerancia de las condiciones KKT; 0 usa 1e-3
+	MaxIter int     // Máximo de iteraciones de SMO; 0 usa max(10⁷, 200n)
+	CacheMB float64 // Memoria de la caché de filas de kernel en MB (cache_size de LIBSVM); 0 usa 100
+
+	expansion  // Vectores de soporte (filas con αᵢ ≠ αᵢ*) con coeficientes αᵢ - αᵢ*
+	iterations int
+}
+
+// NewKernelSVR crea un SVR con kernel RBF y los hiperparámetros por defecto
+func NewKernelSVR() *KernelSVR {
+	return &KernelSVR{Kernel: Kernel{Type: RBF}, C: 1, Epsilon: 0.1, Tol: 1e-3, CacheMB: 100}
+}
+
+// Fit entrena el modelo secuencialmente con X e y
+func (svr *KernelSVR) Fit(X [][]float64, y []float64) error {
+	return svr.fit(X, y, sequentialRows(X))
+}
+
+// Valida los datos, resuelve el dual con las filas de kernel de computeRow y
+// conserva los vectores de soporte
+func (svr *KernelSVR) fit(X [][]float64, y []float64, computeRow func(kernel Kernel, i int, row []float64)) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	if svr.C < 0 || svr.Tol < 0 {
+		return errors.New("C y la tolerancia no pueden ser negativas")
+	}
+	if svr.Epsilon < 0 {
+		return errEpsilon
+	}
+
+	// Variables 0..n-1: αᵢ (etiqueta +1, pᵢ = ε - yᵢ); n..2n-1: αᵢ* (etiqueta -1, pᵢ = ε + yᵢ)
+	n := len(X)
+	labels := make([]float64, 2*n)
+	linear := make([]float64, 2*n)
+	for i, target := range y {
+		labels[i], linear[i] = 1, svr.Epsilon-target
+		labels[n+i], linear[n+i] = -1, svr.Epsilon+target
+	}
+
+	svr.kernel = svr.Kernel.resolve(len(X[0]))
+	s := newSMO(X, labels, svr.kernel, svr.C, svr.Tol, svr.MaxIter, svr.CacheMB, computeRow)
+	s.linear = linear
+	alpha, bias, iterations := s.solve()
+
+	svr.support, svr.coefs = nil, nil
+	for i := range n {
+		if coef := alpha[i] - alpha[n+i]; coef != 0 {
+			svr.support = append(svr.support, X[i])
+			svr.coefs = append(svr.coefs, coef)
+		}
+	}
+	svr.bias, svr.iterations = bias, iterations
+	return nil
+}
+
+// Predict devuelve la predicción de cada fila de X
+func (svr *KernelSVR) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	for i, x := range X {
+		predictions[i] = svr.decision(x)
+	}
+	return predictions
+}
+
+// SupportVectors devuelve los vectores de soporte del último Fit
+func (svr *KernelSVR) SupportVectors() [][]float64 {
+	return svr.support
+}
+
+// DualCoefs devuelve αᵢ - αᵢ* de cada vector de soporte, en el orden de SupportVectors
+func (svr *KernelSVR) DualCoefs() []float64 {
+	return svr.coefs
+}
+
+// Iterations devuelve las iteraciones de SMO del último Fit
+func (svr *KernelSVR) Iterations() int {
+	return svr.iterations
+}
+
+// Estado serializable del SVR con kernel
+type kernelSVRState struct {
+	Kernel   Kernel
+	C        float64
+	Epsilon  float64
+	Tol      float64
+	MaxIter  int
+	CacheMB  float64
+	Resolved Kernel
+	Support  [][]float64
+	Coefs    []float64
+	Bias     float64
+}
+
+// Save guarda el modelo en JSON
+func (svr *KernelSVR) Save(w io.Writer) error {
+	return models.Save(w, kindKernelSVR, svr.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svr *KernelSVR) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindKernelSVR, svr.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svr *KernelSVR) Load(r io.Reader) error {
+	var st kernelSVRState
+	if err := models.Load(r, kindKernelSVR, &st); err != nil {
+		return err
+	}
+	svr.restore(st)
+	return nil
+}
+
+func (svr *KernelSVR) state() kernelSVRState {
+	return kernelSVRState{svr.Kernel, svr.C, svr.Epsilon, svr.Tol, svr.MaxIter, svr.CacheMB, svr.kernel, svr.support, svr.coefs, svr.bias}
+}
+
+func (svr *KernelSVR) restore(st kernelSVRState) {
+	svr.Kernel, svr.C, svr.Epsilon, svr.Tol, svr.MaxIter, svr.CacheMB = st.Kernel, st.C, st.Epsilon, st.Tol, st.MaxIter, st.CacheMB
+	svr.kernel, svr.support, svr.coefs, svr.bias = st.Resolved, st.Support, st.Coefs, st.Bias
+}
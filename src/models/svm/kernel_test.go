@@ -0,0 +1,47 @@
+package svm
+
+import "testing"
+
+func TestKernelCacheCapacity(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		cacheMB float64
+		want    int
+	}{
+		{"100 MB con 45211 filas", 45211, 100, 289},
+		{"1 MB con 1024 filas", 1024, 1, 128},
+		{"mínimo de dos filas", 1 << 20, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newKernelCache(tt.n, tt.cacheMB, nil)
+			if c.capacity != tt.want {
+				t.Errorf("capacidad = %d, se esperaba %d", c.capacity, tt.want)
+			}
+		})
+	}
+}
+
+func TestKernelCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	computed := 0
+	c := newKernelCache(4, 0, func(i int, row []float64) {
+		computed++
+		for t := range row {
+			row[t] = float64(10*i + t)
+		}
+	})
+	c.row(0)
+	c.row(1)
+	c.row(0) // La fila 1 pasa a ser la menos usada
+	c.row(2) // Reemplaza la fila 1
+	if computed != 3 {
+		t.Fatalf("filas calculadas = %d, se esperaban 3", computed)
+	}
+	if row := c.row(0); row[3] != 3 || computed != 3 {
+		t.Errorf("la fila 0 debía seguir en la caché: %v, calculadas %d", row, computed)
+	}
+	if row := c.row(1); row[2] != 12 || computed != 4 {
+		t.Errorf("la fila 1 debía recalcularse: %v, calculadas %d", row, computed)
+	}
+}
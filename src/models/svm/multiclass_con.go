@@ -0,0 +1,81 @@
+package svm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"src/models"
+	"sync"
+)
+
+var (
+	_ models.MultiClassifier = (*MulticlassC)(nil)
+	_ models.Persistent      = (*MulticlassC)(nil)
+)
+
+// MulticlassC es el SVM multiclase concurrente: cada problema binario se
+// entrena en su propia goroutine y los márgenes de cada SVM se calculan en
+// paralelo al predecir
+type MulticlassC struct {
+	Multiclass
+}
+
+// NewMulticlassConcurrent crea un SVM multiclase concurrente que entrena copias de base con la estrategia dada
+func NewMulticlassConcurrent(base Model, strategy Strategy) *MulticlassC {
+	return &MulticlassC{Multiclass: *NewMulticlass(base, strategy)}
+}
+
+// Fit entrena cada SVM binario en su propia goroutine
+func (m *MulticlassC) Fit(X [][]float64, y []float64) error {
+	problems, err := m.problems(X, y)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(problems))
+	var wg sync.WaitGroup
+	wg.Add(len(problems))
+	for i, p := range problems {
+		go func() {
+			defer wg.Done()
+			if err := p.model.Fit(p.X, p.y); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", m.describe(i), err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	m.keep(problems)
+	return nil
+}
+
+// Predict devuelve la clase ganadora de cada fila de X, con los márgenes de cada SVM en paralelo
+func (m *MulticlassC) Predict(X [][]float64) []float64 {
+	decisions := make([][]float64, len(m.estimators))
+	var wg sync.WaitGroup
+	wg.Add(len(m.estimators))
+	for i, estimator := range m.estimators {
+		go func() {
+			defer wg.Done()
+			decisions[i] = estimator.DecisionFunction(X)
+		}()
+	}
+	wg.Wait()
+	return m.aggregate(decisions, len(X))
+}
+
+// Save guarda el modelo en JSON
+func (m *MulticlassC) Save(w io.Writer) error {
+	return m.save(w, kindMulticlassC, false)
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (m *MulticlassC) SaveBinary(w io.Writer) error {
+	return m.save(w, kindMulticlassC, true)
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (m *MulticlassC) Load(r io.Reader) error {
+	return m.load(r, kindMulticlassC)
+}
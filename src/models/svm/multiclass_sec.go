@@ -0,0 +1,350 @@
+package svm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"src/models"
+)
+
+var (
+	_ models.MultiClassifier = (*Multiclass)(nil)
+	_ models.Persistent      = (*Multiclass)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindMulticlass  = "svm_multiclass"
+	kindMulticlassC = "svm_multiclass_concurrent"
+)
+
+func init() {
+	models.Register(kindMulticlass, func() models.Regressor { return &Multiclass{} })
+	models.Register(kindMulticlassC, func() models.Regressor { return &MulticlassC{} })
+}
+
+// Strategy es la forma de descomponer un problema multiclase en problemas binarios
+type Strategy int
+
+const (
+	OneVsRest Strategy = iota // Un SVM por clase: la clase contra el resto
+	OneVsOne                  // Un SVM por par de clases, con solo sus filas
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case OneVsRest:
+		return "ovr"
+	case OneVsOne:
+		return "ovo"
+	}
+	return fmt.Sprintf("Strategy(%d)", int(s))
+}
+
+// ParseStrategy convierte "ovr" u "ovo" en su Strategy
+func ParseStrategy(name string) (Strategy, error) {
+	for _, s := range []Strategy{OneVsRest, OneVsOne} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("estrategia multiclase desconocida %q (use ovr u ovo)", name)
+}
+
+// Aggregation es la forma de combinar las salidas de los SVM binarios
+type Aggregation int
+
+const (
+	AutoAggregation Aggregation = iota // ByDecision en OvR y ByVote en OvO
+	ByDecision                         // La clase con mayor margen (en OvO, la suma de sus márgenes)
+	ByVote                             // La clase con más votos; los empates se deciden por margen
+)
+
+func (a Aggregation) String() string {
+	switch a {
+	case AutoAggregation:
+		return "auto"
+	case ByDecision:
+		return "decision"
+	case ByVote:
+		return "vote"
+	}
+	return fmt.Sprintf("Aggregation(%d)", int(a))
+}
+
+// ParseAggregation convierte "auto", "decision" o "vote" en su Aggregation
+func ParseAggregation(name string) (Aggregation, error) {
+	for _, a := range []Aggregation{AutoAggregation, ByDecision, ByVote} {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("agregación desconocida %q (use auto, decision o vote)", name)
+}
+
+// Multiclass entrena un SVM binario por clase (OvR) o por par de clases (OvO)
+// a partir de copias de Base y predice la clase que gana según Aggregation.
+// En OvR la clase de cada SVM es la positiva; en OvO lo es la primera del par
+type Multiclass struct {
+	Base        Model // SVM binario sin entrenar que se copia para cada problema
+	Strategy    Strategy
+	Aggregation Aggregation
+
+	classes    []float64 // Clases vistas en Fit, en orden
+	pairs      [][2]int  // En OvO, los índices de las clases de cada SVM
+	estimators []Model
+}
+
+// NewMulticlass crea un SVM multiclase que entrena copias de base con la estrategia dada
+func NewMulticlass(base Model, strategy Strategy) *Multiclass {
+	return &Multiclass{Base: base, Strategy: strategy}
+}
+
+// Problema binario: el SVM que lo resuelve y sus filas con etiquetas 0/1
+type binaryProblem struct {
+	model Model
+	X     [][]float64
+	y     []float64
+}
+
+// Fit entrena secuencialmente un SVM binario por problema
+func (m *Multiclass) Fit(X [][]float64, y []float64) error {
+	problems, err := m.problems(X, y)
+	if err != nil {
+		return err
+	}
+	for i, p := range problems {
+		if err := p.model.Fit(p.X, p.y); err != nil {
+			return fmt.Errorf("%s: %w", m.describe(i), err)
+		}
+	}
+	m.keep(problems)
+	return nil
+}
+
+// Valida los datos, guarda las clases y prepara un problema binario con una
+// copia de Base por clase o por par de clases
+func (m *Multiclass) problems(X [][]float64, y []float64) ([]binaryProblem, error) {
+	if err := models.CheckFit(X, y); err != nil {
+		return nil, err
+	}
+	if m.Base == nil {
+		return nil, errors.New("el SVM multiclase necesita un modelo base")
+	}
+	classes := slices.Clone(y)
+	slices.Sort(classes)
+	classes = slices.Compact(classes)
+	if len(classes) < 2 {
+		return nil, errors.New("el SVM multiclase necesita al menos dos clases")
+	}
+	m.classes, m.pairs = classes, nil
+
+	var problems []binaryProblem
+	switch m.Strategy {
+	case OneVsRest:
+		for _, class := range classes {
+			labels := make([]float64, len(y))
+			for i, label := range y {
+				if label == class {
+					labels[i] = 1
+				}
+			}
+			problems = append(problems, binaryProblem{X: X, y: labels})
+		}
+	case OneVsOne:
+		for a := range classes {
+			for b := a + 1; b < len(classes); b++ {
+				var rows [][]float64
+				var labels []float64
+				for i, label := range y {
+					switch label {
+					case classes[a]:
+						rows, labels = append(rows, X[i]), append(labels, 1)
+					case classes[b]:
+						rows, labels = append(rows, X[i]), append(labels, 0)
+					}
+				}
+				m.pairs = append(m.pairs, [2]int{a, b})
+				problems = append(problems, binaryProblem{X: rows, y: labels})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("estrategia multiclase desconocida %v", m.Strategy)
+	}
+
+	for i := range problems {
+		model, err := copyModel(m.Base)
+		if err != nil {
+			return nil, err
+		}
+		problems[i].model = model
+	}
+	return problems, nil
+}
+
+// Copia sin compartir estado de un SVM binario
+func copyModel(base Model) (Model, error) {
+	clone, err := models.Clone(base)
+	if err != nil {
+		return nil, err
+	}
+	model, ok := clone.(Model)
+	if !ok {
+		return nil, fmt.Errorf("%T no es un SVM binario", clone)
+	}
+	return model, nil
+}
+
+// Conserva los SVM entrenados de los problemas
+func (m *Multiclass) keep(problems []binaryProblem) {
+	m.estimators = make([]Model, len(problems))
+	for i, p := range problems {
+		m.estimators[i] = p.model
+	}
+}
+
+// Nombre del problema binario i para los mensajes de error
+func (m *Multiclass) describe(i int) string {
+	if m.Strategy == OneVsOne {
+		return fmt.Sprintf("clases %v y %v", m.classes[m.pairs[i][0]], m.classes[m.pairs[i][1]])
+	}
+	return fmt.Sprintf("clase %v", m.classes[i])
+}
+
+// Classes devuelve las clases vistas en Fit, en orden
+func (m *Multiclass) Classes() []float64 {
+	return m.classes
+}
+
+// Predict devuelve la clase ganadora de cada fila de X
+func (m *Multiclass) Predict(X [][]float64) []float64 {
+	decisions := make([][]float64, len(m.estimators))
+	for i, estimator := range m.estimators {
+		decisions[i] = estimator.DecisionFunction(X)
+	}
+	return m.aggregate(decisions, len(X))
+}
+
+// Combina los márgenes decisions[SVM][fila]. Cada SVM da un voto y suma su
+// margen a la confianza de su clase positiva; en OvO además resta su margen
+// a la otra clase del par, que recibe el voto si el margen es negativo
+func (m *Multiclass) aggregate(decisions [][]float64, n int) []float64 {
+	byVote := m.Aggregation == ByVote || (m.Aggregation == AutoAggregation && m.Strategy == OneVsOne)
+	predictions := make([]float64, n)
+	votes := make([]int, len(m.classes))
+	confidence := make([]float64, len(m.classes))
+	for i := range n {
+		clear(votes)
+		clear(confidence)
+		for e, margins := range decisions {
+			margin := margins[i]
+			positive, negative := e, -1
+			if m.Strategy == OneVsOne {
+				positive, negative = m.pairs[e][0], m.pairs[e][1]
+				confidence[negative] -= margin
+			}
+			confidence[positive] += margin
+			switch {
+			case margin >= 0:
+				votes[positive]++
+			case negative >= 0:
+				votes[negative]++
+			}
+		}
+
+		best := 0
+		for c := 1; c < len(m.classes); c++ {
+			if byVote && votes[c] != votes[best] {
+				if votes[c] > votes[best] {
+					best = c
+				}
+				continue
+			}
+			if confidence[c] > confidence[best] {
+				best = c
+			}
+		}
+		predictions[i] = m.classes[best]
+	}
+	return predictions
+}
+
+// Estado serializable: la configuración y cada SVM guardado en el mismo
+// formato, con su propia cabecera
+type multiclassState struct {
+	Strategy    Strategy
+	Aggregation Aggregation
+	Base        json.RawMessage
+	Classes     []float64
+	Pairs       [][2]int
+	Estimators  []json.RawMessage
+}
+
+// Save guarda el modelo en JSON
+func (m *Multiclass) Save(w io.Writer) error {
+	return m.save(w, kindMulticlass, false)
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (m *Multiclass) SaveBinary(w io.Writer) error {
+	return m.save(w, kindMulticlass, true)
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (m *Multiclass) Load(r io.Reader) error {
+	return m.load(r, kindMulticlass)
+}
+
+func (m *Multiclass) save(w io.Writer, kind string, binary bool) error {
+	st := multiclassState{Strategy: m.Strategy, Aggregation: m.Aggregation, Classes: m.classes, Pairs: m.pairs}
+	var err error
+	if st.Base, err = models.SaveNested(m.Base, binary); err != nil {
+		return err
+	}
+	for _, estimator := range m.estimators {
+		raw, err := models.SaveNested(estimator, binary)
+		if err != nil {
+			return err
+		}
+		st.Estimators = append(st.Estimators, raw)
+	}
+	if binary {
+		return models.SaveBinary(w, kind, st)
+	}
+	return models.Save(w, kind, st)
+}
+
+func (m *Multiclass) load(r io.Reader, kind string) error {
+	var st multiclassState
+	if err := models.Load(r, kind, &st); err != nil {
+		return err
+	}
+	base, err := loadModel(st.Base)
+	if err != nil {
+		return err
+	}
+	estimators := make([]Model, len(st.Estimators))
+	for i, raw := range st.Estimators {
+		if estimators[i], err = loadModel(raw); err != nil {
+			return err
+		}
+	}
+	m.Base, m.Strategy, m.Aggregation = base, st.Strategy, st.Aggregation
+	m.classes, m.pairs, m.estimators = st.Classes, st.Pairs, estimators
+	return nil
+}
+
+// Carga un SVM binario guardado con models.SaveNested
+func loadModel(raw json.RawMessage) (Model, error) {
+	loaded, err := models.LoadNested(raw)
+	if err != nil {
+		return nil, err
+	}
+	model, ok := loaded.(Model)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T no es un SVM binario", models.ErrModelMismatch, loaded)
+	}
+	return model, nil
+}
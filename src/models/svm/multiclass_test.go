@@ -0,0 +1,100 @@
+package svm
+
+import (
+	"slices"
+	"src/models"
+	"testing"
+)
+
+// Cuatro nubes en los puntos cardinales con las clases 2, 5, 7 y 9
+func cardinalBlobs() ([][]float64, []float64) {
+	centers := [][2]float64{{0, 3}, {3, 0}, {0, -3}, {-3, 0}}
+	classes := []float64{2, 5, 7, 9}
+	var X [][]float64
+	var y []float64
+	for i := range 25 {
+		dx, dy := float64(i%5)/5-0.4, float64(i/5)/5-0.4
+		for c, center := range centers {
+			X = append(X, []float64{center[0] + dx, center[1] + dy})
+			y = append(y, classes[c])
+		}
+	}
+	return X, y
+}
+
+func TestMulticlassStrategies(t *testing.T) {
+	X, y := cardinalBlobs()
+	tests := []struct {
+		strategy   Strategy
+		estimators int
+	}{
+		{OneVsRest, 4},
+		{OneVsOne, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy.String(), func(t *testing.T) {
+			base := NewSVM()
+			base.Epochs = 20
+			for _, m := range []interface {
+				models.Regressor
+				Classes() []float64
+			}{NewMulticlass(base, tt.strategy), NewMulticlassConcurrent(base, tt.strategy)} {
+				if err := m.Fit(X, y); err != nil {
+					t.Fatal(err)
+				}
+				if acc := accuracy(m.Predict(X), y); acc != 1 {
+					t.Errorf("%T: accuracy = %v, se esperaba 1", m, acc)
+				}
+				if want := []float64{2, 5, 7, 9}; !slices.Equal(m.Classes(), want) {
+					t.Errorf("%T: clases = %v, se esperaba %v", m, m.Classes(), want)
+				}
+			}
+			// La base no se entrena: cada problema usa su propia copia
+			if base.weights != nil {
+				t.Error("Fit entrenó el modelo base")
+			}
+
+			m := NewMulticlass(base, tt.strategy)
+			if err := m.Fit(X, y); err != nil {
+				t.Fatal(err)
+			}
+			if len(m.estimators) != tt.estimators {
+				t.Errorf("%d SVM binarios, se esperaban %d", len(m.estimators), tt.estimators)
+			}
+			clone, err := models.Clone(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(clone.Predict(X), m.Predict(X)) {
+				t.Error("el clon predice distinto")
+			}
+		})
+	}
+}
+
+func TestMulticlassAggregate(t *testing.T) {
+	// OvO con tres clases: cada clase gana un par, así que el empate a votos se
+	// decide por la suma de márgenes
+	m := &Multiclass{Strategy: OneVsOne, classes: []float64{0, 1, 2}, pairs: [][2]int{{0, 1}, {0, 2}, {1, 2}}}
+	decisions := [][]float64{{0.5}, {-0.1}, {2}}
+	// Confianza: clase 0 = 0.5 - 0.1, clase 1 = -0.5 + 2, clase 2 = 0.1 - 2
+	if got := m.aggregate(decisions, 1); got[0] != 1 {
+		t.Errorf("por votos = %v, se esperaba la clase 1", got[0])
+	}
+
+	// OvR por margen: gana el mayor aunque sea negativo
+	m = &Multiclass{Strategy: OneVsRest, classes: []float64{0, 1, 2}}
+	if got := m.aggregate([][]float64{{-2}, {-0.5}, {-1}}, 1); got[0] != 1 {
+		t.Errorf("por margen = %v, se esperaba la clase 1", got[0])
+	}
+}
+
+func TestMulticlassNeedsTwoClasses(t *testing.T) {
+	X, _ := cardinalBlobs()
+	if err := NewMulticlass(NewSVM(), OneVsRest).Fit(X, make([]float64, len(X))); err == nil {
+		t.Error("una sola clase debe dar error")
+	}
+	if err := (&Multiclass{}).Fit(X, make([]float64, len(X))); err == nil {
+		t.Error("sin modelo base debe dar error")
+	}
+}
@@ -1,162 +1,204 @@
 package svm
 
 import (
-	"fmt"
-	"math/rand"
+	"io"
+	"src/classification"
+	"src/data"
+	"src/models"
 	"sync"
-	"time"
 )
 
-// Estructura del modelo SVM
+var (
+	_ models.Classifier = (*SVMC)(nil)
+	_ models.Persistent = (*SVMC)(nil)
+)
+
+// Estructura del modelo SVM concurrente. Minimiza el mismo objetivo que SVM
+// con Pegasos por mini-lotes: cada paso suma el subgradiente de BatchSize
+// filas, que se reparten entre Workers goroutines, y equivale a BatchSize
+// pasos de SVM con los márgenes del inicio del lote, así que ambos avanzan el
+// mismo número de pasos de Pegasos por época
 type SVMC struct {
-	weights []float64
-	bias    float64
-	mu      sync.Mutex // Mutex para evitar condiciones de carrera
+	SVM
+	BatchSize int // Filas por paso; 0 usa 64
+	Workers   int // Goroutines por lote; 0 usa GOMAXPROCS
 }
 
-// Inicializa el modelo SVM
-func newSVMConcurrent(inputSize int) *SVMC {
-	weights := make([]float64, inputSize)
-	for i := range weights {
-		weights[i] = rand.Float64()*2 - 1 // Valores aleatorios entre -1 y 1
-	}
-	return &SVMC{
-		weights: weights,
-		bias:    rand.Float64()*2 - 1,
-	}
+// NewSVMConcurrent crea un SVM concurrente con los hiperparámetros por defecto
+func NewSVMConcurrent() *SVMC {
+	return &SVMC{SVM: *NewSVM(), BatchSize: 64}
 }
 
-// Calcula la predicción del modelo
-func (svm *SVMC) predictConcurrent(inputs []float64) float64 {
-	sum := svm.bias
-	for i := range inputs {
-		sum += inputs[i] * svm.weights[i]
+// Fit entrena el modelo concurrentemente con X e y; los folds de la
+// calibración se entrenan cada uno en su propia goroutine
+func (svm *SVMC) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
 	}
-	if sum >= 0 {
-		return 1.0
+	if err := models.CheckComplete(X); err != nil {
+		return err
 	}
-	return 0.0
-}
-
-// Actualiza los pesos de forma concurrente
-func (svm *SVMC) updateWeightsConcurrent(data []float64, label float64, prediction float64, learningRate float64, lambda float64) {
-	svm.mu.Lock() // Bloquea el mutex para actualizar los pesos de forma segura
-	defer svm.mu.Unlock()
-
-	// Solo actualiza si el margen no se respeta
-	if label*prediction < 1 {
-		for j := range svm.weights {
-			svm.weights[j] += learningRate * (label*data[j] - lambda*svm.weights[j])
-		}
-		svm.bias += learningRate * label
-	} else {
-		// Regularización
-		for j := range svm.weights {
-			svm.weights[j] -= learningRate * lambda * svm.weights[j]
-		}
+	if svm.Lambda <= 0 {
+		return errLambda
+	}
+	calibrator, err := fitCalibrator(svm.Calibration, X, y, svm.CalibrationFolds, svm.Seed, true, func(X [][]float64, y []float64) (Model, error) {
+		fold := *svm
+		fold.Calibration, fold.Verbose = NoCalibration, false
+		return &fold, fold.Fit(X, y)
+	})
+	if err != nil {
+		return err
 	}
+	svm.weights, svm.bias = make([]float64, len(X[0])), 0
+	svm.trainConcurrent(X, signedLabels(y))
+	svm.calibrator = calibrator
+	return nil
 }
 
-// Entrena el modelo SVM concurrentemente usando goroutines
-func (svm *SVMC) trainConcurrent(data [][]float64, labels []float64, epochs int, learningRate float64, lambda float64) {
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X, repartiendo las filas entre goroutines
+func (svm *SVMC) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
 	var wg sync.WaitGroup
-
-	for epoch := 0; epoch < epochs; epoch++ {
-		// Entrenar cada ejemplo de manera concurrente
-		for i := range data {
-			wg.Add(1)
-			go func(idx int) {
-				defer wg.Done()
-				prediction := svm.predictConcurrent(data[idx])
-				svm.updateWeightsConcurrent(data[idx], labels[idx], prediction, learningRate, lambda)
-			}(i)
-		}
-		// Esperar a que todas las goroutines terminen
-		wg.Wait()
+	wg.Add(len(X))
+	for i := range X {
+		go func(i int) {
+			defer wg.Done()
+			predictions[i] = svm.predict(X[i])
+		}(i)
 	}
+	wg.Wait()
+	return predictions
 }
 
-// Calcula la precisión
-func accuracyConcurrent(predictions, labels []float64) float64 {
-	correct := 0
-	total := len(labels)
-	for i := range predictions {
-		if predictions[i] == labels[i] {
-			correct++
-		}
+// PredictProba devuelve la probabilidad de la clase 1 de cada fila de X según
+// Calibration, repartiendo las filas entre goroutines
+func (svm *SVMC) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	var wg sync.WaitGroup
+	wg.Add(len(X))
+	for i := range X {
+		go func(i int) {
+			defer wg.Done()
+			probas[i] = svm.calibrator.Probability(svm.decision(X[i]))
+		}(i)
 	}
-	return float64(correct) / float64(total)
+	wg.Wait()
+	return probas
 }
 
-// Calcula la matriz de confusión
-func confusionMatrixConcurrent(predictions, labels []float64) (int, int, int, int) {
-	tp, tn, fp, fn := 0, 0, 0, 0
-	for i := range predictions {
-		if predictions[i] >= 0 && labels[i] == 1 {
-			tp++
-		} else if predictions[i] < 0 && labels[i] == 0 {
-			tn++
-		} else if predictions[i] >= 0 && labels[i] == 0 {
-			fp++
-		} else if predictions[i] < 0 && labels[i] == 1 {
-			fn++
-		}
-	}
-	return tp, tn, fp, fn
+// Estado serializable del SVM concurrente
+type svmCState struct {
+	Model     svmState
+	BatchSize int
+	Workers   int
 }
 
-// Función principal que ejecuta el entrenamiento y evalúa el modelo
-func SVMConcurrent(train [][]float64, label_train []float64, test [][]float64, label_test []float64) {
-	// rand.Seed(42) // Inicializa la semilla aleatoria
-
-	start := time.Now()
-
-	// Inicializar el modelo SVM
-	svm := newSVMConcurrent(len(train[0]))
+// Save guarda el modelo en JSON
+func (svm *SVMC) Save(w io.Writer) error {
+	return models.Save(w, kindSVMC, svm.state())
+}
 
-	// Entrenar el modelo concurrentemente
-	svm.trainConcurrent(train, label_train, 100, 0.01, 0.001)
+// SaveBinary guarda el modelo en formato binario
+func (svm *SVMC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindSVMC, svm.state())
+}
 
-	// Hacer predicciones en los datos de entrenamiento
-	predictions := make([]float64, len(train))
-	for i, d := range train {
-		predictions[i] = svm.predictConcurrent(d)
+// Load carga un modelo guardado con Save o SaveBinary
+func (svm *SVMC) Load(r io.Reader) error {
+	var st svmCState
+	if err := models.Load(r, kindSVMC, &st); err != nil {
+		return err
 	}
+	svm.restore(st.Model)
+	svm.BatchSize, svm.Workers = st.BatchSize, st.Workers
+	return nil
+}
 
-	// Evaluar el modelo
-	acc := accuracyConcurrent(predictions, label_train)
-	tp, _, fp, fn := confusionMatrixConcurrent(predictions, label_train)
-	prec := precisionConcurrent(tp, fp)
-	rec := recallConcurrent(tp, fn)
-	f1 := f1ScoreConcurrent(prec, rec)
+func (svm *SVMC) state() svmCState {
+	return svmCState{svm.SVM.state(), svm.BatchSize, svm.Workers}
+}
 
-	fmt.Printf("Precision: %.2f\n", prec)
-	fmt.Printf("Accuracy: %.2f\n", acc)
-	fmt.Printf("F1-Score: %.2f\n", f1)
-	fmt.Printf("Recall: %.2f\n", rec)
+// Subgradiente parcial de la pérdida hinge: suma de y·x (y de y para el
+// sesgo, en la última posición) de las filas que violan el margen
+type subgradient []float64
 
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+func addSubgradients(a, b subgradient) subgradient {
+	for j := range a {
+		a[j] += b[j]
+	}
+	return a
 }
 
-// Funciones adicionales para precisión, recall, y f1-score
-func precisionConcurrent(tp, fp int) float64 {
-	if tp+fp == 0 {
-		return 0.0
+// Filas de cada bloque al repartir entre workers una pasada por todas las
+// filas (la pérdida de una época, las filas de kernel o las predicciones)
+const rowChunk = 256
+
+// Paso de Pegasos de un lote de rows filas. t cuenta filas, no lotes: con
+// η = 1/(λt), siendo t la última fila del lote, (w, b) se encoge en
+// (1 - rows·ηλ) y se suma η veces el subgradiente del lote. Es lo que dan rows
+// pasos secuenciales desde t - rows con los márgenes del inicio del lote, ya
+// que en cada uno el paso 1/(λs) y el encogimiento de los pasos siguientes se
+// compensan en 1/(λt)
+func (h *hyperplane) batchStep(grad subgradient, rows, t int, lambda float64) {
+	eta := 1 / (lambda * float64(t))
+	shrink(h.weights, &h.bias, eta*float64(rows), lambda)
+	d := len(h.weights)
+	for j := range d {
+		h.weights[j] += eta * grad[j]
 	}
-	return float64(tp) / float64(tp+fp)
+	h.bias += eta * grad[d]
 }
 
-func recallConcurrent(tp, fn int) float64 {
-	if tp+fn == 0 {
-		return 0.0
+// Entrena el modelo con Pegasos por mini-lotes, con el paso de batchStep y
+// el subgradiente de cada lote calculado en paralelo
+func (svm *SVMC) trainConcurrent(X [][]float64, labels []float64) {
+	rng := data.NewRand(svm.Seed)
+	batchSize := svm.BatchSize
+	if batchSize <= 0 {
+		batchSize = 64
+	}
+	d := len(svm.weights)
+	svm.objectives = make([]float64, 0, svm.Epochs)
+
+	t := 0
+	for epoch := 0; epoch < svm.Epochs; epoch++ {
+		order := shuffled(len(X), rng)
+		for start := 0; start < len(order); start += batchSize {
+			batch := order[start:min(start+batchSize, len(order))]
+			grad := classification.MapReduce(len(batch), models.BatchChunk, svm.Workers, func(lo, hi int) subgradient {
+				g := make(subgradient, d+1)
+				for _, i := range batch[lo:hi] {
+					if labels[i]*svm.decision(X[i]) < 1 {
+						for j := range d {
+							g[j] += labels[i] * X[i][j]
+						}
+						g[d] += labels[i]
+					}
+				}
+				return g
+			}, addSubgradients)
+
+			t += len(batch)
+			svm.batchStep(grad, len(batch), t, svm.Lambda)
+			project(svm.weights, &svm.bias, svm.Lambda)
+		}
+
+		loss := classification.MapReduce(len(X), rowChunk, svm.Workers, func(lo, hi int) float64 {
+			sum := 0.0
+			for i := lo; i < hi; i++ {
+				sum += hinge(svm.weights, svm.bias, X[i], labels[i])
+			}
+			return sum
+		}, func(a, b float64) float64 { return a + b })
+		svm.logEpoch(epoch, loss/float64(len(X)))
 	}
-	return float64(tp) / float64(tp+fn)
 }
 
-func f1ScoreConcurrent(precision, recall float64) float64 {
-	if precision+recall == 0 {
-		return 0.0
+// SVMConcurrent entrena un SVM concurrente con trainSet y devuelve sus métricas en testSet
+func SVMConcurrent(trainSet, testSet *data.Dataset) (classification.Report, error) {
+	svm := NewSVMConcurrent()
+	if err := models.FitDataset(svm, trainSet); err != nil {
+		return classification.Report{}, err
 	}
-	return 2 * (precision * recall) / (precision + recall)
+	return classification.EvaluateDataset(svm, testSet)
 }
@@ -0,0 +1,46 @@
+package svm
+
+import (
+	"slices"
+	"testing"
+)
+
+// Con lotes de una fila el paso por lotes es exactamente el de Pegasos
+// secuencial, así que ambas variantes deben dar el mismo hiperplano
+func TestBatchOfOneMatchesSequential(t *testing.T) {
+	X, y := separableBlobs()
+	svm, svmc := NewSVM(), NewSVMConcurrent()
+	svm.Epochs, svmc.Epochs, svmc.BatchSize, svmc.Workers = 5, 5, 1, 3
+	for _, m := range []Model{svm, svmc} {
+		if err := m.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !slices.Equal(svm.weights, svmc.weights) || svm.bias != svmc.bias {
+		t.Errorf("SVM: w = %v, b = %v\nSVMC: w = %v, b = %v", svm.weights, svm.bias, svmc.weights, svmc.bias)
+	}
+
+	X, y = linearTarget()
+	svr, svrc := NewSVR(), NewSVRConcurrent()
+	svr.Epochs, svrc.Epochs, svrc.BatchSize, svrc.Workers = 5, 5, 1, 3
+	if err := svr.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if err := svrc.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(svr.weights, svrc.weights) || svr.bias != svrc.bias {
+		t.Errorf("SVR: w = %v, b = %v\nSVRC: w = %v, b = %v", svr.weights, svr.bias, svrc.weights, svrc.bias)
+	}
+}
+
+// t cuenta filas: el paso de un lote de B filas usa η = 1/(λt) con t la
+// última fila del lote, encoge en (1 - Bηλ) y suma η veces el subgradiente
+func TestBatchStepCountsRows(t *testing.T) {
+	h := hyperplane{weights: []float64{2, 4}, bias: 1}
+	// Lote de 4 filas que termina en t = 8: η = 1/(0.5·8) = 0.25, encoge en 1 - 4·0.25·0.5 = 0.5
+	h.batchStep(subgradient{4, 0, 8}, 4, 8, 0.5)
+	if want := []float64{2, 2}; !slices.Equal(h.weights, want) || h.bias != 2.5 {
+		t.Errorf("w = %v, b = %v; se esperaba %v, 2.5", h.weights, h.bias, want)
+	}
+}
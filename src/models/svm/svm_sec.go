@@ -1,149 +1,314 @@
 package svm
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
-	"time"
+	"src/classification"
+	"src/data"
+	"src/models"
 )
 
-// Estructura del modelo SVM
+var (
+	_ models.Classifier = (*SVM)(nil)
+	_ models.Persistent = (*SVM)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindSVM  = "svm"
+	kindSVMC = "svm_concurrent"
+)
+
+func init() {
+	models.Register(kindSVM, func() models.Regressor { return NewSVM() })
+	models.Register(kindSVMC, func() models.Regressor { return NewSVMConcurrent() })
+}
+
+var errLambda = errors.New("la regularización lambda debe ser positiva")
+
+// Estructura del modelo SVM lineal. Fit minimiza el objetivo primal
+//
+//	λ/2·‖w‖² + 1/n·Σ max(0, 1 - yᵢ(w·xᵢ + b))
+//
+// con las etiquetas llevadas a ±1 (la clase 1 es +1 y cualquier otra -1) y el
+// paso decreciente de Pegasos, 1/(λt). El sesgo se trata como el peso de una
+// característica constante 1, así que también se regulariza
 type SVM struct {
-	weights []float64
-	bias    float64
+	// Hiperparámetros usados por Fit
+	Epochs  int     // Pasadas completas por los datos
+	Lambda  float64 // Regularización λ; fija también el tamaño del paso
+	Seed    int64   // Semilla del orden en que se visitan las filas
+	Verbose bool    // Imprime el objetivo primal de cada época
+
+	// Calibración de PredictProba, ajustada con los márgenes de una validación
+	// cruzada interna de CalibrationFolds folds (0 usa 5)
+	Calibration      Calibration
+	CalibrationFolds int
+
+	hyperplane
+	calibrator Calibrator
 }
 
-// Inicializa el modelo SVM
-func newSVM(inputSize int) *SVM {
-	weights := make([]float64, inputSize)
-	for i := range weights {
-		weights[i] = rand.Float64()*2 - 1 // Valores aleatorios entre -1 y 1
-	}
-	return &SVM{
-		weights: weights,
-		bias:    rand.Float64()*2 - 1,
+// NewSVM crea un SVM secuencial con los hiperparámetros por defecto
+func NewSVM() *SVM {
+	return &SVM{Epochs: 100, Lambda: 0.001, Seed: data.DefaultSeed}
+}
+
+// Hiperplano w·x + b de los modelos lineales entrenados con Pegasos (SVM y
+// SVR), con el objetivo primal al final de cada época del último Fit
+type hyperplane struct {
+	weights    []float64
+	bias       float64
+	objectives []float64
+}
+
+// Calcula el margen (función de decisión) del modelo
+func (h *hyperplane) decision(inputs []float64) float64 {
+	return margin(h.weights, h.bias, inputs)
+}
+
+// Objectives devuelve el objetivo primal al final de cada época del último Fit
+func (h *hyperplane) Objectives() []float64 {
+	return h.objectives
+}
+
+// Margen w·x + b
+func margin(weights []float64, bias float64, inputs []float64) float64 {
+	sum := bias
+	for i := range inputs {
+		sum += inputs[i] * weights[i]
 	}
+	return sum
 }
 
 // Calcula la predicción del modelo
 func (svm *SVM) predict(inputs []float64) float64 {
-	sum := svm.bias
-	for i := range inputs {
-		sum += inputs[i] * svm.weights[i]
-	}
-	if sum >= 0 {
+	if svm.decision(inputs) >= 0 {
 		return 1.0
 	} else {
 		return 0.0
 	}
 }
 
-// Entrena el modelo SVM secuencialmente usando el algoritmo de margen máximo
-func (svm *SVM) trainSequential(data [][]float64, labels []float64, epochs int, learningRate float64, lambda float64) {
-	for epoch := 0; epoch < epochs; epoch++ {
-		for i := range data {
-			prediction := svm.predict(data[i])
+// Fit entrena el modelo secuencialmente con X e y
+func (svm *SVM) Fit(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	if svm.Lambda <= 0 {
+		return errLambda
+	}
+	calibrator, err := fitCalibrator(svm.Calibration, X, y, svm.CalibrationFolds, svm.Seed, false, func(X [][]float64, y []float64) (Model, error) {
+		fold := *svm
+		fold.Calibration, fold.Verbose = NoCalibration, false
+		return &fold, fold.Fit(X, y)
+	})
+	if err != nil {
+		return err
+	}
+	svm.weights, svm.bias = make([]float64, len(X[0])), 0
+	svm.trainSequential(X, signedLabels(y))
+	svm.calibrator = calibrator
+	return nil
+}
 
-			// Actualización basada en el margen
-			if labels[i]*prediction < 1 {
-				for j := range svm.weights {
-					svm.weights[j] += learningRate * (labels[i]*data[i][j] - lambda*svm.weights[j])
-				}
-				svm.bias += learningRate * labels[i]
-			} else {
-				// Regularización
-				for j := range svm.weights {
-					svm.weights[j] -= learningRate * lambda * svm.weights[j]
-				}
-			}
-		}
+// Predict devuelve la etiqueta (0 o 1) de cada fila de X
+func (svm *SVM) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	for i, x := range X {
+		predictions[i] = svm.predict(x)
 	}
+	return predictions
 }
 
-// Calcula la precisión
-func accuracy(predictions, labels []float64) float64 {
-	correct := 0
-	total := len(labels)
-	for i := range predictions {
-		if (predictions[i] >= 0 && labels[i] == 1) || (predictions[i] < 0 && labels[i] == 0) {
-			correct++
-		}
+// DecisionFunction devuelve el margen w·x + b de cada fila de X
+func (svm *SVM) DecisionFunction(X [][]float64) []float64 {
+	margins := make([]float64, len(X))
+	for i, x := range X {
+		margins[i] = svm.decision(x)
 	}
-	return float64(correct) / float64(total)
-}
-
-// Calcula la matriz de confusión
-func confusionMatrix(predictions, labels []float64) (int, int, int, int) {
-	tp, tn, fp, fn := 0, 0, 0, 0
-	for i := range predictions {
-		if predictions[i] >= 0 && labels[i] == 1 {
-			tp++
-		} else if predictions[i] < 0 && labels[i] == 0 {
-			tn++
-		} else if predictions[i] >= 0 && labels[i] == 0 {
-			fp++
-		} else if predictions[i] < 0 && labels[i] == 1 {
-			fn++
-		}
+	return margins
+}
+
+// PredictProba devuelve la probabilidad de la clase 1 de cada fila de X según
+// Calibration; sin calibrar es la sigmoide del margen
+func (svm *SVM) PredictProba(X [][]float64) []float64 {
+	probas := make([]float64, len(X))
+	for i, x := range X {
+		probas[i] = svm.calibrator.Probability(svm.decision(x))
 	}
-	return tp, tn, fp, fn
+	return probas
 }
 
-// Calcula la precisión (precision)
-func precision(tp, fp int) float64 {
-	if tp+fp == 0 {
-		return 0.0
+// Calibrator devuelve el calibrador ajustado en el último Fit
+func (svm *SVM) Calibrator() Calibrator {
+	return svm.calibrator
+}
+
+// Estado serializable del SVM
+type svmState struct {
+	Epochs  int
+	Lambda  float64
+	Seed    int64
+	Weights []float64
+	Bias    float64
+
+	Calibration      Calibration
+	CalibrationFolds int
+	Calibrator       Calibrator
+}
+
+// Save guarda el modelo en JSON
+func (svm *SVM) Save(w io.Writer) error {
+	return models.Save(w, kindSVM, svm.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svm *SVM) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindSVM, svm.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svm *SVM) Load(r io.Reader) error {
+	var st svmState
+	if err := models.Load(r, kindSVM, &st); err != nil {
+		return err
 	}
-	return float64(tp) / float64(tp+fp)
+	svm.restore(st)
+	return nil
 }
 
-// Calcula el recall
-func recall(tp, fn int) float64 {
-	if tp+fn == 0 {
-		return 0.0
+func (svm *SVM) state() svmState {
+	return svmState{svm.Epochs, svm.Lambda, svm.Seed, svm.weights, svm.bias, svm.Calibration, svm.CalibrationFolds, svm.calibrator}
+}
+
+func (svm *SVM) restore(st svmState) {
+	svm.Epochs, svm.Lambda, svm.Seed = st.Epochs, st.Lambda, st.Seed
+	svm.weights, svm.bias = st.Weights, st.Bias
+	svm.Calibration, svm.CalibrationFolds, svm.calibrator = st.Calibration, st.CalibrationFolds, st.Calibrator
+}
+
+// Función sigmoide para convertir el margen en una probabilidad
+func sigmoid(x float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-x))
+}
+
+// Lleva las etiquetas a ±1: la clase 1 es +1 y cualquier otra -1
+func signedLabels(y []float64) []float64 {
+	signed := make([]float64, len(y))
+	for i, label := range y {
+		if label == 1 {
+			signed[i] = 1
+		} else {
+			signed[i] = -1
+		}
 	}
-	return float64(tp) / float64(tp+fn)
+	return signed
 }
 
-// Calcula el F1-score
-func f1Score(precision, recall float64) float64 {
-	if precision+recall == 0 {
-		return 0.0
+// Término de regularización λ/2·‖(w, b)‖²
+func regularization(weights []float64, bias, lambda float64) float64 {
+	norm := bias * bias
+	for _, w := range weights {
+		norm += w * w
 	}
-	return 2 * (precision * recall) / (precision + recall)
+	return lambda / 2 * norm
 }
 
-func SVMSecuential(train [][]float64, label_train []float64, test [][]float64, label_test []float64) {
-	// rand.Seed(42) // Inicializa la semilla aleatoria
+// Pérdida hinge de una fila con etiqueta ±1
+func hinge(weights []float64, bias float64, x []float64, label float64) float64 {
+	return math.Max(0, 1-label*margin(weights, bias, x))
+}
 
-	start := time.Now()
+// Encoge (w, b) en (1 - ηλ), el paso del término de regularización
+func shrink(weights []float64, bias *float64, eta, lambda float64) {
+	factor := 1 - eta*lambda
+	for j := range weights {
+		weights[j] *= factor
+	}
+	*bias *= factor
+}
 
-	// Datos de ejemplo
-	data := train
-	labels := label_train
-	// Inicializar el modelo SVM
-	svm := newSVM(len(data[0]))
+// Proyecta (w, b) sobre la bola de radio 1/√λ, donde está la solución óptima
+func project(weights []float64, bias *float64, lambda float64) {
+	clipNorm(weights, bias, 1/lambda)
+}
 
-	// Entrenar el modelo secuencialmente
-	svm.trainSequential(data, labels, 100, 0.01, 0.001)
+// Proyecta (w, b) sobre la bola ‖(w, b)‖² <= limit
+func clipNorm(weights []float64, bias *float64, limit float64) {
+	norm := *bias * *bias
+	for _, w := range weights {
+		norm += w * w
+	}
+	if norm > limit {
+		scale := math.Sqrt(limit / norm)
+		for j := range weights {
+			weights[j] *= scale
+		}
+		*bias *= scale
+	}
+}
 
-	// Hacer predicciones
-	predictions := make([]float64, len(data))
-	for i, d := range data {
-		predictions[i] = svm.predict(d)
+// Orden de las filas barajado con rng
+func shuffled(n int, rng *rand.Rand) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
 	}
+	rng.Shuffle(n, func(a, b int) { order[a], order[b] = order[b], order[a] })
+	return order
+}
 
-	// Evaluar el modelo
-	acc := accuracy(predictions, labels)
-	tp, _, fp, fn := confusionMatrix(predictions, labels)
-	prec := precision(tp, fp)
-	rec := recall(tp, fn)
-	f1 := f1Score(prec, rec)
+// Entrena el modelo con Pegasos: en el paso t, con η = 1/(λt), los pesos se
+// encogen en (1 - ηλ) y, si la fila viola el margen, se suma η·y·x
+func (svm *SVM) trainSequential(X [][]float64, labels []float64) {
+	rng := data.NewRand(svm.Seed)
+	svm.objectives = make([]float64, 0, svm.Epochs)
 
-	fmt.Printf("Precision: %.2f\n", prec)
-	fmt.Printf("Accuracy: %.2f\n", acc)
-	fmt.Printf("F1-Score: %.2f\n", f1)
-	fmt.Printf("Recall: %.2f\n", rec)
+	t := 0
+	for epoch := 0; epoch < svm.Epochs; epoch++ {
+		for _, i := range shuffled(len(X), rng) {
+			t++
+			eta := 1 / (svm.Lambda * float64(t))
+			violates := labels[i]*svm.decision(X[i]) < 1
+			shrink(svm.weights, &svm.bias, eta, svm.Lambda)
+			if violates {
+				for j := range svm.weights {
+					svm.weights[j] += eta * labels[i] * X[i][j]
+				}
+				svm.bias += eta * labels[i]
+			}
+			project(svm.weights, &svm.bias, svm.Lambda)
+		}
+
+		loss := 0.0
+		for i := range X {
+			loss += hinge(svm.weights, svm.bias, X[i], labels[i])
+		}
+		svm.logEpoch(epoch, loss/float64(len(X)))
+	}
+}
 
-	elapsed := time.Since(start)
-	fmt.Printf("Tiempo de ejecución: %s\n", elapsed)
+// Guarda el objetivo primal de la época y lo imprime si Verbose
+func (svm *SVM) logEpoch(epoch int, meanHinge float64) {
+	objective := regularization(svm.weights, svm.bias, svm.Lambda) + meanHinge
+	svm.objectives = append(svm.objectives, objective)
+	if svm.Verbose {
+		fmt.Printf("Epoch %d: Objetivo: %f, Hinge: %f\n", epoch, objective, meanHinge)
+	}
+}
+
+// SVMSecuential entrena un SVM con trainSet y devuelve sus métricas en testSet
+func SVMSecuential(trainSet, testSet *data.Dataset) (classification.Report, error) {
+	svm := NewSVM()
+	if err := models.FitDataset(svm, trainSet); err != nil {
+		return classification.Report{}, err
+	}
+	return classification.EvaluateDataset(svm, testSet)
 }
@@ -0,0 +1,107 @@
+package svm
+
+import (
+	"errors"
+	"slices"
+	"src/models"
+	"testing"
+)
+
+// Dos nubes separables: la clase 1 en torno a (1, 1) y la 0 en torno a (-1, -1)
+func separableBlobs() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 100 {
+		dx, dy := float64(i%10)/20-0.25, float64(i/10)/20-0.25
+		X = append(X, []float64{1 + dx, 1 + dy}, []float64{-1 + dx, -1 + dy})
+		y = append(y, 1, 0)
+	}
+	return X, y
+}
+
+// Fracción de predicciones iguales a y
+func accuracy(predictions, y []float64) float64 {
+	hits := 0
+	for i, p := range predictions {
+		if p == y[i] {
+			hits++
+		}
+	}
+	return float64(hits) / float64(len(y))
+}
+
+func TestSignedLabels(t *testing.T) {
+	got := signedLabels([]float64{1, 0, 2, 1, -1})
+	if want := []float64{1, -1, -1, 1, -1}; !slices.Equal(got, want) {
+		t.Errorf("signedLabels = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestSVMSeparatesBlobs(t *testing.T) {
+	X, y := separableBlobs()
+	for _, m := range []interface {
+		models.Classifier
+		DecisionFunction(X [][]float64) []float64
+		Objectives() []float64
+	}{NewSVM(), NewSVMConcurrent()} {
+		if err := m.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		if acc := accuracy(m.Predict(X), y); acc != 1 {
+			t.Errorf("%T: accuracy = %v, se esperaba 1", m, acc)
+		}
+		// El signo del margen es la clase predicha
+		for i, margin := range m.DecisionFunction(X) {
+			if (margin >= 0) != (y[i] == 1) {
+				t.Fatalf("%T: margen %v en una fila de la clase %v", m, margin, y[i])
+			}
+		}
+		objectives := m.Objectives()
+		if len(objectives) != 100 || objectives[len(objectives)-1] > objectives[0] {
+			t.Errorf("%T: el objetivo primal no bajó: %v ... %v", m, objectives[0], objectives[len(objectives)-1])
+		}
+	}
+}
+
+func TestSVMIsSeeded(t *testing.T) {
+	X, y := separableBlobs()
+	fit := func() []float64 {
+		svm := NewSVM()
+		svm.Epochs = 5
+		if err := svm.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		return svm.DecisionFunction(X)
+	}
+	if !slices.Equal(fit(), fit()) {
+		t.Error("dos Fit con la misma semilla dieron márgenes distintos")
+	}
+}
+
+func TestSVMErrors(t *testing.T) {
+	X, y := separableBlobs()
+	svm := NewSVM()
+	svm.Lambda = 0
+	if err := svm.Fit(X, y); !errors.Is(err, errLambda) {
+		t.Errorf("Lambda 0: error = %v, se esperaba errLambda", err)
+	}
+	if err := NewSVM().Fit(X, y[1:]); err == nil {
+		t.Error("X e y de distinto largo deben dar error")
+	}
+}
+
+func TestSVMRoundTrip(t *testing.T) {
+	X, y := separableBlobs()
+	svm := NewSVM()
+	svm.Epochs = 5
+	if err := svm.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	clone, err := models.Clone(svm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(clone.(*SVM).DecisionFunction(X), svm.DecisionFunction(X)) {
+		t.Error("el clon da márgenes distintos")
+	}
+}
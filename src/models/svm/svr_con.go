@@ -0,0 +1,123 @@
+package svm
+
+import (
+	"io"
+	"src/classification"
+	"src/data"
+	"src/models"
+)
+
+var (
+	_ models.Regressor  = (*SVRC)(nil)
+	_ models.Persistent = (*SVRC)(nil)
+)
+
+// SVRC es el SVR lineal concurrente. Minimiza el mismo objetivo que SVR con
+// Pegasos por mini-lotes: cada paso suma el subgradiente de BatchSize filas,
+// que se reparten entre Workers goroutines, y equivale a BatchSize pasos de
+// SVR con los márgenes del inicio del lote, así que ambos avanzan el mismo
+// número de pasos de Pegasos por época
+type SVRC struct {
+	SVR
+	BatchSize int // Filas por paso; 0 usa 64
+	Workers   int // Goroutines por lote o predicción; 0 usa GOMAXPROCS
+}
+
+// NewSVRConcurrent crea un SVR lineal concurrente con los hiperparámetros por defecto
+func NewSVRConcurrent() *SVRC {
+	return &SVRC{SVR: *NewSVR(), BatchSize: 64}
+}
+
+// Fit entrena el modelo concurrentemente con X e y
+func (svr *SVRC) Fit(X [][]float64, y []float64) error {
+	if err := svr.check(X, y); err != nil {
+		return err
+	}
+	svr.weights, svr.bias = make([]float64, len(X[0])), 0
+	svr.trainConcurrent(X, y)
+	return nil
+}
+
+// Predict devuelve la predicción w·x + b de cada fila de X, repartiendo las filas entre goroutines
+func (svr *SVRC) Predict(X [][]float64) []float64 {
+	return parallelDecisions(X, svr.Workers, svr.decision)
+}
+
+// Estado serializable del SVR lineal concurrente
+type svrCState struct {
+	Model     svrState
+	BatchSize int
+	Workers   int
+}
+
+// Save guarda el modelo en JSON
+func (svr *SVRC) Save(w io.Writer) error {
+	return models.Save(w, kindSVRC, svr.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svr *SVRC) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindSVRC, svr.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svr *SVRC) Load(r io.Reader) error {
+	var st svrCState
+	if err := models.Load(r, kindSVRC, &st); err != nil {
+		return err
+	}
+	svr.restore(st.Model)
+	svr.BatchSize, svr.Workers = st.BatchSize, st.Workers
+	return nil
+}
+
+func (svr *SVRC) state() svrCState {
+	return svrCState{svr.SVR.state(), svr.BatchSize, svr.Workers}
+}
+
+// Entrena el modelo con Pegasos por mini-lotes, con el paso de batchStep y
+// el subgradiente de cada lote calculado en paralelo
+func (svr *SVRC) trainConcurrent(X [][]float64, y []float64) {
+	rng := data.NewRand(svr.Seed)
+	batchSize := svr.BatchSize
+	if batchSize <= 0 {
+		batchSize = 64
+	}
+	limit := svr.limit(y)
+	d := len(svr.weights)
+	svr.objectives = make([]float64, 0, svr.Epochs)
+
+	t := 0
+	for epoch := 0; epoch < svr.Epochs; epoch++ {
+		order := shuffled(len(X), rng)
+		for start := 0; start < len(order); start += batchSize {
+			batch := order[start:min(start+batchSize, len(order))]
+			grad := classification.MapReduce(len(batch), models.BatchChunk, svr.Workers, func(lo, hi int) subgradient {
+				g := make(subgradient, d+1)
+				for _, i := range batch[lo:hi] {
+					sign := tubeSign(y[i]-svr.decision(X[i]), svr.Epsilon)
+					if sign != 0 {
+						for j := range d {
+							g[j] += sign * X[i][j]
+						}
+						g[d] += sign
+					}
+				}
+				return g
+			}, addSubgradients)
+
+			t += len(batch)
+			svr.batchStep(grad, len(batch), t, svr.Lambda)
+			clipNorm(svr.weights, &svr.bias, limit)
+		}
+
+		loss := classification.MapReduce(len(X), rowChunk, svr.Workers, func(lo, hi int) float64 {
+			sum := 0.0
+			for i := lo; i < hi; i++ {
+				sum += epsilonLoss(svr.weights, svr.bias, X[i], y[i], svr.Epsilon)
+			}
+			return sum
+		}, func(a, b float64) float64 { return a + b })
+		svr.logEpoch(epoch, loss/float64(len(X)))
+	}
+}
@@ -0,0 +1,196 @@
+package svm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"src/data"
+	"src/models"
+)
+
+var (
+	_ models.Regressor  = (*SVR)(nil)
+	_ models.Persistent = (*SVR)(nil)
+)
+
+// Tipos de modelo usados al serializar
+const (
+	kindSVR  = "svr"
+	kindSVRC = "svr_concurrent"
+)
+
+func init() {
+	models.Register(kindSVR, func() models.Regressor { return NewSVR() })
+	models.Register(kindSVRC, func() models.Regressor { return NewSVRConcurrent() })
+}
+
+var errEpsilon = errors.New("epsilon no puede ser negativo")
+
+// SVR es la regresión de vectores de soporte lineal (ε-SVR). Comparte con SVM
+// el hiperplano w·x + b y minimiza con Pegasos el objetivo primal
+//
+//	λ/2·‖(w, b)‖² + 1/n·Σ max(0, |yᵢ - (w·xᵢ + b)| - ε)
+//
+// así que los errores dentro del tubo de ancho ε no penalizan. El objetivo
+// es continuo: no se lleva a etiquetas ±1
+type SVR struct {
+	// Hiperparámetros usados por Fit
+	Epochs  int     // Pasadas completas por los datos
+	Lambda  float64 // Regularización λ; fija también el tamaño del paso
+	Epsilon float64 // Ancho del tubo sin pérdida alrededor de la predicción
+	Seed    int64   // Semilla del orden en que se visitan las filas
+	Verbose bool    // Imprime el objetivo primal de cada época
+
+	hyperplane
+}
+
+// NewSVR crea un SVR lineal secuencial con los hiperparámetros por defecto.
+// λ es mayor que en SVM porque el error de la regresión depende de la
+// magnitud de los pesos y los pasos 1/(λt) grandes tardan más en asentarse
+func NewSVR() *SVR {
+	return &SVR{Epochs: 100, Lambda: 0.01, Epsilon: 0.1, Seed: data.DefaultSeed}
+}
+
+// Fit entrena el modelo secuencialmente con X e y
+func (svr *SVR) Fit(X [][]float64, y []float64) error {
+	if err := svr.check(X, y); err != nil {
+		return err
+	}
+	svr.weights, svr.bias = make([]float64, len(X[0])), 0
+	svr.trainSequential(X, y)
+	return nil
+}
+
+// Valida los datos y los hiperparámetros
+func (svr *SVR) check(X [][]float64, y []float64) error {
+	if err := models.CheckFit(X, y); err != nil {
+		return err
+	}
+	if err := models.CheckComplete(X); err != nil {
+		return err
+	}
+	if svr.Lambda <= 0 {
+		return errLambda
+	}
+	if svr.Epsilon < 0 {
+		return errEpsilon
+	}
+	return nil
+}
+
+// Predict devuelve la predicción w·x + b de cada fila de X
+func (svr *SVR) Predict(X [][]float64) []float64 {
+	predictions := make([]float64, len(X))
+	for i, x := range X {
+		predictions[i] = svr.decision(x)
+	}
+	return predictions
+}
+
+// Entrena el modelo con Pegasos: en el paso t, con η = 1/(λt), los pesos se
+// encogen en (1 - ηλ) y, si la fila queda fuera del tubo, se suma η·signo·x,
+// con el signo del residuo
+func (svr *SVR) trainSequential(X [][]float64, y []float64) {
+	rng := data.NewRand(svr.Seed)
+	limit := svr.limit(y)
+	svr.objectives = make([]float64, 0, svr.Epochs)
+
+	t := 0
+	for epoch := 0; epoch < svr.Epochs; epoch++ {
+		for _, i := range shuffled(len(X), rng) {
+			t++
+			eta := 1 / (svr.Lambda * float64(t))
+			sign := tubeSign(y[i]-svr.decision(X[i]), svr.Epsilon)
+			shrink(svr.weights, &svr.bias, eta, svr.Lambda)
+			if sign != 0 {
+				for j := range svr.weights {
+					svr.weights[j] += eta * sign * X[i][j]
+				}
+				svr.bias += eta * sign
+			}
+			clipNorm(svr.weights, &svr.bias, limit)
+		}
+
+		loss := 0.0
+		for i := range X {
+			loss += epsilonLoss(svr.weights, svr.bias, X[i], y[i], svr.Epsilon)
+		}
+		svr.logEpoch(epoch, loss/float64(len(X)))
+	}
+}
+
+// Cota de ‖(w, b)‖² en el óptimo: λ/2·‖(w, b)‖² no supera el objetivo en
+// (w, b) = 0, que es la pérdida media de predecir siempre 0
+func (svr *SVR) limit(y []float64) float64 {
+	loss := 0.0
+	for _, v := range y {
+		loss += math.Max(0, math.Abs(v)-svr.Epsilon)
+	}
+	return 2 * loss / float64(len(y)) / svr.Lambda
+}
+
+// Guarda el objetivo primal de la época y lo imprime si Verbose
+func (svr *SVR) logEpoch(epoch int, meanLoss float64) {
+	objective := regularization(svr.weights, svr.bias, svr.Lambda) + meanLoss
+	svr.objectives = append(svr.objectives, objective)
+	if svr.Verbose {
+		fmt.Printf("Epoch %d: Objetivo: %f, Pérdida ε: %f\n", epoch, objective, meanLoss)
+	}
+}
+
+// Pérdida ε-insensible de una fila
+func epsilonLoss(weights []float64, bias float64, x []float64, y, epsilon float64) float64 {
+	return math.Max(0, math.Abs(y-margin(weights, bias, x))-epsilon)
+}
+
+// Signo del residuo si queda fuera del tubo de ancho ε y 0 si queda dentro:
+// la dirección del subgradiente de la pérdida ε-insensible
+func tubeSign(residual, epsilon float64) float64 {
+	switch {
+	case residual > epsilon:
+		return 1
+	case residual < -epsilon:
+		return -1
+	}
+	return 0
+}
+
+// Estado serializable del SVR lineal
+type svrState struct {
+	Epochs  int
+	Lambda  float64
+	Epsilon float64
+	Seed    int64
+	Weights []float64
+	Bias    float64
+}
+
+// Save guarda el modelo en JSON
+func (svr *SVR) Save(w io.Writer) error {
+	return models.Save(w, kindSVR, svr.state())
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (svr *SVR) SaveBinary(w io.Writer) error {
+	return models.SaveBinary(w, kindSVR, svr.state())
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (svr *SVR) Load(r io.Reader) error {
+	var st svrState
+	if err := models.Load(r, kindSVR, &st); err != nil {
+		return err
+	}
+	svr.restore(st)
+	return nil
+}
+
+func (svr *SVR) state() svrState {
+	return svrState{svr.Epochs, svr.Lambda, svr.Epsilon, svr.Seed, svr.weights, svr.bias}
+}
+
+func (svr *SVR) restore(st svrState) {
+	svr.Epochs, svr.Lambda, svr.Epsilon, svr.Seed = st.Epochs, st.Lambda, st.Epsilon, st.Seed
+	svr.weights, svr.bias = st.Weights, st.Bias
+}
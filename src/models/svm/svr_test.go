@@ -0,0 +1,42 @@
+package svm
+
+import (
+	"src/models"
+	"testing"
+)
+
+// Objetivo lineal 2·x₀ - 3·x₁ + 1 sin ruido, con las filas en una rejilla
+func linearTarget() ([][]float64, []float64) {
+	var X [][]float64
+	var y []float64
+	for i := range 40 {
+		for j := range 10 {
+			x := []float64{float64(i)/20 - 1, float64(j)/5 - 1}
+			X = append(X, x)
+			y = append(y, 2*x[0]-3*x[1]+1)
+		}
+	}
+	return X, y
+}
+
+func TestSVRSequentialAndConcurrentAgree(t *testing.T) {
+	X, y := linearTarget()
+	for _, m := range []interface {
+		models.Regressor
+		Objectives() []float64
+	}{NewSVR(), NewSVRConcurrent()} {
+		if err := m.Fit(X, y); err != nil {
+			t.Fatal(err)
+		}
+		mse := 0.0
+		for i, p := range m.Predict(X) {
+			mse += (p - y[i]) * (p - y[i])
+		}
+		if mse /= float64(len(y)); mse > 0.05 {
+			t.Errorf("%T: mse = %v, se esperaba < 0.05", m, mse)
+		}
+		if len(m.Objectives()) != 100 {
+			t.Errorf("%T: %d objetivos, se esperaban 100", m, len(m.Objectives()))
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+var (
+	_ Classifier = (*Thresholded)(nil)
+	_ Persistent = (*Thresholded)(nil)
+)
+
+// Tipo de modelo usado al serializar
+const kindThresholded = "thresholded"
+
+func init() {
+	Register(kindThresholded, func() Regressor { return &Thresholded{} })
+}
+
+// Thresholded envuelve un clasificador y predice la clase 1 cuando su
+// probabilidad es >= Threshold, en lugar del corte fijo del modelo
+type Thresholded struct {
+	Model     Classifier
+	Threshold float64
+}
+
+// Fit entrena el modelo envuelto; el umbral no cambia
+func (t *Thresholded) Fit(X [][]float64, y []float64) error {
+	return t.Model.Fit(X, y)
+}
+
+// Predict devuelve 1 para las filas con probabilidad >= Threshold y 0 para el resto
+func (t *Thresholded) Predict(X [][]float64) []float64 {
+	predictions := t.Model.PredictProba(X)
+	for i, p := range predictions {
+		if p >= t.Threshold {
+			predictions[i] = 1
+		} else {
+			predictions[i] = 0
+		}
+	}
+	return predictions
+}
+
+// PredictProba devuelve las probabilidades del modelo envuelto
+func (t *Thresholded) PredictProba(X [][]float64) []float64 {
+	return t.Model.PredictProba(X)
+}
+
+// Estado serializable: el umbral y el modelo envuelto guardado en el mismo
+// formato, con su propia cabecera
+type thresholdedState struct {
+	Threshold float64
+	Model     json.RawMessage
+}
+
+// Save guarda el modelo en JSON
+func (t *Thresholded) Save(w io.Writer) error {
+	return t.save(w, false)
+}
+
+// SaveBinary guarda el modelo en formato binario
+func (t *Thresholded) SaveBinary(w io.Writer) error {
+	return t.save(w, true)
+}
+
+func (t *Thresholded) save(w io.Writer, binary bool) error {
+	inner, err := SaveNested(t.Model, binary)
+	if err != nil {
+		return err
+	}
+	if binary {
+		return SaveBinary(w, kindThresholded, thresholdedState{t.Threshold, inner})
+	}
+	return Save(w, kindThresholded, thresholdedState{t.Threshold, inner})
+}
+
+// Load carga un modelo guardado con Save o SaveBinary
+func (t *Thresholded) Load(r io.Reader) error {
+	var st thresholdedState
+	if err := Load(r, kindThresholded, &st); err != nil {
+		return err
+	}
+	model, err := LoadNested(st.Model)
+	if err != nil {
+		return err
+	}
+	classifier, ok := model.(Classifier)
+	if !ok {
+		return fmt.Errorf("%w: el modelo envuelto no es un clasificador", ErrModelMismatch)
+	}
+	t.Model, t.Threshold = classifier, st.Threshold
+	return nil
+}